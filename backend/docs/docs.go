@@ -541,7 +541,6 @@ const docTemplate = `{
                 },
                 "password": {
                     "type": "string",
-                    "minLength": 6,
                     "example": "password123"
                 },
                 "username": {
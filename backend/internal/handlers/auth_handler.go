@@ -11,6 +11,7 @@ import (
 type AuthHandler struct {
 	db          *gorm.DB
 	authService services.AuthService
+	mfaService  services.MFAService
 }
 
 type AuthRequest struct {
@@ -24,8 +25,14 @@ type AuthResponse struct {
 	ExpiresIn    int    `json:"expires_in"`
 }
 
-func NewAuthHandler(db *gorm.DB, authService services.AuthService) *AuthHandler {
-	return &AuthHandler{db: db, authService: authService}
+// MFAChallengeResponse replaces the tokens when the login needs a second factor
+type MFAChallengeResponse struct {
+	MFARequired bool `json:"mfa_required"`
+	*services.MFAChallengeToken
+}
+
+func NewAuthHandler(db *gorm.DB, authService services.AuthService, mfaService services.MFAService) *AuthHandler {
+	return &AuthHandler{db: db, authService: authService, mfaService: mfaService}
 }
 
 func (h *AuthHandler) Login(c *gin.Context) {
@@ -36,14 +43,36 @@ func (h *AuthHandler) Login(c *gin.Context) {
 	}
 
 	// Authenticate user
-	user, err := h.authService.LoginUser(h.db, req.Username, req.Password)
+	user, err := h.authService.LoginUser(h.db, req.Username, req.Password, clientInfo(c))
 	if err != nil {
+		switch err.Error() {
+		case "email not verified":
+			c.JSON(http.StatusForbidden, gin.H{"error": "Email address not verified"})
+			return
+		case "account disabled":
+			c.JSON(http.StatusForbidden, gin.H{"error": "Account disabled"})
+			return
+		case "password reset required":
+			c.JSON(http.StatusForbidden, gin.H{"error": "Password reset required, check your email for a reset link"})
+			return
+		}
 		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid credentials"})
 		return
 	}
 
+	// With 2FA the tokens are only issued by /auth/mfa/verify
+	challenge, err := h.mfaService.LoginChallenge(h.db, user.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to check two-factor authentication"})
+		return
+	}
+	if challenge != nil {
+		c.JSON(http.StatusOK, MFAChallengeResponse{MFARequired: true, MFAChallengeToken: challenge})
+		return
+	}
+
 	// Generate tokens
-	accessToken, refreshToken, err := h.authService.GenerateToken(h.db, user.ID)
+	accessToken, refreshToken, err := h.authService.GenerateToken(h.db, user.ID, clientInfo(c))
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to generate tokens"})
 		return
@@ -0,0 +1,91 @@
+package handlers
+
+import (
+	"net/http"
+	"task-manager/backend/internal/services"
+	"task-manager/backend/internal/utils"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gofrs/uuid"
+	"gorm.io/gorm"
+)
+
+type JobHandler struct {
+	db         *gorm.DB
+	jobService services.JobService
+}
+
+func NewJobHandler(db *gorm.DB, jobService services.JobService) *JobHandler {
+	return &JobHandler{db: db, jobService: jobService}
+}
+
+func (h *JobHandler) GetJobs(c *gin.Context) {
+	jobs, err := h.jobService.GetScheduledJobs(h.db)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get jobs"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"jobs": jobs})
+}
+
+func (h *JobHandler) GetJobRuns(c *gin.Context) {
+	pagination := utils.GetPaginationParams(c)
+
+	response, err := h.jobService.GetJobRuns(h.db, c.Query("name"), pagination)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get job runs"})
+		return
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+func (h *JobHandler) TriggerJob(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return
+	}
+
+	run, err := h.jobService.TriggerJob(h.db, c.Param("name"), userID.(uuid.UUID))
+	if err != nil {
+		switch err.Error() {
+		case "job not found":
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		case "job is paused":
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to trigger job"})
+		}
+		return
+	}
+
+	c.JSON(http.StatusAccepted, gin.H{"message": "job queued", "run": run})
+}
+
+func (h *JobHandler) PauseJob(c *gin.Context) {
+	h.setPaused(c, true)
+}
+
+func (h *JobHandler) ResumeJob(c *gin.Context) {
+	h.setPaused(c, false)
+}
+
+func (h *JobHandler) setPaused(c *gin.Context, paused bool) {
+	job, err := h.jobService.SetJobPaused(h.db, c.Param("name"), paused)
+	if err != nil {
+		if err.Error() == "job not found" {
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update job"})
+		return
+	}
+
+	message := "job resumed"
+	if paused {
+		message = "job paused"
+	}
+	c.JSON(http.StatusOK, gin.H{"message": message, "job": job})
+}
@@ -0,0 +1,79 @@
+package handlers
+
+import (
+	"net/http"
+	"task-manager/backend/internal/services"
+	"task-manager/backend/internal/utils"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gofrs/uuid"
+	"gorm.io/gorm"
+)
+
+type LoginGuardHandler struct {
+	db         *gorm.DB
+	loginGuard services.LoginGuardService
+}
+
+func NewLoginGuardHandler(db *gorm.DB, loginGuard services.LoginGuardService) *LoginGuardHandler {
+	return &LoginGuardHandler{db: db, loginGuard: loginGuard}
+}
+
+// GetMyLoginAttempts lets users review where their account was signed in from
+func (h *LoginGuardHandler) GetMyLoginAttempts(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return
+	}
+
+	response, err := h.loginGuard.GetLoginAttempts(h.db, userID.(uuid.UUID), utils.GetPaginationParams(c))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get login history"})
+		return
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+func (h *LoginGuardHandler) GetUserLoginAttempts(c *gin.Context) {
+	userID, err := uuid.FromString(c.Param("user_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID"})
+		return
+	}
+
+	response, err := h.loginGuard.GetLoginAttempts(h.db, userID, utils.GetPaginationParams(c))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get login history"})
+		return
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// UnlockUser lifts a login delay or lockout before it runs out
+func (h *LoginGuardHandler) UnlockUser(c *gin.Context) {
+	adminID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return
+	}
+
+	userID, err := uuid.FromString(c.Param("user_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID"})
+		return
+	}
+
+	if err := h.loginGuard.Unlock(h.db, userID, adminID.(uuid.UUID)); err != nil {
+		if err.Error() == "user not found" {
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to unlock user"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "user unlocked"})
+}
@@ -0,0 +1,228 @@
+package handlers
+
+import (
+	"net/http"
+	"task-manager/backend/internal/models"
+	"task-manager/backend/internal/services"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gofrs/uuid"
+	"gorm.io/gorm"
+)
+
+type MFAHandler struct {
+	db          *gorm.DB
+	mfaService  services.MFAService
+	authService services.AuthService
+}
+
+func NewMFAHandler(db *gorm.DB, mfaService services.MFAService, authService services.AuthService) *MFAHandler {
+	return &MFAHandler{db: db, mfaService: mfaService, authService: authService}
+}
+
+// mfaError maps service errors shared by the 2FA endpoints
+func mfaError(c *gin.Context, err error, fallback string) {
+	switch err.Error() {
+	case "invalid code", "too many invalid codes", "invalid or expired mfa token":
+		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+	case "two-factor authentication is already enabled", "two-factor authentication is not enabled", "no enrollment in progress":
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	case "two-factor authentication is required for your role":
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+	case "user not found", "role not found":
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": fallback})
+	}
+}
+
+func (h *MFAHandler) GetStatus(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return
+	}
+
+	status, err := h.mfaService.GetStatus(h.db, userID.(uuid.UUID))
+	if err != nil {
+		mfaError(c, err, "Failed to get two-factor status")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"mfa": status})
+}
+
+// Setup starts enrolment; the provisioning URI is meant to be shown as a QR code
+func (h *MFAHandler) Setup(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return
+	}
+
+	enrollment, err := h.mfaService.BeginEnrollment(h.db, userID.(uuid.UUID))
+	if err != nil {
+		mfaError(c, err, "Failed to start two-factor enrollment")
+		return
+	}
+
+	c.JSON(http.StatusOK, enrollment)
+}
+
+// Confirm enables 2FA. The recovery codes are only ever returned here.
+func (h *MFAHandler) Confirm(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return
+	}
+
+	var req models.MFACodeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	codes, err := h.mfaService.ConfirmEnrollment(h.db, userID.(uuid.UUID), req.Code)
+	if err != nil {
+		mfaError(c, err, "Failed to enable two-factor authentication")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "two-factor authentication enabled", "recovery_codes": codes})
+}
+
+func (h *MFAHandler) Disable(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return
+	}
+
+	var req models.MFACodeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := h.mfaService.Disable(h.db, userID.(uuid.UUID), req.Code); err != nil {
+		mfaError(c, err, "Failed to disable two-factor authentication")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "two-factor authentication disabled"})
+}
+
+func (h *MFAHandler) RegenerateRecoveryCodes(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return
+	}
+
+	var req models.MFACodeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	codes, err := h.mfaService.RegenerateRecoveryCodes(h.db, userID.(uuid.UUID), req.Code)
+	if err != nil {
+		mfaError(c, err, "Failed to regenerate recovery codes")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"recovery_codes": codes})
+}
+
+// Enroll sets up 2FA during a login that requires it, using the mfa_token
+// from the login response instead of an access token
+func (h *MFAHandler) Enroll(c *gin.Context) {
+	var req models.MFAEnrollRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	enrollment, err := h.mfaService.BeginChallengeEnrollment(h.db, req.MFAToken)
+	if err != nil {
+		mfaError(c, err, "Failed to start two-factor enrollment")
+		return
+	}
+
+	c.JSON(http.StatusOK, enrollment)
+}
+
+// Verify completes a login that answered with an MFA challenge
+func (h *MFAHandler) Verify(c *gin.Context) {
+	var req models.MFAVerifyRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	userID, recoveryCodes, err := h.mfaService.VerifyChallenge(h.db, req.MFAToken, req.Code)
+	if err != nil {
+		mfaError(c, err, "Failed to verify code")
+		return
+	}
+
+	accessToken, refreshToken, err := h.authService.GenerateToken(h.db, userID, clientInfo(c))
+	if err != nil {
+		if err.Error() == "account disabled" {
+			c.JSON(http.StatusForbidden, gin.H{"error": "Account disabled"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to generate tokens"})
+		return
+	}
+
+	response := gin.H{
+		"access_token":  accessToken,
+		"refresh_token": refreshToken,
+		"expires_in":    3600, // 1 hour
+	}
+	if recoveryCodes != nil {
+		response["recovery_codes"] = recoveryCodes
+	}
+	c.JSON(http.StatusOK, response)
+}
+
+// SetRoleRequirement lets admins require 2FA for everyone with a role
+func (h *MFAHandler) SetRoleRequirement(c *gin.Context) {
+	roleID, err := uuid.FromString(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid role ID"})
+		return
+	}
+
+	var req models.RoleMFARequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	role, err := h.mfaService.SetRoleRequirement(h.db, roleID, *req.Required)
+	if err != nil {
+		mfaError(c, err, "Failed to update role")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "role updated successfully", "role": role})
+}
+
+// ResetUserMFA removes another user's 2FA so they can enrol again
+func (h *MFAHandler) ResetUserMFA(c *gin.Context) {
+	userID, err := uuid.FromString(c.Param("user_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID"})
+		return
+	}
+
+	if err := h.mfaService.ResetMFA(h.db, userID); err != nil {
+		mfaError(c, err, "Failed to reset two-factor authentication")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "two-factor authentication reset"})
+}
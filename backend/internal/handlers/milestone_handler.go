@@ -0,0 +1,154 @@
+package handlers
+
+import (
+	"net/http"
+	"task-manager/backend/internal/models"
+	"task-manager/backend/internal/services"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gofrs/uuid"
+	"gorm.io/gorm"
+)
+
+type MilestoneHandler struct {
+	db               *gorm.DB
+	milestoneService services.MilestoneService
+}
+
+func NewMilestoneHandler(db *gorm.DB, milestoneService services.MilestoneService) *MilestoneHandler {
+	return &MilestoneHandler{db: db, milestoneService: milestoneService}
+}
+
+func (h *MilestoneHandler) CreateMilestone(c *gin.Context) {
+	var req models.MilestoneCreateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return
+	}
+
+	milestone := models.Milestone{
+		Name:      req.Name,
+		Goal:      req.Goal,
+		StartDate: req.StartDate,
+		EndDate:   req.EndDate,
+		CreatedBy: userID.(uuid.UUID),
+	}
+
+	createdMilestone, err := h.milestoneService.CreateMilestone(h.db, milestone)
+	if err != nil {
+		if err.Error() == "end date must be after start date" {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create milestone"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"message": "milestone created successfully", "milestone": createdMilestone})
+}
+
+func (h *MilestoneHandler) UpdateMilestone(c *gin.Context) {
+	milestoneID, err := uuid.FromString(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid milestone ID"})
+		return
+	}
+
+	var req models.MilestoneUpdateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	updatedMilestone, err := h.milestoneService.UpdateMilestone(h.db, milestoneID, req)
+	if err != nil {
+		if err.Error() == "milestone not found" {
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
+		if err.Error() == "end date must be after start date" {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update milestone"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "milestone updated successfully", "milestone": updatedMilestone})
+}
+
+func (h *MilestoneHandler) DeleteMilestone(c *gin.Context) {
+	milestoneID, err := uuid.FromString(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid milestone ID"})
+		return
+	}
+
+	err = h.milestoneService.DeleteMilestone(h.db, milestoneID)
+	if err != nil {
+		if err.Error() == "milestone not found" {
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete milestone"})
+		return
+	}
+
+	c.JSON(http.StatusNoContent, nil)
+}
+
+func (h *MilestoneHandler) GetMilestoneByID(c *gin.Context) {
+	milestoneID, err := uuid.FromString(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid milestone ID"})
+		return
+	}
+
+	milestone, err := h.milestoneService.GetMilestoneByID(h.db, milestoneID)
+	if err != nil {
+		if err.Error() == "milestone not found" {
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get milestone"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"milestone": milestone})
+}
+
+func (h *MilestoneHandler) GetMilestones(c *gin.Context) {
+	milestones, err := h.milestoneService.GetMilestones(h.db)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get milestones"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"milestones": milestones})
+}
+
+func (h *MilestoneHandler) GetBurndown(c *gin.Context) {
+	milestoneID, err := uuid.FromString(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid milestone ID"})
+		return
+	}
+
+	burndown, err := h.milestoneService.GetBurndown(h.db, milestoneID)
+	if err != nil {
+		if err.Error() == "milestone not found" {
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get burndown"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"burndown": burndown})
+}
@@ -0,0 +1,103 @@
+package handlers
+
+import (
+	"net/http"
+	"task-manager/backend/internal/services"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+type OIDCHandler struct {
+	db          *gorm.DB
+	oidcService services.OIDCService
+	authService services.AuthService
+	mfaService  services.MFAService
+}
+
+type OIDCCallbackRequest struct {
+	Code             string `json:"code" form:"code"`
+	State            string `json:"state" form:"state"`
+	Error            string `json:"error" form:"error"`
+	ErrorDescription string `json:"error_description" form:"error_description"`
+}
+
+func NewOIDCHandler(db *gorm.DB, oidcService services.OIDCService, authService services.AuthService, mfaService services.MFAService) *OIDCHandler {
+	return &OIDCHandler{db: db, oidcService: oidcService, authService: authService, mfaService: mfaService}
+}
+
+// Login sends the browser to the identity provider
+func (h *OIDCHandler) Login(c *gin.Context) {
+	if !h.oidcService.Enabled() {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Single sign-on is not configured"})
+		return
+	}
+
+	authorizationURL, err := h.oidcService.BeginLogin(h.db)
+	if err != nil {
+		c.JSON(http.StatusBadGateway, gin.H{"error": "Failed to start single sign-on"})
+		return
+	}
+
+	c.Redirect(http.StatusFound, authorizationURL)
+}
+
+// Callback finishes the login with the code the identity provider redirected
+// back with. It accepts the query string directly or a JSON body posted by
+// the frontend callback page, and answers like Login.
+func (h *OIDCHandler) Callback(c *gin.Context) {
+	if !h.oidcService.Enabled() {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Single sign-on is not configured"})
+		return
+	}
+
+	var req OIDCCallbackRequest
+	if err := c.ShouldBind(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if req.Error != "" {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Single sign-on failed", "reason": req.Error, "description": req.ErrorDescription})
+		return
+	}
+	if req.Code == "" || req.State == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "code and state are required"})
+		return
+	}
+
+	user, err := h.oidcService.CompleteLogin(h.db, req.State, req.Code)
+	if err != nil {
+		switch err.Error() {
+		case "invalid or expired login state", "identity provider rejected the login":
+			c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		case "email address is not verified", "account email address is not verified", "no account for this email", "identity provider did not share an email address", "account disabled":
+			c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to complete single sign-on"})
+		}
+		return
+	}
+
+	// Single sign-on does not replace 2FA; the tokens come from /auth/mfa/verify
+	challenge, err := h.mfaService.LoginChallenge(h.db, user.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to check two-factor authentication"})
+		return
+	}
+	if challenge != nil {
+		c.JSON(http.StatusOK, MFAChallengeResponse{MFARequired: true, MFAChallengeToken: challenge})
+		return
+	}
+
+	accessToken, refreshToken, err := h.authService.GenerateToken(h.db, user.ID, clientInfo(c))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to generate tokens"})
+		return
+	}
+
+	c.JSON(http.StatusOK, AuthResponse{
+		AccessToken:  accessToken,
+		RefreshToken: refreshToken,
+		ExpiresIn:    3600, // 1 hour
+	})
+}
@@ -0,0 +1,208 @@
+package handlers
+
+import (
+	"net/http"
+	"task-manager/backend/internal/models"
+	"task-manager/backend/internal/services"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gofrs/uuid"
+	"gorm.io/gorm"
+)
+
+type RBACHandler struct {
+	db          *gorm.DB
+	rbacService services.RBACService
+}
+
+func NewRBACHandler(db *gorm.DB, rbacService services.RBACService) *RBACHandler {
+	return &RBACHandler{db: db, rbacService: rbacService}
+}
+
+func (h *RBACHandler) GetRoles(c *gin.Context) {
+	roles, err := h.rbacService.GetRoles(h.db)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get roles"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"roles": roles})
+}
+
+func (h *RBACHandler) CreateRole(c *gin.Context) {
+	var req models.CreateRoleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	role, err := h.rbacService.CreateRole(h.db, req)
+	if err != nil {
+		rbacError(c, err, "Failed to create role")
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"role": role})
+}
+
+func (h *RBACHandler) UpdateRole(c *gin.Context) {
+	roleID, ok := uuidParam(c, "id", "Invalid role ID")
+	if !ok {
+		return
+	}
+
+	var req models.UpdateRoleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	role, err := h.rbacService.UpdateRole(h.db, roleID, req)
+	if err != nil {
+		rbacError(c, err, "Failed to update role")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"role": role})
+}
+
+func (h *RBACHandler) DeleteRole(c *gin.Context) {
+	roleID, ok := uuidParam(c, "id", "Invalid role ID")
+	if !ok {
+		return
+	}
+
+	if err := h.rbacService.DeleteRole(h.db, roleID); err != nil {
+		rbacError(c, err, "Failed to delete role")
+		return
+	}
+
+	c.JSON(http.StatusNoContent, nil)
+}
+
+func (h *RBACHandler) GetRolePermissions(c *gin.Context) {
+	roleID, ok := uuidParam(c, "id", "Invalid role ID")
+	if !ok {
+		return
+	}
+
+	permissions, err := h.rbacService.GetRolePermissions(h.db, roleID)
+	if err != nil {
+		rbacError(c, err, "Failed to get role permissions")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"permissions": permissions})
+}
+
+func (h *RBACHandler) GrantPermission(c *gin.Context) {
+	roleID, ok := uuidParam(c, "id", "Invalid role ID")
+	if !ok {
+		return
+	}
+
+	var req models.GrantPermissionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := h.rbacService.GrantPermission(h.db, roleID, req.PermissionID); err != nil {
+		rbacError(c, err, "Failed to grant permission")
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"message": "permission granted"})
+}
+
+func (h *RBACHandler) RevokePermission(c *gin.Context) {
+	roleID, ok := uuidParam(c, "id", "Invalid role ID")
+	if !ok {
+		return
+	}
+	permissionID, ok := uuidParam(c, "permission_id", "Invalid permission ID")
+	if !ok {
+		return
+	}
+
+	if err := h.rbacService.RevokePermission(h.db, roleID, permissionID); err != nil {
+		rbacError(c, err, "Failed to revoke permission")
+		return
+	}
+
+	c.JSON(http.StatusNoContent, nil)
+}
+
+func (h *RBACHandler) GetPermissions(c *gin.Context) {
+	permissions, err := h.rbacService.GetPermissions(h.db)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get permissions"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"permissions": permissions})
+}
+
+func (h *RBACHandler) CreatePermission(c *gin.Context) {
+	var req models.CreatePermissionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	permission, err := h.rbacService.CreatePermission(h.db, req)
+	if err != nil {
+		rbacError(c, err, "Failed to create permission")
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"permission": permission})
+}
+
+func (h *RBACHandler) DeletePermission(c *gin.Context) {
+	permissionID, ok := uuidParam(c, "id", "Invalid permission ID")
+	if !ok {
+		return
+	}
+
+	if err := h.rbacService.DeletePermission(h.db, permissionID); err != nil {
+		rbacError(c, err, "Failed to delete permission")
+		return
+	}
+
+	c.JSON(http.StatusNoContent, nil)
+}
+
+// GetCatalogue lists the permissions the registered routes check
+func (h *RBACHandler) GetCatalogue(c *gin.Context) {
+	catalogue, err := h.rbacService.GetCatalogue(h.db)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get permission catalogue"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"permissions": catalogue})
+}
+
+func uuidParam(c *gin.Context, name, message string) (uuid.UUID, bool) {
+	id, err := uuid.FromString(c.Param(name))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": message})
+		return uuid.Nil, false
+	}
+	return id, true
+}
+
+func rbacError(c *gin.Context, err error, message string) {
+	switch err.Error() {
+	case "role not found", "permission not found", "permission not granted":
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case "role already exists", "permission already exists", "permission already granted",
+		"built-in roles cannot be renamed", "built-in roles cannot be deleted", "permission is required by a route":
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	case "role name cannot be empty", "resource and action are required":
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": message})
+	}
+}
@@ -5,7 +5,6 @@ import (
 	"task-manager/backend/internal/services"
 
 	"github.com/gin-gonic/gin"
-	"github.com/gofrs/uuid"
 	"gorm.io/gorm"
 )
 
@@ -35,30 +34,20 @@ func (h *RefreshHandler) Refresh(c *gin.Context) {
 		return
 	}
 
-	// Parse refresh token UUID
-	refreshTokenUUID, err := uuid.FromString(req.RefreshToken)
+	// Rotate the refresh token; the old one stops working and replaying it
+	// later revokes the whole session
+	accessToken, newRefreshToken, err := h.authService.RefreshSession(h.db, req.RefreshToken, clientInfo(c))
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid refresh token format"})
-		return
-	}
-
-	// Validate refresh token
-	token, err := h.authService.ValidateRefreshToken(h.db, refreshTokenUUID)
-	if err != nil {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid or expired refresh token"})
-		return
-	}
-
-	// Invalidate old refresh token
-	if err := h.authService.InvalidateRefreshToken(h.db, refreshTokenUUID); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to invalidate refresh token"})
-		return
-	}
-
-	// Generate new tokens
-	accessToken, newRefreshToken, err := h.authService.GenerateToken(h.db, token.UserID)
-	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to generate tokens"})
+		switch err.Error() {
+		case "invalid or expired refresh token":
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid or expired refresh token"})
+		case "account disabled":
+			c.JSON(http.StatusForbidden, gin.H{"error": "Account disabled"})
+		case "refresh token reuse detected":
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "Refresh token reuse detected, please log in again"})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to generate tokens"})
+		}
 		return
 	}
 
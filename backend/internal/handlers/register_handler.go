@@ -20,7 +20,17 @@ type RegisterHandler struct {
 type RegisterRequest struct {
 	Username string `json:"username" binding:"required"`
 	Email    string `json:"email" binding:"required,email"`
-	Password string `json:"password" binding:"required,min=6"`
+	Password string `json:"password" binding:"required"`
+}
+
+// passwordPolicyError answers with every rule the password breaks
+func passwordPolicyError(c *gin.Context, err error) bool {
+	var policyErr *services.PasswordPolicyError
+	if !errors.As(err, &policyErr) {
+		return false
+	}
+	c.JSON(http.StatusBadRequest, gin.H{"error": policyErr.Error(), "violations": policyErr.Violations})
+	return true
 }
 
 func NewRegisterHandler(db *gorm.DB, registerService services.RegisterService) *RegisterHandler {
@@ -42,6 +52,9 @@ func (h *RegisterHandler) Registration(c *gin.Context) {
 
 	err := h.registerService.RegisterUser(h.db, user)
 	if err != nil {
+		if passwordPolicyError(c, err) {
+			return
+		}
 		if err.Error() == "username or email already exists" {
 			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
 			return
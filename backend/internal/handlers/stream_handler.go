@@ -0,0 +1,169 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"task-manager/backend/internal/services"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gofrs/uuid"
+	"golang.org/x/net/websocket"
+	"gorm.io/gorm"
+)
+
+const (
+	streamHeartbeatInterval = 25 * time.Second
+	websocketAuthProtocol   = "taskify.bearer"
+)
+
+type StreamHandler struct {
+	db     *gorm.DB
+	broker services.StreamBroker
+}
+
+func NewStreamHandler(db *gorm.DB, broker services.StreamBroker) *StreamHandler {
+	return &StreamHandler{db: db, broker: broker}
+}
+
+// Stream pushes task events as Server-Sent Events. Clients resume after a
+// reconnect by sending the Last-Event-ID header.
+func (h *StreamHandler) Stream(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return
+	}
+
+	isAdmin, _ := c.Get("is_admin")
+
+	lastEventID := c.GetHeader("Last-Event-ID")
+	if lastEventID == "" {
+		lastEventID = c.Query("last_event_id")
+	}
+
+	sub := h.broker.Subscribe(lastEventID)
+	defer h.broker.Unsubscribe(sub)
+
+	c.Header("Content-Type", "text/event-stream")
+	c.Header("Cache-Control", "no-cache")
+	c.Header("Connection", "keep-alive")
+	c.Header("X-Accel-Buffering", "no")
+	c.Status(http.StatusOK)
+
+	send := func(event services.StreamEvent) bool {
+		if !services.TaskVisibleTo(h.db, event, userID.(uuid.UUID), isAdmin.(bool)) {
+			return true
+		}
+
+		data, err := json.Marshal(event)
+		if err != nil {
+			return true
+		}
+
+		if _, err := fmt.Fprintf(c.Writer, "id: %s\nevent: %s\ndata: %s\n\n", event.ID, event.Type, data); err != nil {
+			return false
+		}
+		c.Writer.Flush()
+		return true
+	}
+
+	for _, event := range sub.Replay {
+		if !send(event) {
+			return
+		}
+	}
+	// Flush headers even when there is nothing to replay
+	c.Writer.Flush()
+
+	heartbeat := time.NewTicker(streamHeartbeatInterval)
+	defer heartbeat.Stop()
+
+	for {
+		select {
+		case <-c.Request.Context().Done():
+			return
+		case event, ok := <-sub.Events:
+			if !ok {
+				return
+			}
+			if !send(event) {
+				return
+			}
+		case <-heartbeat.C:
+			if _, err := fmt.Fprint(c.Writer, ": ping\n\n"); err != nil {
+				return
+			}
+			c.Writer.Flush()
+		}
+	}
+}
+
+// StreamWebSocket pushes the same events as Stream over a WebSocket. Each
+// message is one JSON encoded event; resume with ?last_event_id=.
+func (h *StreamHandler) StreamWebSocket(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return
+	}
+
+	isAdmin, _ := c.Get("is_admin")
+	lastEventID := c.Query("last_event_id")
+
+	server := websocket.Server{
+		Handshake: func(config *websocket.Config, req *http.Request) error {
+			// Echo the auth subprotocol so browsers accept the handshake
+			for _, protocol := range config.Protocol {
+				if protocol == websocketAuthProtocol {
+					config.Protocol = []string{websocketAuthProtocol}
+					return nil
+				}
+			}
+			config.Protocol = nil
+			return nil
+		},
+		Handler: func(ws *websocket.Conn) {
+			defer ws.Close()
+
+			sub := h.broker.Subscribe(lastEventID)
+			defer h.broker.Unsubscribe(sub)
+
+			// The client never sends data; a failed read means it went away
+			closed := make(chan struct{})
+			go func() {
+				var discard []byte
+				for websocket.Message.Receive(ws, &discard) == nil {
+				}
+				close(closed)
+			}()
+
+			send := func(event services.StreamEvent) bool {
+				if !services.TaskVisibleTo(h.db, event, userID.(uuid.UUID), isAdmin.(bool)) {
+					return true
+				}
+				return websocket.JSON.Send(ws, event) == nil
+			}
+
+			for _, event := range sub.Replay {
+				if !send(event) {
+					return
+				}
+			}
+
+			for {
+				select {
+				case <-closed:
+					return
+				case event, ok := <-sub.Events:
+					if !ok || !send(event) {
+						return
+					}
+				}
+			}
+		},
+	}
+
+	server.ServeHTTP(c.Writer, c.Request)
+}
@@ -36,9 +36,13 @@ func (h *TaskHandler) CreateTask(c *gin.Context) {
 	}
 
 	task := models.Task{
-		Title:       req.Title,
-		Description: req.Description,
-		UserID:      userID.(uuid.UUID),
+		Title:             req.Title,
+		Description:       req.Description,
+		OriginalEstimate:  req.OriginalEstimate,
+		RemainingEstimate: req.RemainingEstimate,
+		StoryPoints:       req.StoryPoints,
+		MilestoneID:       req.MilestoneID,
+		UserID:            userID.(uuid.UUID),
 	}
 
 	if req.Status != "" {
@@ -55,6 +59,10 @@ func (h *TaskHandler) CreateTask(c *gin.Context) {
 
 	createdTask, err := h.taskService.CreateTask(h.db, task, h.cacheService)
 	if err != nil {
+		if err.Error() == "milestone not found" {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create task"})
 		return
 	}
@@ -88,8 +96,11 @@ func (h *TaskHandler) UpdateTask(c *gin.Context) {
 			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
 			return
 		}
-		if err.Error() == "unauthorized: cannot update task owned by another user" {
-			c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+		if accessDenied(c, err) {
+			return
+		}
+		if err.Error() == "milestone not found" {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 			return
 		}
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update task"})
@@ -99,6 +110,42 @@ func (h *TaskHandler) UpdateTask(c *gin.Context) {
 	c.JSON(http.StatusOK, gin.H{"message": "task updated successfully", "task": updatedTask})
 }
 
+func (h *TaskHandler) UpdateRemainingEstimate(c *gin.Context) {
+	taskIDStr := c.Param("id")
+	taskID, err := uuid.FromString(taskIDStr)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid task ID"})
+		return
+	}
+
+	var req models.TaskRemainingEstimateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return
+	}
+
+	updatedTask, err := h.taskService.UpdateRemainingEstimate(h.db, taskID, *req.RemainingEstimate, userID.(uuid.UUID), h.cacheService)
+	if err != nil {
+		if err.Error() == "task not found" {
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
+		if accessDenied(c, err) {
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update remaining estimate"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "remaining estimate updated successfully", "task": updatedTask})
+}
+
 func (h *TaskHandler) DeleteTask(c *gin.Context) {
 	taskIDStr := c.Param("id")
 	taskID, err := uuid.FromString(taskIDStr)
@@ -121,8 +168,7 @@ func (h *TaskHandler) DeleteTask(c *gin.Context) {
 			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
 			return
 		}
-		if err.Error() == "unauthorized: cannot delete task owned by another user" {
-			c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+		if accessDenied(c, err) {
 			return
 		}
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete task"})
@@ -154,8 +200,7 @@ func (h *TaskHandler) GetTaskByID(c *gin.Context) {
 			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
 			return
 		}
-		if err.Error() == "unauthorized: cannot view task owned by another user" {
-			c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+		if accessDenied(c, err) {
 			return
 		}
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get task"})
@@ -180,9 +225,9 @@ func (h *TaskHandler) GetTasksByUser(c *gin.Context) {
 		return
 	}
 
-	isAdmin, _ := c.Get("is_admin")
-	if !isAdmin.(bool) && userID != authenticatedUserID.(uuid.UUID) {
-		c.JSON(http.StatusForbidden, gin.H{"error": "Unauthorized to view other user's tasks"})
+	subject := services.PolicySubject{UserID: authenticatedUserID.(uuid.UUID), IsAdmin: c.GetBool("is_admin")}
+	resource := services.PolicyResource{Type: services.PolicyResourceUser, ID: userID, OwnerID: userID}
+	if accessDenied(c, services.Policies().Authorize(subject, services.PolicyActionRead, resource, "Unauthorized to view other user's tasks")) {
 		return
 	}
 
@@ -221,6 +266,345 @@ func (h *TaskHandler) GetTasks(c *gin.Context) {
 	c.JSON(http.StatusOK, response)
 }
 
+func (h *TaskHandler) WatchTask(c *gin.Context) {
+	taskID, err := uuid.FromString(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid task ID"})
+		return
+	}
+
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return
+	}
+
+	isAdmin, _ := c.Get("is_admin")
+
+	err = h.taskService.WatchTask(h.db, taskID, userID.(uuid.UUID), isAdmin.(bool), h.cacheService)
+	if err != nil {
+		if err.Error() == "task not found" {
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
+		if accessDenied(c, err) {
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to watch task"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "watching task"})
+}
+
+func (h *TaskHandler) UnwatchTask(c *gin.Context) {
+	taskID, err := uuid.FromString(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid task ID"})
+		return
+	}
+
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return
+	}
+
+	if err := h.taskService.UnwatchTask(h.db, taskID, userID.(uuid.UUID)); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to unwatch task"})
+		return
+	}
+
+	c.JSON(http.StatusNoContent, nil)
+}
+
+func (h *TaskHandler) GetTaskWatchers(c *gin.Context) {
+	taskID, err := uuid.FromString(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid task ID"})
+		return
+	}
+
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return
+	}
+
+	isAdmin, _ := c.Get("is_admin")
+
+	watchers, err := h.taskService.GetTaskWatchers(h.db, taskID, userID.(uuid.UUID), isAdmin.(bool), h.cacheService)
+	if err != nil {
+		if err.Error() == "task not found" {
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
+		if accessDenied(c, err) {
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get watchers"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"watchers": watchers})
+}
+
+func (h *TaskHandler) GetTeamShares(c *gin.Context) {
+	taskID, ok := uuidParam(c, "id", "Invalid task ID")
+	if !ok {
+		return
+	}
+
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return
+	}
+
+	shares, err := h.taskService.GetTeamShares(h.db, taskID, userID.(uuid.UUID))
+	if err != nil {
+		taskShareError(c, err, "Failed to get shares")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"shares": shares})
+}
+
+func (h *TaskHandler) ShareWithTeam(c *gin.Context) {
+	taskID, ok := uuidParam(c, "id", "Invalid task ID")
+	if !ok {
+		return
+	}
+	teamID, ok := uuidParam(c, "team_id", "Invalid team ID")
+	if !ok {
+		return
+	}
+
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return
+	}
+
+	var req models.ShareTaskRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	share, err := h.taskService.ShareWithTeam(h.db, taskID, teamID, req.Access, userID.(uuid.UUID))
+	if err != nil {
+		taskShareError(c, err, "Failed to share task")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"share": share})
+}
+
+func (h *TaskHandler) UnshareWithTeam(c *gin.Context) {
+	taskID, ok := uuidParam(c, "id", "Invalid task ID")
+	if !ok {
+		return
+	}
+	teamID, ok := uuidParam(c, "team_id", "Invalid team ID")
+	if !ok {
+		return
+	}
+
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return
+	}
+
+	if err := h.taskService.UnshareWithTeam(h.db, taskID, teamID, userID.(uuid.UUID)); err != nil {
+		taskShareError(c, err, "Failed to unshare task")
+		return
+	}
+
+	c.JSON(http.StatusNoContent, nil)
+}
+
+func (h *TaskHandler) GetUserShares(c *gin.Context) {
+	taskID, ok := uuidParam(c, "id", "Invalid task ID")
+	if !ok {
+		return
+	}
+
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return
+	}
+
+	shares, err := h.taskService.GetUserShares(h.db, taskID, userID.(uuid.UUID))
+	if err != nil {
+		taskShareError(c, err, "Failed to get shares")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"shares": shares})
+}
+
+func (h *TaskHandler) ShareWithUser(c *gin.Context) {
+	taskID, ok := uuidParam(c, "id", "Invalid task ID")
+	if !ok {
+		return
+	}
+	targetUserID, ok := uuidParam(c, "user_id", "Invalid user ID")
+	if !ok {
+		return
+	}
+
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return
+	}
+
+	var req models.ShareTaskRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	share, err := h.taskService.ShareWithUser(h.db, taskID, targetUserID, req.Access, userID.(uuid.UUID))
+	if err != nil {
+		taskShareError(c, err, "Failed to share task")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"share": share})
+}
+
+func (h *TaskHandler) UnshareWithUser(c *gin.Context) {
+	taskID, ok := uuidParam(c, "id", "Invalid task ID")
+	if !ok {
+		return
+	}
+	targetUserID, ok := uuidParam(c, "user_id", "Invalid user ID")
+	if !ok {
+		return
+	}
+
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return
+	}
+
+	if err := h.taskService.UnshareWithUser(h.db, taskID, targetUserID, userID.(uuid.UUID)); err != nil {
+		taskShareError(c, err, "Failed to unshare task")
+		return
+	}
+
+	c.JSON(http.StatusNoContent, nil)
+}
+
+func (h *TaskHandler) GetPublicLinks(c *gin.Context) {
+	taskID, ok := uuidParam(c, "id", "Invalid task ID")
+	if !ok {
+		return
+	}
+
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return
+	}
+
+	links, err := h.taskService.GetPublicLinks(h.db, taskID, userID.(uuid.UUID))
+	if err != nil {
+		taskShareError(c, err, "Failed to get links")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"links": links})
+}
+
+// CreatePublicLink returns the link's token, which cannot be retrieved later
+func (h *TaskHandler) CreatePublicLink(c *gin.Context) {
+	taskID, ok := uuidParam(c, "id", "Invalid task ID")
+	if !ok {
+		return
+	}
+
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return
+	}
+
+	var req models.CreateTaskPublicLinkRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	link, err := h.taskService.CreatePublicLink(h.db, taskID, req, userID.(uuid.UUID))
+	if err != nil {
+		taskShareError(c, err, "Failed to create link")
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"link": link})
+}
+
+func (h *TaskHandler) RevokePublicLink(c *gin.Context) {
+	taskID, ok := uuidParam(c, "id", "Invalid task ID")
+	if !ok {
+		return
+	}
+	linkID, ok := uuidParam(c, "link_id", "Invalid link ID")
+	if !ok {
+		return
+	}
+
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return
+	}
+
+	if err := h.taskService.RevokePublicLink(h.db, taskID, linkID, userID.(uuid.UUID)); err != nil {
+		taskShareError(c, err, "Failed to revoke link")
+		return
+	}
+
+	c.JSON(http.StatusNoContent, nil)
+}
+
+// GetPublicTask serves a public link without authentication
+func (h *TaskHandler) GetPublicTask(c *gin.Context) {
+	c.Header("Cache-Control", "no-store")
+
+	task, err := h.taskService.GetPublicTask(h.db, c.Param("token"))
+	if err != nil {
+		if err.Error() == "link not found" {
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get task"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"task": task})
+}
+
+func taskShareError(c *gin.Context, err error, message string) {
+	if accessDenied(c, err) {
+		return
+	}
+	switch err.Error() {
+	case "task not found", "team not found", "user not found", "share not found", "link not found":
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case "access must be read or write", "cannot share a task with its owner":
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": message})
+	}
+}
+
 func handleTaskError(c *gin.Context, err error) {
 	if errors.Is(err, gorm.ErrRecordNotFound) {
 		c.JSON(http.StatusNotFound, gin.H{
@@ -232,3 +616,14 @@ func handleTaskError(c *gin.Context, err error) {
 		})
 	}
 }
+
+// accessDenied answers 403 with the policy decision when err is an
+// *services.AccessDeniedError
+func accessDenied(c *gin.Context, err error) bool {
+	var deniedErr *services.AccessDeniedError
+	if !errors.As(err, &deniedErr) {
+		return false
+	}
+	c.JSON(http.StatusForbidden, gin.H{"error": deniedErr.Error(), "reason": deniedErr.Decision.Reason})
+	return true
+}
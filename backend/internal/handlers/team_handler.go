@@ -0,0 +1,221 @@
+package handlers
+
+import (
+	"net/http"
+	"task-manager/backend/internal/models"
+	"task-manager/backend/internal/services"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gofrs/uuid"
+	"gorm.io/gorm"
+)
+
+type TeamHandler struct {
+	db          *gorm.DB
+	teamService services.TeamService
+}
+
+func NewTeamHandler(db *gorm.DB, teamService services.TeamService) *TeamHandler {
+	return &TeamHandler{db: db, teamService: teamService}
+}
+
+// GetTeams lists every team for admins and the caller's own teams otherwise
+func (h *TeamHandler) GetTeams(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return
+	}
+
+	teams, err := h.teamService.GetTeams(h.db, userID.(uuid.UUID), c.GetBool("is_admin"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get teams"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"teams": teams})
+}
+
+func (h *TeamHandler) CreateTeam(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return
+	}
+
+	var req models.CreateTeamRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	team, err := h.teamService.CreateTeam(h.db, userID.(uuid.UUID), req)
+	if err != nil {
+		teamError(c, err, "Failed to create team")
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"team": team})
+}
+
+func (h *TeamHandler) UpdateTeam(c *gin.Context) {
+	teamID, ok := uuidParam(c, "id", "Invalid team ID")
+	if !ok {
+		return
+	}
+
+	var req models.UpdateTeamRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	team, err := h.teamService.UpdateTeam(h.db, teamID, req)
+	if err != nil {
+		teamError(c, err, "Failed to update team")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"team": team})
+}
+
+func (h *TeamHandler) DeleteTeam(c *gin.Context) {
+	teamID, ok := uuidParam(c, "id", "Invalid team ID")
+	if !ok {
+		return
+	}
+
+	if err := h.teamService.DeleteTeam(h.db, teamID); err != nil {
+		teamError(c, err, "Failed to delete team")
+		return
+	}
+
+	c.JSON(http.StatusNoContent, nil)
+}
+
+// GetMembers is open to admins and the team's own members
+func (h *TeamHandler) GetMembers(c *gin.Context) {
+	teamID, ok := uuidParam(c, "id", "Invalid team ID")
+	if !ok {
+		return
+	}
+
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return
+	}
+
+	members, err := h.teamService.GetMembers(h.db, teamID, userID.(uuid.UUID), c.GetBool("is_admin"))
+	if err != nil {
+		teamError(c, err, "Failed to get team members")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"members": members})
+}
+
+func (h *TeamHandler) AddMember(c *gin.Context) {
+	teamID, ok := uuidParam(c, "id", "Invalid team ID")
+	if !ok {
+		return
+	}
+
+	var req models.AddTeamMemberRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := h.teamService.AddMember(h.db, teamID, req.UserID); err != nil {
+		teamError(c, err, "Failed to add team member")
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"message": "member added"})
+}
+
+func (h *TeamHandler) RemoveMember(c *gin.Context) {
+	teamID, ok := uuidParam(c, "id", "Invalid team ID")
+	if !ok {
+		return
+	}
+	userID, ok := uuidParam(c, "user_id", "Invalid user ID")
+	if !ok {
+		return
+	}
+
+	if err := h.teamService.RemoveMember(h.db, teamID, userID); err != nil {
+		teamError(c, err, "Failed to remove team member")
+		return
+	}
+
+	c.JSON(http.StatusNoContent, nil)
+}
+
+func (h *TeamHandler) GetTeamRoles(c *gin.Context) {
+	teamID, ok := uuidParam(c, "id", "Invalid team ID")
+	if !ok {
+		return
+	}
+
+	roles, err := h.teamService.GetTeamRoles(h.db, teamID)
+	if err != nil {
+		teamError(c, err, "Failed to get team roles")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"roles": roles})
+}
+
+// GrantRole gives the role to every member of the team
+func (h *TeamHandler) GrantRole(c *gin.Context) {
+	teamID, ok := uuidParam(c, "id", "Invalid team ID")
+	if !ok {
+		return
+	}
+
+	var req models.GrantRoleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := h.teamService.GrantRole(h.db, teamID, req.RoleID); err != nil {
+		teamError(c, err, "Failed to grant role")
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"message": "role granted"})
+}
+
+func (h *TeamHandler) RevokeRole(c *gin.Context) {
+	teamID, ok := uuidParam(c, "id", "Invalid team ID")
+	if !ok {
+		return
+	}
+	roleID, ok := uuidParam(c, "role_id", "Invalid role ID")
+	if !ok {
+		return
+	}
+
+	if err := h.teamService.RevokeRole(h.db, teamID, roleID); err != nil {
+		teamError(c, err, "Failed to revoke role")
+		return
+	}
+
+	c.JSON(http.StatusNoContent, nil)
+}
+
+func teamError(c *gin.Context, err error, message string) {
+	switch err.Error() {
+	case "team not found", "user not found", "role not found", "role not granted", "user is not a member":
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case "team already exists", "role already granted", "user is already a member", "cannot remove the last admin":
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	case "team name cannot be empty":
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": message})
+	}
+}
@@ -0,0 +1,196 @@
+package handlers
+
+import (
+	"net/http"
+	"task-manager/backend/internal/models"
+	"task-manager/backend/internal/services"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gofrs/uuid"
+	"gorm.io/gorm"
+)
+
+type UserAdminHandler struct {
+	db               *gorm.DB
+	userAdminService services.UserAdminService
+}
+
+func NewUserAdminHandler(db *gorm.DB, userAdminService services.UserAdminService) *UserAdminHandler {
+	return &UserAdminHandler{db: db, userAdminService: userAdminService}
+}
+
+func (h *UserAdminHandler) CreateUser(c *gin.Context) {
+	adminID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return
+	}
+
+	var req models.AdminCreateUserRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	user, err := h.userAdminService.CreateUser(h.db, adminID.(uuid.UUID), req)
+	if err != nil {
+		if passwordPolicyError(c, err) {
+			return
+		}
+		userAdminError(c, err, "Failed to create user")
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"user": user})
+}
+
+func (h *UserAdminHandler) UpdateUser(c *gin.Context) {
+	adminID, userID, ok := adminTarget(c)
+	if !ok {
+		return
+	}
+
+	var req models.AdminUpdateUserRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	user, err := h.userAdminService.UpdateUser(h.db, adminID, userID, req)
+	if err != nil {
+		userAdminError(c, err, "Failed to update user")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"user": user})
+}
+
+func (h *UserAdminHandler) DisableUser(c *gin.Context) {
+	adminID, userID, ok := adminTarget(c)
+	if !ok {
+		return
+	}
+
+	if err := h.userAdminService.DisableUser(h.db, adminID, userID); err != nil {
+		userAdminError(c, err, "Failed to disable user")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "user disabled"})
+}
+
+func (h *UserAdminHandler) EnableUser(c *gin.Context) {
+	adminID, userID, ok := adminTarget(c)
+	if !ok {
+		return
+	}
+
+	if err := h.userAdminService.EnableUser(h.db, adminID, userID); err != nil {
+		userAdminError(c, err, "Failed to enable user")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "user enabled"})
+}
+
+// ForcePasswordReset signs the user out and emails them a reset link
+func (h *UserAdminHandler) ForcePasswordReset(c *gin.Context) {
+	adminID, userID, ok := adminTarget(c)
+	if !ok {
+		return
+	}
+
+	if err := h.userAdminService.ForcePasswordReset(h.db, adminID, userID); err != nil {
+		userAdminError(c, err, "Failed to force password reset")
+		return
+	}
+
+	c.JSON(http.StatusAccepted, gin.H{"message": "password reset required, a reset link was sent"})
+}
+
+func (h *UserAdminHandler) GetUserRoles(c *gin.Context) {
+	userID, err := uuid.FromString(c.Param("user_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID"})
+		return
+	}
+
+	roles, err := h.userAdminService.GetUserRoles(h.db, userID)
+	if err != nil {
+		userAdminError(c, err, "Failed to get roles")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"roles": roles})
+}
+
+func (h *UserAdminHandler) GrantRole(c *gin.Context) {
+	adminID, userID, ok := adminTarget(c)
+	if !ok {
+		return
+	}
+
+	var req models.GrantRoleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := h.userAdminService.GrantRole(h.db, adminID, userID, req.RoleID); err != nil {
+		userAdminError(c, err, "Failed to grant role")
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"message": "role granted"})
+}
+
+func (h *UserAdminHandler) RevokeRole(c *gin.Context) {
+	adminID, userID, ok := adminTarget(c)
+	if !ok {
+		return
+	}
+
+	roleID, err := uuid.FromString(c.Param("role_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid role ID"})
+		return
+	}
+
+	if err := h.userAdminService.RevokeRole(h.db, adminID, userID, roleID); err != nil {
+		userAdminError(c, err, "Failed to revoke role")
+		return
+	}
+
+	c.JSON(http.StatusNoContent, nil)
+}
+
+// adminTarget reads the signed in admin and the user_id path parameter
+func adminTarget(c *gin.Context) (uuid.UUID, uuid.UUID, bool) {
+	adminID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return uuid.Nil, uuid.Nil, false
+	}
+
+	userID, err := uuid.FromString(c.Param("user_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID"})
+		return uuid.Nil, uuid.Nil, false
+	}
+
+	return adminID.(uuid.UUID), userID, true
+}
+
+func userAdminError(c *gin.Context, err error, message string) {
+	switch err.Error() {
+	case "user not found", "role not found", "role not granted":
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case "username already exists", "email already exists", "role already granted",
+		"cannot remove the last admin", "cannot disable your own account":
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	case "username cannot be empty":
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": message})
+	}
+}
@@ -2,6 +2,7 @@ package handlers
 
 import (
 	"net/http"
+	"task-manager/backend/internal/models"
 	"task-manager/backend/internal/services"
 
 	"github.com/gin-gonic/gin"
@@ -31,7 +32,15 @@ func (h *UserHandler) GetUserProfile(c *gin.Context) {
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{"user": user})
+	// The roles and permissions the request was authorized with, which
+	// compact access tokens do not carry
+	permissions, _ := c.Get("permissions")
+	c.JSON(http.StatusOK, gin.H{
+		"user":        user,
+		"roles":       c.GetStringSlice("roles"),
+		"is_admin":    c.GetBool("is_admin"),
+		"permissions": permissions,
+	})
 }
 
 func (h *UserHandler) GetUserProfileByUserId(c *gin.Context) {
@@ -49,9 +58,9 @@ func (h *UserHandler) GetUserProfileByUserId(c *gin.Context) {
 		return
 	}
 
-	isAdmin, _ := c.Get("is_admin")
-	if !isAdmin.(bool) && userID != authenticatedUserID.(uuid.UUID) {
-		c.JSON(http.StatusForbidden, gin.H{"error": "Unauthorized to view other user's profile"})
+	subject := services.PolicySubject{UserID: authenticatedUserID.(uuid.UUID), IsAdmin: c.GetBool("is_admin")}
+	resource := services.PolicyResource{Type: services.PolicyResourceUser, ID: userID, OwnerID: userID}
+	if accessDenied(c, services.Policies().Authorize(subject, services.PolicyActionRead, resource, "Unauthorized to view other user's profile")) {
 		return
 	}
 
@@ -102,9 +111,133 @@ func (h *UserHandler) DeleteUser(c *gin.Context) {
 			c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
 			return
 		}
+		if err.Error() == "cannot remove the last admin" {
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+			return
+		}
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete user"})
 		return
 	}
 
 	c.JSON(http.StatusNoContent, nil)
 }
+
+// UpdateProfile changes the username, email address or display name of the
+// signed in user
+func (h *UserHandler) UpdateProfile(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return
+	}
+
+	var req models.UpdateProfileRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	user, err := h.userService.UpdateProfile(h.db, userID.(uuid.UUID), req, clientInfo(c))
+	if err != nil {
+		switch err.Error() {
+		case "username already exists", "email already exists":
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		case "username cannot be empty":
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		case "user not found":
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update profile"})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"user": user})
+}
+
+// ChangePassword signs out every other session of the user
+func (h *UserHandler) ChangePassword(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return
+	}
+
+	var req models.ChangePasswordRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	sessionID, _ := c.Get("session_id")
+	currentSessionID, _ := sessionID.(uuid.UUID)
+
+	if err := h.userService.ChangePassword(h.db, userID.(uuid.UUID), currentSessionID, req.CurrentPassword, req.NewPassword, clientInfo(c)); err != nil {
+		if passwordPolicyError(c, err) {
+			return
+		}
+		switch err.Error() {
+		case "current password is incorrect":
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		case "user not found":
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to change password"})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "password changed, other sessions were signed out"})
+}
+
+// DeleteAccount schedules the signed in user's account for deletion after
+// the grace period
+func (h *UserHandler) DeleteAccount(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return
+	}
+
+	var req models.DeleteAccountRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	deleteAt, err := h.userService.ScheduleDeletion(h.db, userID.(uuid.UUID), req.Password, clientInfo(c))
+	if err != nil {
+		switch err.Error() {
+		case "current password is incorrect":
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		case "user not found":
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		case "cannot remove the last admin":
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to schedule account deletion"})
+		}
+		return
+	}
+
+	c.JSON(http.StatusAccepted, gin.H{"message": "account deletion scheduled", "deletion_scheduled_at": deleteAt})
+}
+
+func (h *UserHandler) CancelAccountDeletion(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return
+	}
+
+	if err := h.userService.CancelDeletion(h.db, userID.(uuid.UUID), clientInfo(c)); err != nil {
+		if err.Error() == "no deletion scheduled" {
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to cancel account deletion"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "account deletion cancelled"})
+}
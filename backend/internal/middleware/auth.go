@@ -6,9 +6,43 @@ import (
 	"task-manager/backend/internal/utils"
 
 	"github.com/gin-gonic/gin"
+	"github.com/gofrs/uuid"
 )
 
-func AuthMiddleware() gin.HandlerFunc {
+// TokenRevocationChecker reports whether a valid token was revoked before
+// it expired
+type TokenRevocationChecker interface {
+	IsRevoked(claims *utils.Claims) bool
+}
+
+// PersonalAccessTokenAuthenticator resolves a "pat_" API token to the
+// claims of its owner, limited to the token's scopes
+type PersonalAccessTokenAuthenticator interface {
+	AuthenticatePersonalAccessToken(token, ipAddress string) (*utils.Claims, error)
+}
+
+// PermissionResolver looks up the current roles and permissions of a user,
+// so they apply without waiting for a new access token
+type PermissionResolver interface {
+	ResolvePermissions(userID uuid.UUID) ([]string, bool, []utils.Permission, error)
+}
+
+// Values of the "auth_type" context key
+const (
+	AuthTypeJWT                 = "jwt"
+	AuthTypePersonalAccessToken = "personal_access_token"
+)
+
+// AuthMiddlewareConfig defines the configuration for AuthMiddleware
+type AuthMiddlewareConfig struct {
+	Revocations          TokenRevocationChecker
+	PersonalAccessTokens PersonalAccessTokenAuthenticator
+	// Permissions, when set, replaces the roles and permissions an access
+	// token carries; compact tokens carry none
+	Permissions PermissionResolver
+}
+
+func AuthMiddleware(config AuthMiddlewareConfig) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		authHeader := c.GetHeader("Authorization")
 		if authHeader == "" {
@@ -27,6 +61,22 @@ func AuthMiddleware() gin.HandlerFunc {
 		// Extract the token
 		tokenString := strings.TrimPrefix(authHeader, "Bearer ")
 
+		// API tokens are looked up instead of verified; deleting one is
+		// what revokes it
+		if config.PersonalAccessTokens != nil && strings.HasPrefix(tokenString, "pat_") {
+			claims, err := config.PersonalAccessTokens.AuthenticatePersonalAccessToken(tokenString, c.ClientIP())
+			if err != nil {
+				c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid token"})
+				c.Abort()
+				return
+			}
+
+			setClaims(c, claims)
+			c.Set("auth_type", AuthTypePersonalAccessToken)
+			c.Next()
+			return
+		}
+
 		// Validate the token
 		claims, err := utils.ValidateJWT(tokenString)
 		if err != nil {
@@ -35,18 +85,64 @@ func AuthMiddleware() gin.HandlerFunc {
 			return
 		}
 
-		// Set user information in context
-		c.Set("user_id", claims.UserID)
-		c.Set("username", claims.Username)
-		c.Set("roles", claims.Roles)
-		c.Set("is_admin", claims.IsAdmin)
-		c.Set("permissions", claims.Permissions)
+		// Logged out, deleted or demoted users must not keep using old tokens
+		if config.Revocations != nil && config.Revocations.IsRevoked(claims) {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "Token has been revoked"})
+			c.Abort()
+			return
+		}
+
+		if config.Permissions != nil {
+			roles, isAdmin, permissions, err := config.Permissions.ResolvePermissions(claims.UserID)
+			if err != nil {
+				c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to resolve permissions"})
+				c.Abort()
+				return
+			}
+			claims.Roles, claims.IsAdmin, claims.Permissions = roles, isAdmin, permissions
+		}
 
+		setClaims(c, claims)
+		c.Set("auth_type", AuthTypeJWT)
 		c.Next()
 	}
 }
 
+// setClaims puts the user information into the context
+func setClaims(c *gin.Context, claims *utils.Claims) {
+	c.Set("user_id", claims.UserID)
+	c.Set("username", claims.Username)
+	c.Set("roles", claims.Roles)
+	c.Set("is_admin", claims.IsAdmin)
+	c.Set("permissions", claims.Permissions)
+	c.Set("session_id", claims.SessionID)
+	c.Set("token_id", claims.ID)
+	if claims.ExpiresAt != nil {
+		c.Set("token_expires_at", claims.ExpiresAt.Time)
+	}
+}
+
+// RejectPersonalAccessTokens keeps API tokens away from account management,
+// so a leaked token cannot mint more tokens or take over the account
+func RejectPersonalAccessTokens() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if c.GetString("auth_type") == AuthTypePersonalAccessToken {
+			c.JSON(http.StatusForbidden, gin.H{"error": "Personal access tokens cannot be used here"})
+			c.Abort()
+			return
+		}
+
+		c.Next()
+	}
+}
+
+// RequirePermission checks the permissions AuthMiddleware put into the
+// context, resolved live when it has a PermissionResolver. It also adds the
+// permission to RequiredPermissions, which startup checks against the
+// permissions table.
 func RequirePermission(resource, action string) gin.HandlerFunc {
+	registerRequiredPermission(resource, action)
+
 	return func(c *gin.Context) {
 		permissions, exists := c.Get("permissions")
 		if !exists {
@@ -91,3 +187,19 @@ func RequireAdmin() gin.HandlerFunc {
 		c.Next()
 	}
 }
+
+// WebSocketProtocolAuth lets browsers authenticate WebSocket upgrades, which
+// cannot carry an Authorization header, by sending the token as a second
+// subprotocol: Sec-WebSocket-Protocol: taskify.bearer, <token>
+func WebSocketProtocolAuth() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if c.GetHeader("Authorization") == "" {
+			protocols := strings.Split(c.GetHeader("Sec-WebSocket-Protocol"), ",")
+			if len(protocols) == 2 && strings.TrimSpace(protocols[0]) == "taskify.bearer" {
+				c.Request.Header.Set("Authorization", "Bearer "+strings.TrimSpace(protocols[1]))
+			}
+		}
+
+		c.Next()
+	}
+}
@@ -0,0 +1,46 @@
+package middleware
+
+import (
+	"sort"
+	"sync"
+	"task-manager/backend/internal/utils"
+)
+
+// requiredPermissions collects every permission passed to RequirePermission.
+// Routes call RequirePermission while they are registered, so once the
+// router is set up this is the set of permissions the API actually checks.
+var requiredPermissions = struct {
+	sync.Mutex
+	actions map[string]map[string]bool
+}{actions: map[string]map[string]bool{}}
+
+func registerRequiredPermission(resource, action string) {
+	requiredPermissions.Lock()
+	defer requiredPermissions.Unlock()
+
+	if requiredPermissions.actions[resource] == nil {
+		requiredPermissions.actions[resource] = map[string]bool{}
+	}
+	requiredPermissions.actions[resource][action] = true
+}
+
+// RequiredPermissions returns the permissions checked by registered routes,
+// sorted by resource and action
+func RequiredPermissions() []utils.Permission {
+	requiredPermissions.Lock()
+	defer requiredPermissions.Unlock()
+
+	var permissions []utils.Permission
+	for resource, actions := range requiredPermissions.actions {
+		permission := utils.Permission{Resource: resource}
+		for action := range actions {
+			permission.Actions = append(permission.Actions, action)
+		}
+		sort.Strings(permission.Actions)
+		permissions = append(permissions, permission)
+	}
+	sort.Slice(permissions, func(i, j int) bool {
+		return permissions[i].Resource < permissions[j].Resource
+	})
+	return permissions
+}
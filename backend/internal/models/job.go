@@ -0,0 +1,43 @@
+package models
+
+import (
+	"time"
+
+	"github.com/gofrs/uuid"
+)
+
+const (
+	JobStatusQueued    = "queued"
+	JobStatusRunning   = "running"
+	JobStatusSucceeded = "succeeded"
+	JobStatusFailed    = "failed"
+)
+
+// ScheduledJob is the state of a recurring job shared by all replicas
+type ScheduledJob struct {
+	Name       string     `json:"name" gorm:"primaryKey"`
+	Schedule   string     `json:"schedule" gorm:"not null"`
+	Paused     bool       `json:"paused" gorm:"not null;default:false"`
+	NextRunAt  *time.Time `json:"next_run_at"`
+	LastRunAt  *time.Time `json:"last_run_at"`
+	LastStatus string     `json:"last_status"`
+	LastError  string     `json:"last_error"`
+	CreatedAt  time.Time  `json:"created_at" gorm:"not null"`
+	UpdatedAt  time.Time  `json:"updated_at" gorm:"not null"`
+}
+
+// Job is one queued run of a job, either scheduled or triggered by an admin
+type Job struct {
+	ID          uuid.UUID  `json:"id" gorm:"primaryKey;type:uuid;default:gen_random_uuid()"`
+	Name        string     `json:"name" gorm:"not null;index"`
+	Status      string     `json:"status" gorm:"not null;default:queued;index"`
+	Attempts    int        `json:"attempts" gorm:"not null;default:0"`
+	MaxAttempts int        `json:"max_attempts" gorm:"not null;default:1"`
+	RunAt       time.Time  `json:"run_at" gorm:"not null;index"`
+	LockedAt    *time.Time `json:"locked_at"`
+	LastError   string     `json:"last_error"`
+	TriggeredBy *uuid.UUID `json:"triggered_by" gorm:"type:uuid"`
+	FinishedAt  *time.Time `json:"finished_at"`
+	CreatedAt   time.Time  `json:"created_at" gorm:"not null"`
+	UpdatedAt   time.Time  `json:"updated_at" gorm:"not null"`
+}
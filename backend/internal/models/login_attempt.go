@@ -0,0 +1,20 @@
+package models
+
+import (
+	"time"
+
+	"github.com/gofrs/uuid"
+)
+
+// LoginAttempt records every password login, failed or not. UserID is nil
+// when the username matched no account.
+type LoginAttempt struct {
+	ID        uuid.UUID  `json:"id" gorm:"primaryKey;type:uuid;default:gen_random_uuid()"`
+	UserID    *uuid.UUID `json:"user_id" gorm:"type:uuid;index"`
+	Username  string     `json:"username" gorm:"not null"`
+	Success   bool       `json:"success" gorm:"not null"`
+	IPAddress string     `json:"ip_address" gorm:"index"`
+	UserAgent string     `json:"user_agent"`
+	Device    string     `json:"device"`
+	CreatedAt time.Time  `json:"created_at" gorm:"not null;index"`
+}
@@ -0,0 +1,76 @@
+package models
+
+import (
+	"time"
+
+	"github.com/gofrs/uuid"
+)
+
+// UserMFA holds a user's TOTP secret. It only protects logins once the user
+// has confirmed it with a code from their authenticator app.
+type UserMFA struct {
+	UserID       uuid.UUID  `json:"user_id" gorm:"primaryKey;type:uuid"`
+	Secret       string     `json:"-" gorm:"not null"`
+	Enabled      bool       `json:"enabled" gorm:"not null;default:false"`
+	ConfirmedAt  *time.Time `json:"confirmed_at"`
+	LastUsedStep int64      `json:"-" gorm:"not null;default:0"`
+	CreatedAt    time.Time  `json:"created_at" gorm:"not null"`
+	UpdatedAt    time.Time  `json:"updated_at" gorm:"not null"`
+}
+
+func (UserMFA) TableName() string {
+	return "user_mfa"
+}
+
+// MFARecoveryCode is a single use code for when the authenticator is lost
+type MFARecoveryCode struct {
+	ID        uuid.UUID  `json:"id" gorm:"primaryKey;type:uuid;default:gen_random_uuid()"`
+	UserID    uuid.UUID  `json:"user_id" gorm:"type:uuid;not null;index"`
+	CodeHash  string     `json:"-" gorm:"not null"`
+	UsedAt    *time.Time `json:"used_at"`
+	CreatedAt time.Time  `json:"created_at" gorm:"not null"`
+}
+
+// MFAChallenge is a password login waiting for its second factor. Enrollment
+// challenges belong to users whose role requires 2FA before they have set
+// it up; they enrol and finish logging in with the same token.
+type MFAChallenge struct {
+	ID         uuid.UUID `json:"id" gorm:"primaryKey;type:uuid;default:gen_random_uuid()"`
+	UserID     uuid.UUID `json:"user_id" gorm:"type:uuid;not null;index"`
+	TokenHash  string    `json:"-" gorm:"not null;uniqueIndex"`
+	Enrollment bool      `json:"enrollment" gorm:"not null;default:false"`
+	Attempts   int       `json:"attempts" gorm:"not null;default:0"`
+	ExpiresAt  time.Time `json:"expires_at" gorm:"not null;index"`
+	CreatedAt  time.Time `json:"created_at" gorm:"not null"`
+}
+
+// MFAStatus describes a user's 2FA setup
+type MFAStatus struct {
+	Enabled                bool       `json:"enabled"`
+	Required               bool       `json:"required"`
+	ConfirmedAt            *time.Time `json:"confirmed_at,omitempty"`
+	RecoveryCodesRemaining int64      `json:"recovery_codes_remaining"`
+}
+
+// MFAEnrollment is what an authenticator app needs to add the account
+type MFAEnrollment struct {
+	Secret          string `json:"secret"`
+	ProvisioningURI string `json:"provisioning_uri"`
+}
+
+type MFACodeRequest struct {
+	Code string `json:"code" binding:"required"`
+}
+
+type MFAVerifyRequest struct {
+	MFAToken string `json:"mfa_token" binding:"required"`
+	Code     string `json:"code" binding:"required"`
+}
+
+type MFAEnrollRequest struct {
+	MFAToken string `json:"mfa_token" binding:"required"`
+}
+
+type RoleMFARequest struct {
+	Required *bool `json:"required" binding:"required"`
+}
@@ -0,0 +1,48 @@
+package models
+
+import (
+	"time"
+
+	"github.com/gofrs/uuid"
+)
+
+type Milestone struct {
+	ID        uuid.UUID  `json:"id" gorm:"primaryKey;type:uuid;default:gen_random_uuid()"`
+	Name      string     `json:"name" gorm:"not null"`
+	Goal      string     `json:"goal"`
+	StartDate time.Time  `json:"start_date" gorm:"not null"`
+	EndDate   time.Time  `json:"end_date" gorm:"not null"`
+	CreatedBy uuid.UUID  `json:"created_by" gorm:"type:uuid"`
+	CreatedAt time.Time  `json:"created_at" gorm:"not null"`
+	UpdatedAt time.Time  `json:"updated_at" gorm:"not null"`
+	DeletedAt *time.Time `json:"-" gorm:"index"`
+}
+
+type MilestoneCreateRequest struct {
+	Name      string    `json:"name" binding:"required"`
+	Goal      string    `json:"goal"`
+	StartDate time.Time `json:"start_date" binding:"required"`
+	EndDate   time.Time `json:"end_date" binding:"required"`
+}
+
+type MilestoneUpdateRequest struct {
+	Name      *string    `json:"name"`
+	Goal      *string    `json:"goal"`
+	StartDate *time.Time `json:"start_date"`
+	EndDate   *time.Time `json:"end_date"`
+}
+
+// BurndownPoint is the remaining task count at the end of one day
+type BurndownPoint struct {
+	Date      string  `json:"date"`
+	Remaining int     `json:"remaining"`
+	Ideal     float64 `json:"ideal"`
+}
+
+type Burndown struct {
+	MilestoneID uuid.UUID       `json:"milestone_id"`
+	StartDate   time.Time       `json:"start_date"`
+	EndDate     time.Time       `json:"end_date"`
+	TotalTasks  int             `json:"total_tasks"`
+	Points      []BurndownPoint `json:"points"`
+}
@@ -0,0 +1,27 @@
+package models
+
+import (
+	"time"
+
+	"github.com/gofrs/uuid"
+)
+
+const (
+	NotificationTaskCreated = "task.created"
+	NotificationTaskUpdated = "task.updated"
+	NotificationTaskDeleted = "task.deleted"
+)
+
+type Notification struct {
+	ID        uuid.UUID  `json:"id" gorm:"primaryKey;type:uuid;default:gen_random_uuid()"`
+	UserID    uuid.UUID  `json:"user_id" gorm:"not null;index"`
+	ActorID   *uuid.UUID `json:"actor_id" gorm:"type:uuid"`
+	TaskID    *uuid.UUID `json:"task_id" gorm:"type:uuid"`
+	Type      string     `json:"type" gorm:"not null"`
+	Message   string     `json:"message" gorm:"not null"`
+	ReadAt    *time.Time `json:"read_at"`
+	CreatedAt time.Time  `json:"created_at" gorm:"not null"`
+
+	// EmailPending marks notifications waiting for the recipient's next digest
+	EmailPending bool `json:"-" gorm:"not null;default:false;index"`
+}
@@ -0,0 +1,28 @@
+package models
+
+import (
+	"time"
+
+	"github.com/gofrs/uuid"
+)
+
+const (
+	EmailModeImmediate = "immediate"
+	EmailModeHourly    = "hourly"
+	EmailModeDaily     = "daily"
+	EmailModeOff       = "off"
+)
+
+// NotificationPreference controls how a user is emailed about changes to
+// their own tasks
+type NotificationPreference struct {
+	UserID       uuid.UUID  `json:"user_id" gorm:"primaryKey;type:uuid"`
+	EmailMode    string     `json:"email_mode" gorm:"not null;default:immediate"`
+	LastDigestAt *time.Time `json:"last_digest_at"`
+	CreatedAt    time.Time  `json:"created_at" gorm:"not null"`
+	UpdatedAt    time.Time  `json:"updated_at" gorm:"not null"`
+}
+
+type NotificationPreferenceUpdateRequest struct {
+	EmailMode string `json:"email_mode" binding:"required,oneof=immediate hourly daily off"`
+}
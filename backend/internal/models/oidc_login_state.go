@@ -0,0 +1,13 @@
+package models
+
+import "time"
+
+// OIDCLoginState remembers a started single sign-on login until the identity
+// provider redirects back. It is deleted when the login completes.
+type OIDCLoginState struct {
+	State        string    `json:"-" gorm:"primaryKey"`
+	Nonce        string    `json:"-" gorm:"not null"`
+	CodeVerifier string    `json:"-" gorm:"not null"`
+	ExpiresAt    time.Time `json:"expires_at" gorm:"not null;index"`
+	CreatedAt    time.Time `json:"created_at" gorm:"not null"`
+}
@@ -0,0 +1,44 @@
+package models
+
+import (
+	"time"
+
+	"github.com/gofrs/uuid"
+)
+
+// Domain event types. Task events share their names with the webhook event
+// types so subscribers can forward them unchanged.
+const (
+	DomainEventTaskCreated = "task.created"
+	DomainEventTaskUpdated = "task.updated"
+	DomainEventTaskDeleted = "task.deleted"
+	DomainEventUserDeleted = "user.deleted"
+	// Raised when a user gains or loses a role, and when a role's
+	// permissions or name change
+	DomainEventUserRolesChanged       = "user.roles_changed"
+	DomainEventRolePermissionsChanged = "role.permissions_changed"
+)
+
+const (
+	OutboxEventPending   = "pending"
+	OutboxEventProcessed = "processed"
+	OutboxEventFailed    = "failed"
+)
+
+// OutboxEvent is a domain event stored in the same transaction as the change
+// that raised it, until every subscriber has handled it
+type OutboxEvent struct {
+	ID            uuid.UUID  `json:"id" gorm:"primaryKey;type:uuid;default:gen_random_uuid()"`
+	EventType     string     `json:"event_type" gorm:"not null;index"`
+	AggregateID   uuid.UUID  `json:"aggregate_id" gorm:"type:uuid;not null;index"`
+	ActorID       uuid.UUID  `json:"actor_id" gorm:"type:uuid"`
+	Payload       string     `json:"payload" gorm:"type:text;not null"`
+	Status        string     `json:"status" gorm:"not null;default:pending;index"`
+	DeliveredTo   []string   `json:"delivered_to" gorm:"type:text;serializer:json"`
+	Attempts      int        `json:"attempts" gorm:"not null;default:0"`
+	LastError     string     `json:"last_error"`
+	NextAttemptAt time.Time  `json:"next_attempt_at" gorm:"not null;index"`
+	ProcessedAt   *time.Time `json:"processed_at"`
+	CreatedAt     time.Time  `json:"created_at" gorm:"not null"`
+	UpdatedAt     time.Time  `json:"updated_at" gorm:"not null"`
+}
@@ -16,3 +16,21 @@ type Permission struct {
 	UpdatedAt time.Time `json:"updated_at"`
 	DeletedAt gorm.DeletedAt `json:"-" gorm:"index"`
 }
+
+type CreatePermissionRequest struct {
+	Resource string `json:"resource" binding:"required,max=50"`
+	Action   string `json:"action" binding:"required,max=50"`
+}
+
+type GrantPermissionRequest struct {
+	PermissionID uuid.UUID `json:"permission_id" binding:"required"`
+}
+
+// CataloguePermission is a permission some route requires, with whether it
+// exists and which roles hold it
+type CataloguePermission struct {
+	Resource     string     `json:"resource"`
+	Action       string     `json:"action"`
+	PermissionID *uuid.UUID `json:"permission_id"`
+	Roles        []string   `json:"roles"`
+}
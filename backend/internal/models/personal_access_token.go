@@ -0,0 +1,44 @@
+package models
+
+import (
+	"task-manager/backend/internal/utils"
+	"time"
+
+	"github.com/gofrs/uuid"
+)
+
+// PersonalAccessTokenPrefix marks API tokens so they can be told apart from
+// JWTs and spotted by secret scanners
+const PersonalAccessTokenPrefix = "pat_"
+
+// PersonalAccessToken is a long lived API token for scripts. Only its hash
+// is stored; the token itself is shown once when it is created.
+type PersonalAccessToken struct {
+	ID     uuid.UUID `json:"id" gorm:"primaryKey;type:uuid;default:gen_random_uuid()"`
+	UserID uuid.UUID `json:"user_id" gorm:"type:uuid;not null;index"`
+	Name   string    `json:"name" gorm:"not null"`
+	// Hint is the start of the token, enough to recognise it in a list
+	Hint      string             `json:"hint" gorm:"not null"`
+	TokenHash string             `json:"-" gorm:"not null;uniqueIndex"`
+	Scopes    []utils.Permission `json:"scopes" gorm:"type:text;serializer:json"`
+	// Admin lets the token use admin endpoints while its owner is an admin
+	Admin      bool       `json:"admin" gorm:"not null;default:false"`
+	ExpiresAt  time.Time  `json:"expires_at" gorm:"not null"`
+	LastUsedAt *time.Time `json:"last_used_at"`
+	LastUsedIP string     `json:"last_used_ip"`
+	CreatedAt  time.Time  `json:"created_at" gorm:"not null"`
+}
+
+type CreatePersonalAccessTokenRequest struct {
+	Name   string             `json:"name" binding:"required,max=100"`
+	Scopes []utils.Permission `json:"scopes" binding:"required,min=1"`
+	Admin  bool               `json:"admin"`
+	// ExpiresInDays defaults to 30
+	ExpiresInDays int `json:"expires_in_days" binding:"omitempty,min=1,max=365"`
+}
+
+// CreatedPersonalAccessToken is the only response that contains the token
+type CreatedPersonalAccessToken struct {
+	PersonalAccessToken
+	Token string `json:"token"`
+}
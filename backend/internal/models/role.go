@@ -11,7 +11,19 @@ type Role struct {
 	gorm.Model
 	ID        uuid.UUID `json:"id" gorm:"primaryKey;type:uuid;default:gen_random_uuid()"`
 	Name      string    `json:"name" gorm:"unique;not null"`
+	RequireMFA bool      `json:"require_mfa" gorm:"not null;default:false"`
 	CreatedAt time.Time `json:"created_at"`
 	UpdatedAt time.Time `json:"updated_at"`
 	DeletedAt gorm.DeletedAt `json:"-" gorm:"index"`
 }
+
+type CreateRoleRequest struct {
+	Name       string `json:"name" binding:"required,max=50"`
+	RequireMFA bool   `json:"require_mfa"`
+}
+
+// UpdateRoleRequest changes only the fields that are set
+type UpdateRoleRequest struct {
+	Name       *string `json:"name" binding:"omitempty,min=1,max=50"`
+	RequireMFA *bool   `json:"require_mfa"`
+}
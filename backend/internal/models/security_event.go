@@ -0,0 +1,41 @@
+package models
+
+import (
+	"time"
+
+	"github.com/gofrs/uuid"
+)
+
+const (
+	SecurityEventRefreshTokenReuse   = "refresh_token_reuse"
+	SecurityEventMFAEnabled          = "mfa_enabled"
+	SecurityEventMFADisabled         = "mfa_disabled"
+	SecurityEventMFARecoveryCodeUsed = "mfa_recovery_code_used"
+	SecurityEventPasswordReset       = "password_reset"
+	SecurityEventAccountLocked       = "account_locked"
+	SecurityEventAccountUnlocked     = "account_unlocked"
+	SecurityEventNewLoginIP          = "login_new_ip"
+	SecurityEventNewLoginDevice      = "login_new_device"
+	SecurityEventPasswordChanged     = "password_changed"
+	SecurityEventEmailChanged        = "email_changed"
+	SecurityEventDeletionScheduled   = "account_deletion_scheduled"
+	SecurityEventDeletionCancelled   = "account_deletion_cancelled"
+	SecurityEventAccountCreated      = "account_created_by_admin"
+	SecurityEventAccountUpdated      = "account_updated_by_admin"
+	SecurityEventAccountDisabled     = "account_disabled"
+	SecurityEventAccountEnabled      = "account_enabled"
+	SecurityEventPasswordResetForced = "password_reset_forced"
+	SecurityEventRoleGranted         = "role_granted"
+	SecurityEventRoleRevoked         = "role_revoked"
+)
+
+// SecurityEvent is an audit record of something suspicious on an account
+type SecurityEvent struct {
+	ID        uuid.UUID  `json:"id" gorm:"primaryKey;type:uuid;default:gen_random_uuid()"`
+	UserID    *uuid.UUID `json:"user_id" gorm:"type:uuid;index"`
+	Type      string     `json:"type" gorm:"not null;index"`
+	IPAddress string     `json:"ip_address"`
+	UserAgent string     `json:"user_agent"`
+	Details   string     `json:"details" gorm:"type:text"`
+	CreatedAt time.Time  `json:"created_at" gorm:"not null;index"`
+}
@@ -1,35 +1,84 @@
 package models
 
 import (
+	"encoding/json"
 	"time"
 
 	"github.com/gofrs/uuid"
 )
 
+const (
+	TaskStatusPending    = "pending"
+	TaskStatusInProgress = "in_progress"
+	TaskStatusCompleted  = "completed"
+)
+
 type Task struct {
-	ID          uuid.UUID  `json:"id" gorm:"primaryKey;type:uuid;default:gen_random_uuid()"`
-	Title       string     `json:"title" gorm:"not null"`
-	Description string     `json:"description"`
-	Status      string     `json:"status" gorm:"default:pending"`
-	Priority    string     `json:"priority" gorm:"default:medium"`
-	UserID      uuid.UUID  `json:"user_id" gorm:"not null"`
-	CreatedAt   time.Time  `json:"created_at" gorm:"not null"`
-	UpdatedAt   time.Time  `json:"updated_at" gorm:"not null"`
-	DeletedAt   *time.Time `json:"-" gorm:"index"`
-	
+	ID                uuid.UUID  `json:"id" gorm:"primaryKey;type:uuid;default:gen_random_uuid()"`
+	Title             string     `json:"title" gorm:"not null"`
+	Description       string     `json:"description"`
+	Status            string     `json:"status" gorm:"default:pending"`
+	Priority          string     `json:"priority" gorm:"default:medium"`
+	OriginalEstimate  *float64   `json:"original_estimate"`
+	RemainingEstimate *float64   `json:"remaining_estimate"`
+	StoryPoints       *int       `json:"story_points"`
+	MilestoneID       *uuid.UUID `json:"milestone_id" gorm:"type:uuid;index"`
+	CompletedAt       *time.Time `json:"completed_at"`
+	UserID            uuid.UUID  `json:"user_id" gorm:"not null"`
+	CreatedAt         time.Time  `json:"created_at" gorm:"not null"`
+	UpdatedAt         time.Time  `json:"updated_at" gorm:"not null"`
+	DeletedAt         *time.Time `json:"-" gorm:"index"`
+
 	User User `json:"user" gorm:"foreignKey:UserID"`
 }
 
 type TaskCreateRequest struct {
-	Title       string `json:"title" binding:"required"`
-	Description string `json:"description"`
-	Status      string `json:"status"`
-	Priority    string `json:"priority"`
+	Title             string     `json:"title" binding:"required"`
+	Description       string     `json:"description"`
+	Status            string     `json:"status"`
+	Priority          string     `json:"priority"`
+	OriginalEstimate  *float64   `json:"original_estimate" binding:"omitempty,min=0"`
+	RemainingEstimate *float64   `json:"remaining_estimate" binding:"omitempty,min=0"`
+	StoryPoints       *int       `json:"story_points" binding:"omitempty,min=0"`
+	MilestoneID       *uuid.UUID `json:"milestone_id"`
 }
 
 type TaskUpdateRequest struct {
-	Title       *string `json:"title"`
-	Description *string `json:"description"`
-	Status      *string `json:"status"`
-	Priority    *string `json:"priority"`
+	Title             *string    `json:"title"`
+	Description       *string    `json:"description"`
+	Status            *string    `json:"status"`
+	Priority          *string    `json:"priority"`
+	OriginalEstimate  *float64   `json:"original_estimate" binding:"omitempty,min=0"`
+	RemainingEstimate *float64   `json:"remaining_estimate" binding:"omitempty,min=0"`
+	StoryPoints       *int       `json:"story_points" binding:"omitempty,min=0"`
+	MilestoneID       *uuid.UUID `json:"milestone_id"`
+	// MilestoneSet tells "milestone_id": null, which takes the task out of
+	// its milestone, apart from leaving the field out
+	MilestoneSet bool `json:"-"`
+}
+
+func (r *TaskUpdateRequest) UnmarshalJSON(data []byte) error {
+	type fields TaskUpdateRequest
+	if err := json.Unmarshal(data, (*fields)(r)); err != nil {
+		return err
+	}
+
+	var present map[string]json.RawMessage
+	if err := json.Unmarshal(data, &present); err != nil {
+		return err
+	}
+	_, r.MilestoneSet = present["milestone_id"]
+	return nil
+}
+
+// TaskRemainingEstimateRequest updates only the remaining work of a task
+type TaskRemainingEstimateRequest struct {
+	RemainingEstimate *float64 `json:"remaining_estimate" binding:"required,min=0"`
+}
+
+// TaskAggregates holds effort totals for a filtered task list
+type TaskAggregates struct {
+	TotalOriginalEstimate  float64 `json:"total_original_estimate"`
+	TotalRemainingEstimate float64 `json:"total_remaining_estimate"`
+	TotalStoryPoints       int64   `json:"total_story_points"`
 }
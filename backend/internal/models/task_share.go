@@ -0,0 +1,68 @@
+package models
+
+import (
+	"time"
+
+	"github.com/gofrs/uuid"
+)
+
+// TaskUserShare gives one user access to a task
+type TaskUserShare struct {
+	ID        uuid.UUID `json:"id" gorm:"primaryKey;type:uuid;default:gen_random_uuid()"`
+	TaskID    uuid.UUID `json:"task_id" gorm:"type:uuid;not null;uniqueIndex:idx_task_user_shares_task_user"`
+	UserID    uuid.UUID `json:"user_id" gorm:"type:uuid;not null;uniqueIndex:idx_task_user_shares_task_user;index"`
+	Access    string    `json:"access" gorm:"not null"`
+	SharedBy  uuid.UUID `json:"shared_by" gorm:"type:uuid"`
+	CreatedAt time.Time `json:"created_at" gorm:"not null"`
+	UpdatedAt time.Time `json:"updated_at" gorm:"not null"`
+
+	User User `json:"user" gorm:"foreignKey:UserID"`
+}
+
+// TaskPublicLink lets anyone holding the link read a task without signing
+// in. Only the hash of its token is stored; the link is shown once when it
+// is created.
+type TaskPublicLink struct {
+	ID        uuid.UUID `json:"id" gorm:"primaryKey;type:uuid;default:gen_random_uuid()"`
+	TaskID    uuid.UUID `json:"task_id" gorm:"type:uuid;not null;index"`
+	TokenHash string    `json:"-" gorm:"not null;uniqueIndex"`
+	// Hint is the start of the token, enough to recognise the link in a list
+	Hint       string     `json:"hint" gorm:"not null"`
+	ExpiresAt  *time.Time `json:"expires_at"`
+	CreatedBy  uuid.UUID  `json:"created_by" gorm:"type:uuid"`
+	LastUsedAt *time.Time `json:"last_used_at"`
+	CreatedAt  time.Time  `json:"created_at" gorm:"not null"`
+}
+
+type CreateTaskPublicLinkRequest struct {
+	// ExpiresInDays leaves the link valid until it is revoked when unset
+	ExpiresInDays int `json:"expires_in_days" binding:"omitempty,min=1,max=365"`
+}
+
+// CreatedTaskPublicLink is the only response that contains the token
+type CreatedTaskPublicLink struct {
+	TaskPublicLink
+	Token string `json:"token"`
+}
+
+// PublicTask is what a public link shows: the task without its owner,
+// estimates or any other internal detail
+type PublicTask struct {
+	Title       string     `json:"title"`
+	Description string     `json:"description"`
+	Status      string     `json:"status"`
+	Priority    string     `json:"priority"`
+	CompletedAt *time.Time `json:"completed_at"`
+	UpdatedAt   time.Time  `json:"updated_at"`
+}
+
+func NewPublicTask(task Task) PublicTask {
+	return PublicTask{
+		Title:       task.Title,
+		Description: task.Description,
+		Status:      task.Status,
+		Priority:    task.Priority,
+		CompletedAt: task.CompletedAt,
+		UpdatedAt:   task.UpdatedAt,
+	}
+}
@@ -0,0 +1,14 @@
+package models
+
+import (
+	"time"
+
+	"github.com/gofrs/uuid"
+)
+
+type TaskWatcher struct {
+	ID        uuid.UUID `json:"id" gorm:"primaryKey;type:uuid;default:gen_random_uuid()"`
+	TaskID    uuid.UUID `json:"task_id" gorm:"not null;uniqueIndex:idx_task_watchers_task_user"`
+	UserID    uuid.UUID `json:"user_id" gorm:"not null;uniqueIndex:idx_task_watchers_task_user"`
+	CreatedAt time.Time `json:"created_at" gorm:"not null"`
+}
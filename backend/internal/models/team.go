@@ -0,0 +1,72 @@
+package models
+
+import (
+	"time"
+
+	"github.com/gofrs/uuid"
+)
+
+// Team groups users so roles and tasks can be given to all of them at once
+type Team struct {
+	ID          uuid.UUID `json:"id" gorm:"primaryKey;type:uuid;default:gen_random_uuid()"`
+	Name        string    `json:"name" gorm:"unique;not null"`
+	Description string    `json:"description"`
+	CreatedBy   uuid.UUID `json:"created_by" gorm:"type:uuid"`
+	CreatedAt   time.Time `json:"created_at" gorm:"not null"`
+	UpdatedAt   time.Time `json:"updated_at" gorm:"not null"`
+}
+
+type TeamMember struct {
+	ID        uuid.UUID `json:"id" gorm:"primaryKey;type:uuid;default:gen_random_uuid()"`
+	TeamID    uuid.UUID `json:"team_id" gorm:"type:uuid;not null;uniqueIndex:idx_team_members_team_user"`
+	UserID    uuid.UUID `json:"user_id" gorm:"type:uuid;not null;uniqueIndex:idx_team_members_team_user;index"`
+	CreatedAt time.Time `json:"created_at" gorm:"not null"`
+
+	User User `json:"user" gorm:"foreignKey:UserID"`
+}
+
+// TeamRole is a role every member of the team holds
+type TeamRole struct {
+	ID        uuid.UUID `json:"id" gorm:"primaryKey;type:uuid;default:gen_random_uuid()"`
+	TeamID    uuid.UUID `json:"team_id" gorm:"type:uuid;not null;uniqueIndex:idx_team_roles_team_role"`
+	RoleID    uuid.UUID `json:"role_id" gorm:"type:uuid;not null;uniqueIndex:idx_team_roles_team_role;index"`
+	CreatedAt time.Time `json:"created_at" gorm:"not null"`
+}
+
+// Access levels a task can be shared with
+const (
+	TaskShareAccessRead  = "read"
+	TaskShareAccessWrite = "write"
+)
+
+// TaskTeamShare gives every member of a team access to a task
+type TaskTeamShare struct {
+	ID        uuid.UUID `json:"id" gorm:"primaryKey;type:uuid;default:gen_random_uuid()"`
+	TaskID    uuid.UUID `json:"task_id" gorm:"type:uuid;not null;uniqueIndex:idx_task_team_shares_task_team"`
+	TeamID    uuid.UUID `json:"team_id" gorm:"type:uuid;not null;uniqueIndex:idx_task_team_shares_task_team;index"`
+	Access    string    `json:"access" gorm:"not null"`
+	SharedBy  uuid.UUID `json:"shared_by" gorm:"type:uuid"`
+	CreatedAt time.Time `json:"created_at" gorm:"not null"`
+	UpdatedAt time.Time `json:"updated_at" gorm:"not null"`
+
+	Team Team `json:"team" gorm:"foreignKey:TeamID"`
+}
+
+type CreateTeamRequest struct {
+	Name        string `json:"name" binding:"required,max=100"`
+	Description string `json:"description"`
+}
+
+// UpdateTeamRequest changes only the fields that are set
+type UpdateTeamRequest struct {
+	Name        *string `json:"name" binding:"omitempty,min=1,max=100"`
+	Description *string `json:"description"`
+}
+
+type AddTeamMemberRequest struct {
+	UserID uuid.UUID `json:"user_id" binding:"required"`
+}
+
+type ShareTaskRequest struct {
+	Access string `json:"access" binding:"required,oneof=read write"`
+}
@@ -6,14 +6,36 @@ import (
 	"github.com/gofrs/uuid"
 )
 
+// Token is one refresh token. Every refresh creates a child token in the
+// same family and marks its parent used; a family is one session.
 type Token struct {
-	ID           uuid.UUID `json:"id" gorm:"primaryKey;type:uuid;default:gen_random_uuid()"`
-	UserID       uuid.UUID `json:"user_id" gorm:"not null"`
-	RefreshToken uuid.UUID `json:"refresh_token" gorm:"not null"`
-	ExpiresAt    time.Time `json:"expires_at" gorm:"not null"`
-	CreatedAt    time.Time `json:"created_at" gorm:"not null"`
-	UpdatedAt    time.Time `json:"updated_at" gorm:"not null"`
-	DeletedAt    *time.Time `json:"-" gorm:"index"`
+	ID         uuid.UUID  `json:"id" gorm:"primaryKey;type:uuid;default:gen_random_uuid()"`
+	UserID     uuid.UUID  `json:"user_id" gorm:"not null"`
+	FamilyID   uuid.UUID  `json:"family_id" gorm:"type:uuid;not null;index"`
+	ParentID   *uuid.UUID `json:"parent_id" gorm:"type:uuid"`
+	TokenHash  string     `json:"-" gorm:"not null;uniqueIndex"`
+	ExpiresAt  time.Time  `json:"expires_at" gorm:"not null"`
+	UsedAt     *time.Time `json:"used_at"`
+	Device     string     `json:"device"`
+	UserAgent  string     `json:"user_agent"`
+	IPAddress  string     `json:"ip_address"`
+	LastUsedAt *time.Time `json:"last_used_at"`
+	CreatedAt  time.Time  `json:"created_at" gorm:"not null"`
+	UpdatedAt  time.Time  `json:"updated_at" gorm:"not null"`
+	DeletedAt  *time.Time `json:"-" gorm:"index"`
 	
 	User User `json:"user" gorm:"foreignKey:UserID"`
 }
+
+// Session is the client facing view of a token family; it never exposes
+// the refresh token itself
+type Session struct {
+	ID         uuid.UUID  `json:"id"`
+	Device     string     `json:"device"`
+	UserAgent  string     `json:"user_agent"`
+	IPAddress  string     `json:"ip_address"`
+	Current    bool       `json:"current"`
+	CreatedAt  time.Time  `json:"created_at"`
+	LastUsedAt *time.Time `json:"last_used_at"`
+	ExpiresAt  time.Time  `json:"expires_at"`
+}
@@ -0,0 +1,25 @@
+package models
+
+import (
+	"time"
+
+	"github.com/gofrs/uuid"
+)
+
+const (
+	RevocationKindToken   = "token"
+	RevocationKindSession = "session"
+	RevocationKindUser    = "user"
+)
+
+// TokenRevocation rejects access tokens before they expire. Token entries
+// match one jti; session and user entries match every token of that session
+// or user issued before RevokedAt.
+type TokenRevocation struct {
+	ID        uuid.UUID `json:"id" gorm:"primaryKey;type:uuid;default:gen_random_uuid()"`
+	Kind      string    `json:"kind" gorm:"not null"`
+	Subject   string    `json:"subject" gorm:"not null;index"`
+	RevokedAt time.Time `json:"revoked_at" gorm:"not null"`
+	ExpiresAt time.Time `json:"expires_at" gorm:"not null;index"`
+	CreatedAt time.Time `json:"created_at" gorm:"not null;index"`
+}
@@ -7,11 +7,61 @@ import (
 )
 
 type User struct {
-	ID        uuid.UUID  `json:"id" gorm:"primaryKey;type:uuid;default:gen_random_uuid()"`
-	Username  string     `json:"username" gorm:"unique;not null"`
-	Email     string     `json:"email" gorm:"unique;not null"`
-	Password  string     `json:"-" gorm:"not null"`
-	CreatedAt time.Time  `json:"created_at" gorm:"not null"`
-	UpdatedAt time.Time  `json:"updated_at" gorm:"not null"`
-	DeletedAt *time.Time `json:"-" gorm:"index"`
+	ID                  uuid.UUID  `json:"id" gorm:"primaryKey;type:uuid;default:gen_random_uuid()"`
+	Username            string     `json:"username" gorm:"unique;not null"`
+	Email               string     `json:"email" gorm:"unique;not null"`
+	DisplayName         string     `json:"display_name"`
+	Password            string     `json:"-" gorm:"not null"`
+	EmailVerifiedAt     *time.Time `json:"email_verified_at"`
+	FailedLoginAttempts int        `json:"-" gorm:"not null;default:0"`
+	LockedUntil         *time.Time `json:"locked_until,omitempty"`
+	// DeletionScheduledAt is when a deletion the user asked for takes effect
+	DeletionScheduledAt *time.Time `json:"deletion_scheduled_at,omitempty"`
+	// DisabledAt is set while an admin has disabled the account
+	DisabledAt *time.Time `json:"disabled_at,omitempty"`
+	// PasswordResetRequired refuses password logins until the user has
+	// reset their password
+	PasswordResetRequired bool       `json:"password_reset_required" gorm:"not null;default:false"`
+	CreatedAt             time.Time  `json:"created_at" gorm:"not null"`
+	UpdatedAt             time.Time  `json:"updated_at" gorm:"not null"`
+	DeletedAt             *time.Time `json:"-" gorm:"index"`
+}
+
+// UpdateProfileRequest changes only the fields that are set
+type UpdateProfileRequest struct {
+	Username    *string `json:"username" binding:"omitempty,min=1,max=50"`
+	Email       *string `json:"email" binding:"omitempty,email"`
+	DisplayName *string `json:"display_name" binding:"omitempty,max=100"`
+}
+
+type ChangePasswordRequest struct {
+	CurrentPassword string `json:"current_password" binding:"required"`
+	NewPassword     string `json:"new_password" binding:"required"`
+}
+
+type DeleteAccountRequest struct {
+	Password string `json:"password"`
+}
+
+// AdminCreateUserRequest creates an account on behalf of someone. Without a
+// password the user is sent a link to choose one.
+type AdminCreateUserRequest struct {
+	Username      string      `json:"username" binding:"required,min=1,max=50"`
+	Email         string      `json:"email" binding:"required,email"`
+	DisplayName   string      `json:"display_name" binding:"max=100"`
+	Password      string      `json:"password"`
+	EmailVerified bool        `json:"email_verified"`
+	RoleIDs       []uuid.UUID `json:"role_ids"`
+}
+
+// AdminUpdateUserRequest changes only the fields that are set
+type AdminUpdateUserRequest struct {
+	Username      *string `json:"username" binding:"omitempty,min=1,max=50"`
+	Email         *string `json:"email" binding:"omitempty,email"`
+	DisplayName   *string `json:"display_name" binding:"omitempty,max=100"`
+	EmailVerified *bool   `json:"email_verified"`
+}
+
+type GrantRoleRequest struct {
+	RoleID uuid.UUID `json:"role_id" binding:"required"`
 }
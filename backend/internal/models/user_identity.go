@@ -0,0 +1,18 @@
+package models
+
+import (
+	"time"
+
+	"github.com/gofrs/uuid"
+)
+
+// UserIdentity links a user to an account at an external identity provider
+type UserIdentity struct {
+	ID          uuid.UUID `json:"id" gorm:"primaryKey;type:uuid;default:gen_random_uuid()"`
+	UserID      uuid.UUID `json:"user_id" gorm:"type:uuid;not null;index"`
+	Issuer      string    `json:"issuer" gorm:"not null;uniqueIndex:idx_user_identities_issuer_subject"`
+	Subject     string    `json:"subject" gorm:"not null;uniqueIndex:idx_user_identities_issuer_subject"`
+	Email       string    `json:"email"`
+	LastLoginAt time.Time `json:"last_login_at" gorm:"not null"`
+	CreatedAt   time.Time `json:"created_at" gorm:"not null"`
+}
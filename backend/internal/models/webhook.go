@@ -0,0 +1,61 @@
+package models
+
+import (
+	"time"
+
+	"github.com/gofrs/uuid"
+)
+
+const (
+	WebhookEventTaskCreated = "task.created"
+	WebhookEventTaskUpdated = "task.updated"
+	WebhookEventTaskDeleted = "task.deleted"
+	WebhookEventTest        = "webhook.test"
+)
+
+const (
+	WebhookDeliveryPending   = "pending"
+	WebhookDeliveryDelivered = "delivered"
+	WebhookDeliveryFailed    = "failed"
+)
+
+type Webhook struct {
+	ID         uuid.UUID  `json:"id" gorm:"primaryKey;type:uuid;default:gen_random_uuid()"`
+	URL        string     `json:"url" gorm:"not null"`
+	Secret     string     `json:"-" gorm:"not null"`
+	EventTypes []string   `json:"event_types" gorm:"type:text;serializer:json;not null"`
+	Active     bool       `json:"active" gorm:"not null;default:true"`
+	CreatedBy  uuid.UUID  `json:"created_by" gorm:"type:uuid"`
+	CreatedAt  time.Time  `json:"created_at" gorm:"not null"`
+	UpdatedAt  time.Time  `json:"updated_at" gorm:"not null"`
+	DeletedAt  *time.Time `json:"-" gorm:"index"`
+}
+
+// WebhookDelivery is one attempt log entry per event sent to a webhook
+type WebhookDelivery struct {
+	ID             uuid.UUID  `json:"id" gorm:"primaryKey;type:uuid;default:gen_random_uuid()"`
+	WebhookID      uuid.UUID  `json:"webhook_id" gorm:"not null;index"`
+	EventType      string     `json:"event_type" gorm:"not null"`
+	Payload        string     `json:"payload" gorm:"type:text;not null"`
+	Status         string     `json:"status" gorm:"not null;default:pending;index"`
+	Attempts       int        `json:"attempts" gorm:"not null;default:0"`
+	ResponseStatus int        `json:"response_status"`
+	LastError      string     `json:"last_error"`
+	NextAttemptAt  time.Time  `json:"next_attempt_at" gorm:"not null;index"`
+	DeliveredAt    *time.Time `json:"delivered_at"`
+	CreatedAt      time.Time  `json:"created_at" gorm:"not null"`
+	UpdatedAt      time.Time  `json:"updated_at" gorm:"not null"`
+}
+
+type WebhookCreateRequest struct {
+	URL        string   `json:"url" binding:"required,url"`
+	Secret     string   `json:"secret"`
+	EventTypes []string `json:"event_types" binding:"required,min=1,dive,oneof=task.created task.updated task.deleted"`
+}
+
+type WebhookUpdateRequest struct {
+	URL        *string  `json:"url" binding:"omitempty,url"`
+	Secret     *string  `json:"secret"`
+	EventTypes []string `json:"event_types" binding:"omitempty,min=1,dive,oneof=task.created task.updated task.deleted"`
+	Active     *bool    `json:"active"`
+}
@@ -0,0 +1,255 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+	"task-manager/backend/internal/models"
+	"task-manager/backend/internal/utils"
+	"time"
+
+	"github.com/gofrs/uuid"
+	"gorm.io/gorm"
+)
+
+const (
+	emailVerificationLifetime = 24 * time.Hour
+	passwordResetLifetime     = time.Hour
+)
+
+// AccountService sends and redeems the email verification and password
+// reset links
+type AccountService interface {
+	SendVerificationEmail(db *gorm.DB, userID uuid.UUID) error
+	VerifyEmail(db *gorm.DB, token string) error
+	RequestPasswordReset(db *gorm.DB, email string) error
+	ResetPassword(db *gorm.DB, token, newPassword string, client ClientInfo) error
+}
+
+type AccountServiceImpl struct {
+	appURL       string
+	emailService EmailService
+	revocations  *RevocationStore
+	policy       *PasswordPolicy
+}
+
+func NewAccountService(emailService EmailService) *AccountServiceImpl {
+	return &AccountServiceImpl{
+		appURL:       utils.GetEnv("APP_URL", "http://localhost:3000"),
+		emailService: emailService,
+	}
+}
+
+// UseRevocationStore makes password resets end the user's signed in sessions
+// right away instead of when their access tokens expire
+func (s *AccountServiceImpl) UseRevocationStore(revocations *RevocationStore) {
+	s.revocations = revocations
+}
+
+// UsePasswordPolicy rejects new passwords that break the policy. The reset
+// link stays valid so the user can try another password.
+func (s *AccountServiceImpl) UsePasswordPolicy(policy *PasswordPolicy) {
+	s.policy = policy
+}
+
+func (s *AccountServiceImpl) SendVerificationEmail(db *gorm.DB, userID uuid.UUID) error {
+	var user models.User
+	if err := db.First(&user, "id = ?", userID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return errors.New("user not found")
+		}
+		return err
+	}
+	if user.EmailVerifiedAt != nil {
+		return errors.New("email already verified")
+	}
+
+	return s.sendToken(db, user, models.AccountTokenEmailVerification, emailVerificationLifetime)
+}
+
+func (s *AccountServiceImpl) VerifyEmail(db *gorm.DB, token string) error {
+	return db.Transaction(func(tx *gorm.DB) error {
+		accountToken, err := redeemAccountToken(tx, token, models.AccountTokenEmailVerification)
+		if err != nil {
+			return err
+		}
+
+		// A link sent to an address the user has since changed proves nothing
+		result := tx.Model(&models.User{}).
+			Where("id = ? AND email = ?", accountToken.UserID, accountToken.Email).
+			Update("email_verified_at", time.Now())
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return errors.New("invalid or expired token")
+		}
+		return nil
+	})
+}
+
+// RequestPasswordReset emails a reset link if the address belongs to an
+// account. Unknown addresses are not an error, so callers cannot use this
+// to find out who has an account.
+func (s *AccountServiceImpl) RequestPasswordReset(db *gorm.DB, email string) error {
+	var user models.User
+	result := db.Where("LOWER(email) = LOWER(?)", strings.TrimSpace(email)).Limit(1).Find(&user)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return nil
+	}
+
+	return s.sendToken(db, user, models.AccountTokenPasswordReset, passwordResetLifetime)
+}
+
+// ResetPassword sets a new password and signs the user out everywhere, since
+// whoever knew the old password may still hold a session
+func (s *AccountServiceImpl) ResetPassword(db *gorm.DB, token, newPassword string, client ClientInfo) error {
+	var userID uuid.UUID
+	err := db.Transaction(func(tx *gorm.DB) error {
+		accountToken, err := redeemAccountToken(tx, token, models.AccountTokenPasswordReset)
+		if err != nil {
+			return err
+		}
+		userID = accountToken.UserID
+
+		var user models.User
+		if err := tx.First(&user, "id = ?", userID).Error; err != nil {
+			return err
+		}
+		if s.policy != nil {
+			if err := s.policy.Validate(tx, newPassword, user); err != nil {
+				return err
+			}
+		}
+
+		hashedPassword, err := HashPassword(newPassword)
+		if err != nil {
+			return err
+		}
+		updates := map[string]interface{}{"password": hashedPassword, "password_reset_required": false}
+		// Following the link proves the user reads this mailbox
+		if user.EmailVerifiedAt == nil && user.Email == accountToken.Email {
+			updates["email_verified_at"] = time.Now()
+		}
+		if err := tx.Model(&user).Updates(updates).Error; err != nil {
+			return err
+		}
+		if s.policy != nil {
+			if err := s.policy.Remember(tx, userID, hashedPassword); err != nil {
+				return err
+			}
+		}
+
+		// Other reset links for the account stop working too
+		if err := tx.Model(&models.AccountToken{}).
+			Where("user_id = ? AND purpose = ? AND used_at IS NULL", userID, models.AccountTokenPasswordReset).
+			Update("used_at", time.Now()).Error; err != nil {
+			return err
+		}
+
+		if err := tx.Where("user_id = ?", userID).Delete(&models.Token{}).Error; err != nil {
+			return err
+		}
+
+		return RecordSecurityEvent(tx, models.SecurityEvent{
+			UserID:    &userID,
+			Type:      models.SecurityEventPasswordReset,
+			IPAddress: client.IPAddress,
+			UserAgent: client.UserAgent,
+		})
+	})
+	if err != nil {
+		return err
+	}
+
+	if s.revocations != nil {
+		return s.revocations.RevokeUser(db, userID)
+	}
+	return nil
+}
+
+// sendToken stores a new token, replacing older unused ones for the same
+// purpose, and queues the email with its link
+func (s *AccountServiceImpl) sendToken(db *gorm.DB, user models.User, purpose string, lifetime time.Duration) error {
+	token, err := generateRefreshToken()
+	if err != nil {
+		return err
+	}
+
+	path, subject := "/verify-email", "Confirm your email address"
+	text, html := verificationText, verificationHTML
+	if purpose == models.AccountTokenPasswordReset {
+		path, subject = "/reset-password", "Reset your Taskify password"
+		text, html = passwordResetText, passwordResetHTML
+	}
+
+	textBody, htmlBody, err := renderTemplates(text, html, accountEmailData{
+		Username:  user.Username,
+		Link:      s.appURL + path + "?token=" + url.QueryEscape(token),
+		ExpiresIn: describeLifetime(lifetime),
+	})
+	if err != nil {
+		return err
+	}
+
+	return db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("user_id = ? AND purpose = ? AND used_at IS NULL", user.ID, purpose).Delete(&models.AccountToken{}).Error; err != nil {
+			return err
+		}
+
+		accountToken := models.AccountToken{
+			ID:        uuid.Must(uuid.NewV4()),
+			UserID:    user.ID,
+			Purpose:   purpose,
+			TokenHash: HashRefreshToken(token),
+			Email:     user.Email,
+			ExpiresAt: time.Now().Add(lifetime),
+		}
+		if err := tx.Create(&accountToken).Error; err != nil {
+			return err
+		}
+
+		userID := user.ID
+		return s.emailService.EnqueueEmail(tx, &userID, MailMessage{
+			To:       user.Email,
+			Subject:  subject,
+			TextBody: textBody,
+			HTMLBody: htmlBody,
+		})
+	})
+}
+
+// redeemAccountToken marks a valid token used. The conditional update makes
+// sure two requests racing with the same link cannot both succeed.
+func redeemAccountToken(tx *gorm.DB, token, purpose string) (*models.AccountToken, error) {
+	var accountToken models.AccountToken
+	result := tx.Where("token_hash = ? AND purpose = ?", HashRefreshToken(token), purpose).Limit(1).Find(&accountToken)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	if result.RowsAffected == 0 || accountToken.UsedAt != nil || !accountToken.ExpiresAt.After(time.Now()) {
+		return nil, errors.New("invalid or expired token")
+	}
+
+	result = tx.Model(&models.AccountToken{}).
+		Where("id = ? AND used_at IS NULL", accountToken.ID).
+		Update("used_at", time.Now())
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	if result.RowsAffected == 0 {
+		return nil, errors.New("invalid or expired token")
+	}
+	return &accountToken, nil
+}
+
+func describeLifetime(lifetime time.Duration) string {
+	if hours := int(lifetime / time.Hour); hours > 1 {
+		return fmt.Sprintf("%d hours", hours)
+	}
+	return "1 hour"
+}
@@ -1,7 +1,12 @@
 package services
 
 import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
 	"errors"
+	"fmt"
 	"task-manager/backend/internal/models"
 	"task-manager/backend/internal/utils"
 	"time"
@@ -12,79 +17,287 @@ import (
 )
 
 type AuthService interface {
-	LoginUser(db *gorm.DB, username, password string) (*models.User, error)
-	GenerateToken(db *gorm.DB, userID uuid.UUID) (string, string, error)
-	ValidateRefreshToken(db *gorm.DB, refreshToken uuid.UUID) (*models.Token, error)
-	InvalidateRefreshToken(db *gorm.DB, refreshToken uuid.UUID) error
+	LoginUser(db *gorm.DB, username, password string, client ClientInfo) (*models.User, error)
+	GenerateToken(db *gorm.DB, userID uuid.UUID, client ClientInfo) (string, string, error)
+	ValidateRefreshToken(db *gorm.DB, refreshToken string) (*models.Token, error)
+	RefreshSession(db *gorm.DB, refreshToken string, client ClientInfo) (string, string, error)
+	InvalidateRefreshToken(db *gorm.DB, refreshToken string) error
+	Logout(db *gorm.DB, userID uuid.UUID, sessionID uuid.UUID, tokenID string, tokenExpiresAt time.Time) error
+	GetSessions(db *gorm.DB, userID uuid.UUID, currentSessionID uuid.UUID) ([]models.Session, error)
+	RevokeSession(db *gorm.DB, userID uuid.UUID, sessionID uuid.UUID) error
+	RevokeAllSessions(db *gorm.DB, userID uuid.UUID) (int64, error)
+	PurgeExpiredTokens(db *gorm.DB, now time.Time) (int64, error)
 	GetUserRolesAndPermissions(db *gorm.DB, userID uuid.UUID) ([]string, bool, []utils.Permission, error)
 }
 
-type AuthServiceImpl struct{}
+// Refresh tokens expire after this long without being used
+const refreshTokenLifetime = time.Hour
+
+type AuthServiceImpl struct {
+	revocations          *RevocationStore
+	loginGuard           LoginGuardService
+	requireVerifiedEmail bool
+	compactTokens        bool
+}
+
+// Compared against when the username matches no account, so that takes as
+// long as a wrong password
+var dummyPasswordHash, _ = HashPassword("no account has this password")
 
 func NewAuthService() *AuthServiceImpl {
 	return &AuthServiceImpl{}
 }
 
-func (s *AuthServiceImpl) LoginUser(db *gorm.DB, username, password string) (*models.User, error) {
+// UseRevocationStore makes logouts and revoked sessions reject access
+// tokens that have not expired yet
+func (s *AuthServiceImpl) UseRevocationStore(revocations *RevocationStore) {
+	s.revocations = revocations
+}
+
+// UseLoginGuard throttles and locks out repeated failed logins per account
+// and records every login attempt
+func (s *AuthServiceImpl) UseLoginGuard(loginGuard LoginGuardService) {
+	s.loginGuard = loginGuard
+}
+
+// RequireEmailVerification refuses password logins until the user has
+// confirmed their email address
+func (s *AuthServiceImpl) RequireEmailVerification() {
+	s.requireVerifiedEmail = true
+}
+
+// UseCompactTokens issues access tokens carrying only the user and role IDs.
+// The permissions are then resolved by AuthMiddleware for each request.
+func (s *AuthServiceImpl) UseCompactTokens() {
+	s.compactTokens = true
+}
+
+func (s *AuthServiceImpl) LoginUser(db *gorm.DB, username, password string, client ClientInfo) (*models.User, error) {
 	var user models.User
 	
 	result := db.Where("username = ? OR email = ?", username, username).First(&user)
 	if result.Error != nil {
 		if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+			VerifyPassword(dummyPasswordHash, password)
+			if s.loginGuard != nil {
+				if err := s.loginGuard.RecordFailure(db, nil, username, client); err != nil {
+					return nil, err
+				}
+			}
 			return nil, errors.New("invalid credentials")
 		}
 		return nil, result.Error
 	}
 
+	// A blocked account refuses even the right password, and says nothing
+	// different, so guessing on while blocked is pointless
+	if s.loginGuard != nil && s.loginGuard.Blocked(&user, time.Now()) {
+		if err := s.loginGuard.RecordFailure(db, &user, username, client); err != nil {
+			return nil, err
+		}
+		return nil, errors.New("invalid credentials")
+	}
+
 	if !VerifyPassword(user.Password, password) {
+		if s.loginGuard != nil {
+			if err := s.loginGuard.RecordFailure(db, &user, username, client); err != nil {
+				return nil, err
+			}
+		}
 		return nil, errors.New("invalid credentials")
 	}
 
+	// Only said once the password is right, so it tells a guesser nothing
+	if user.DisabledAt != nil {
+		return nil, errors.New("account disabled")
+	}
+	if user.PasswordResetRequired {
+		return nil, errors.New("password reset required")
+	}
+
+	if s.requireVerifiedEmail && user.EmailVerifiedAt == nil {
+		return nil, errors.New("email not verified")
+	}
+
+	if s.loginGuard != nil {
+		if err := s.loginGuard.RecordSuccess(db, &user, client); err != nil {
+			return nil, err
+		}
+	}
+
 	return &user, nil
 }
 
-func (s *AuthServiceImpl) GenerateToken(db *gorm.DB, userID uuid.UUID) (string, string, error) {
-	// Get user roles and permissions
-	roles, isAdmin, permissions, err := s.GetUserRolesAndPermissions(db, userID)
+// GenerateToken starts a new session (token family) for the client and
+// returns its access and refresh tokens
+func (s *AuthServiceImpl) GenerateToken(db *gorm.DB, userID uuid.UUID, client ClientInfo) (string, string, error) {
+	if err := ensureAccountEnabled(db, userID); err != nil {
+		return "", "", err
+	}
+
+	// Generate refresh token
+	refreshToken, err := generateRefreshToken()
 	if err != nil {
 		return "", "", err
 	}
+	now := time.Now()
 
-	// Get user details for JWT
-	var user models.User
-	if err := db.First(&user, userID).Error; err != nil {
+	// Store only the hash of the refresh token
+	tokenID := uuid.Must(uuid.NewV4())
+	token := models.Token{
+		ID:         tokenID,
+		UserID:     userID,
+		FamilyID:   tokenID,
+		TokenHash:  HashRefreshToken(refreshToken),
+		ExpiresAt:  now.Add(refreshTokenLifetime),
+		Device:     DescribeDevice(client.UserAgent),
+		UserAgent:  client.UserAgent,
+		IPAddress:  client.IPAddress,
+		LastUsedAt: &now,
+	}
+
+	if err := db.Create(&token).Error; err != nil {
 		return "", "", err
 	}
 
-	// Generate access token
-	accessToken, err := utils.GenerateJWT(userID, user.Username, roles, isAdmin, permissions)
+	accessToken, err := s.generateAccessToken(db, userID, token.FamilyID)
 	if err != nil {
 		return "", "", err
 	}
 
-	// Generate refresh token
-	refreshToken := uuid.Must(uuid.NewV4())
-	expiresAt := time.Now().Add(time.Hour)
+	return accessToken, refreshToken, nil
+}
 
-	// Store refresh token in database
-	token := models.Token{
-		ID:           uuid.Must(uuid.NewV4()),
-		UserID:       userID,
-		RefreshToken: refreshToken,
-		ExpiresAt:    expiresAt,
+// RefreshSession exchanges a refresh token for a new pair. The presented
+// token is marked used and a child token joins its family. Presenting a
+// used token again means it was stolen, so the whole family is revoked.
+func (s *AuthServiceImpl) RefreshSession(db *gorm.DB, refreshToken string, client ClientInfo) (string, string, error) {
+	var token models.Token
+	result := db.Where("token_hash = ?", HashRefreshToken(refreshToken)).Limit(1).Find(&token)
+	if result.Error != nil {
+		return "", "", result.Error
+	}
+	if result.RowsAffected == 0 {
+		return "", "", errors.New("invalid or expired refresh token")
 	}
 
-	if err := db.Create(&token).Error; err != nil {
+	if token.UsedAt != nil {
+		return "", "", s.revokeReusedFamily(db, token, client)
+	}
+
+	now := time.Now()
+	if !token.ExpiresAt.After(now) {
+		return "", "", errors.New("invalid or expired refresh token")
+	}
+	if err := ensureAccountEnabled(db, token.UserID); err != nil {
+		return "", "", err
+	}
+
+	newRefreshToken, err := generateRefreshToken()
+	if err != nil {
+		return "", "", err
+	}
+
+	parentID := token.ID
+	child := models.Token{
+		ID:         uuid.Must(uuid.NewV4()),
+		UserID:     token.UserID,
+		FamilyID:   token.FamilyID,
+		ParentID:   &parentID,
+		TokenHash:  HashRefreshToken(newRefreshToken),
+		ExpiresAt:  now.Add(refreshTokenLifetime),
+		Device:     DescribeDevice(client.UserAgent),
+		UserAgent:  client.UserAgent,
+		IPAddress:  client.IPAddress,
+		LastUsedAt: &now,
+	}
+
+	reused := false
+	err = db.Transaction(func(tx *gorm.DB) error {
+		// Only one of two concurrent refreshes with the same token can win
+		result := tx.Model(&models.Token{}).Where("id = ? AND used_at IS NULL", token.ID).Update("used_at", now)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			reused = true
+			return nil
+		}
+
+		return tx.Create(&child).Error
+	})
+	if err != nil {
+		return "", "", err
+	}
+	if reused {
+		return "", "", s.revokeReusedFamily(db, token, client)
+	}
+
+	accessToken, err := s.generateAccessToken(db, token.UserID, token.FamilyID)
+	if err != nil {
 		return "", "", err
 	}
 
-	return accessToken, refreshToken.String(), nil
+	return accessToken, newRefreshToken, nil
+}
+
+// revokeReusedFamily ends the session a replayed refresh token belongs to
+// and records the incident
+func (s *AuthServiceImpl) revokeReusedFamily(db *gorm.DB, token models.Token, client ClientInfo) error {
+	if err := db.Where("family_id = ?", token.FamilyID).Delete(&models.Token{}).Error; err != nil {
+		return err
+	}
+
+	if s.revocations != nil {
+		if err := s.revocations.RevokeSession(db, token.FamilyID); err != nil {
+			return err
+		}
+	}
+
+	userID := token.UserID
+	err := RecordSecurityEvent(db, models.SecurityEvent{
+		UserID:    &userID,
+		Type:      models.SecurityEventRefreshTokenReuse,
+		IPAddress: client.IPAddress,
+		UserAgent: client.UserAgent,
+		Details:   fmt.Sprintf("refresh token %s of session %s was presented after rotation; session revoked", token.ID, token.FamilyID),
+	})
+	if err != nil {
+		return err
+	}
+
+	return errors.New("refresh token reuse detected")
+}
+
+func (s *AuthServiceImpl) generateAccessToken(db *gorm.DB, userID uuid.UUID, sessionID uuid.UUID) (string, error) {
+	if s.compactTokens {
+		roleIDs, err := userRoleIDs(db, userID)
+		if err != nil {
+			return "", err
+		}
+		return utils.GenerateCompactJWT(userID, roleIDs, sessionID)
+	}
+
+	// Get user roles and permissions
+	roles, isAdmin, permissions, err := s.GetUserRolesAndPermissions(db, userID)
+	if err != nil {
+		return "", err
+	}
+
+	// Get user details for JWT
+	var user models.User
+	if err := db.First(&user, userID).Error; err != nil {
+		return "", err
+	}
+
+	return utils.GenerateJWT(userID, user.Username, roles, isAdmin, permissions, sessionID)
 }
 
-func (s *AuthServiceImpl) ValidateRefreshToken(db *gorm.DB, refreshToken uuid.UUID) (*models.Token, error) {
+// ValidateRefreshToken returns the token if it can still be exchanged
+func (s *AuthServiceImpl) ValidateRefreshToken(db *gorm.DB, refreshToken string) (*models.Token, error) {
 	var token models.Token
 	
-	result := db.Where("refresh_token = ? AND expires_at > ?", refreshToken, time.Now()).First(&token)
+	result := db.Where("token_hash = ? AND used_at IS NULL AND expires_at > ?", HashRefreshToken(refreshToken), time.Now()).First(&token)
 	if result.Error != nil {
 		if errors.Is(result.Error, gorm.ErrRecordNotFound) {
 			return nil, errors.New("invalid or expired refresh token")
@@ -95,32 +308,134 @@ func (s *AuthServiceImpl) ValidateRefreshToken(db *gorm.DB, refreshToken uuid.UU
 	return &token, nil
 }
 
-func (s *AuthServiceImpl) InvalidateRefreshToken(db *gorm.DB, refreshToken uuid.UUID) error {
-	return db.Where("refresh_token = ?", refreshToken).Delete(&models.Token{}).Error
+func (s *AuthServiceImpl) InvalidateRefreshToken(db *gorm.DB, refreshToken string) error {
+	return db.Where("token_hash = ?", HashRefreshToken(refreshToken)).Delete(&models.Token{}).Error
+}
+
+// Logout ends the session the access token belongs to and rejects the
+// token itself for the rest of its lifetime
+func (s *AuthServiceImpl) Logout(db *gorm.DB, userID uuid.UUID, sessionID uuid.UUID, tokenID string, tokenExpiresAt time.Time) error {
+	if err := db.Where("family_id = ? AND user_id = ?", sessionID, userID).Delete(&models.Token{}).Error; err != nil {
+		return err
+	}
+
+	if s.revocations == nil {
+		return nil
+	}
+	if err := s.revocations.RevokeToken(db, tokenID, tokenExpiresAt); err != nil {
+		return err
+	}
+	return s.revocations.RevokeSession(db, sessionID)
+}
+
+// GetSessions lists the user's active sessions, most recently used first
+func (s *AuthServiceImpl) GetSessions(db *gorm.DB, userID uuid.UUID, currentSessionID uuid.UUID) ([]models.Session, error) {
+	var tokens []models.Token
+
+	// The unused token of each family stands for the session
+	result := db.Where("user_id = ? AND used_at IS NULL AND expires_at > ?", userID, time.Now()).Order("last_used_at desc").Find(&tokens)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+
+	// A session started when the first token of its family was issued
+	var history []models.Token
+	result = db.Select("family_id", "created_at").Where("user_id = ?", userID).Order("created_at asc").Find(&history)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	startedAt := make(map[uuid.UUID]time.Time)
+	for _, token := range history {
+		if _, ok := startedAt[token.FamilyID]; !ok {
+			startedAt[token.FamilyID] = token.CreatedAt
+		}
+	}
+
+	sessions := make([]models.Session, 0, len(tokens))
+	for _, token := range tokens {
+		createdAt, ok := startedAt[token.FamilyID]
+		if !ok {
+			createdAt = token.CreatedAt
+		}
+
+		sessions = append(sessions, models.Session{
+			ID:         token.FamilyID,
+			Device:     token.Device,
+			UserAgent:  token.UserAgent,
+			IPAddress:  token.IPAddress,
+			Current:    token.FamilyID == currentSessionID,
+			CreatedAt:  createdAt,
+			LastUsedAt: token.LastUsedAt,
+			ExpiresAt:  token.ExpiresAt,
+		})
+	}
+
+	return sessions, nil
+}
+
+// RevokeSession ends one of the user's sessions; its refresh token stops
+// working immediately
+func (s *AuthServiceImpl) RevokeSession(db *gorm.DB, userID uuid.UUID, sessionID uuid.UUID) error {
+	result := db.Where("family_id = ? AND user_id = ?", sessionID, userID).Delete(&models.Token{})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return errors.New("session not found")
+	}
+
+	if s.revocations != nil {
+		return s.revocations.RevokeSession(db, sessionID)
+	}
+	return nil
+}
+
+// RevokeAllSessions logs the user out everywhere
+func (s *AuthServiceImpl) RevokeAllSessions(db *gorm.DB, userID uuid.UUID) (int64, error) {
+	result := db.Where("user_id = ?", userID).Delete(&models.Token{})
+	if result.Error != nil {
+		return 0, result.Error
+	}
+
+	if s.revocations != nil {
+		if err := s.revocations.RevokeUser(db, userID); err != nil {
+			return 0, err
+		}
+	}
+	return result.RowsAffected, nil
+}
+
+// PurgeExpiredTokens removes token families whose every token has expired.
+// Used tokens of a live family are kept so RefreshSession still recognises
+// them when they are replayed.
+func (s *AuthServiceImpl) PurgeExpiredTokens(db *gorm.DB, now time.Time) (int64, error) {
+	live := db.Model(&models.Token{}).Select("family_id").Where("expires_at > ?", now)
+	result := db.Where("family_id NOT IN (?)", live).Delete(&models.Token{})
+	return result.RowsAffected, result.Error
 }
 
 func (s *AuthServiceImpl) GetUserRolesAndPermissions(db *gorm.DB, userID uuid.UUID) ([]string, bool, []utils.Permission, error) {
-	var userRoles []models.UserRole
-	
-	// Get user roles
-	result := db.Preload("Role").Where("user_id = ?", userID).Find(&userRoles)
+	var heldRoles []models.Role
+
+	// Get user roles, including those inherited from their teams
+	result := db.Where("id IN ("+heldRoleIDs+")", userID, userID).Find(&heldRoles)
 	if result.Error != nil {
 		return nil, false, nil, result.Error
 	}
 
 	var roles []string
 	isAdmin := false
-	
-	for _, ur := range userRoles {
-		roles = append(roles, ur.Role.Name)
-		if ur.Role.Name == "admin" {
+
+	for _, role := range heldRoles {
+		roles = append(roles, role.Name)
+		if role.Name == "admin" {
 			isAdmin = true
 		}
 	}
 
 	// Get permissions for all roles
 	var rolePermissions []models.RolePermission
-	result = db.Preload("Permission").Where("role_id IN (SELECT role_id FROM user_roles WHERE user_id = ?)", userID).Find(&rolePermissions)
+	result = db.Preload("Permission").Where("role_id IN ("+heldRoleIDs+")", userID, userID).Find(&rolePermissions)
 	if result.Error != nil {
 		return nil, false, nil, result.Error
 	}
@@ -150,6 +465,33 @@ func (s *AuthServiceImpl) GetUserRolesAndPermissions(db *gorm.DB, userID uuid.UU
 	return roles, isAdmin, permissions, nil
 }
 
+// ensureAccountEnabled refuses new tokens for accounts an admin disabled
+func ensureAccountEnabled(db *gorm.DB, userID uuid.UUID) error {
+	var disabled int64
+	if err := db.Model(&models.User{}).Where("id = ? AND disabled_at IS NOT NULL", userID).Count(&disabled).Error; err != nil {
+		return err
+	}
+	if disabled > 0 {
+		return errors.New("account disabled")
+	}
+	return nil
+}
+
+// HashRefreshToken is how refresh tokens are stored. They carry 256 random
+// bits, so a fast unsalted hash is enough and keeps them searchable.
+func HashRefreshToken(refreshToken string) string {
+	sum := sha256.Sum256([]byte(refreshToken))
+	return hex.EncodeToString(sum[:])
+}
+
+func generateRefreshToken() (string, error) {
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(buf), nil
+}
+
 func VerifyPassword(hashedPassword, plainPassword string) bool {
 	err := bcrypt.CompareHashAndPassword([]byte(hashedPassword), []byte(plainPassword))
 	return err == nil
@@ -1,7 +1,9 @@
 package services
 
 import (
+	"strings"
 	"testing"
+	"time"
 	"task-manager/backend/internal/models"
 
 	"github.com/gofrs/uuid"
@@ -11,13 +13,30 @@ import (
 )
 
 func setupTestDB() *gorm.DB {
-	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	return openTestDB(":memory:")
+}
+
+func openTestDB(dsn string) *gorm.DB {
+	db, err := gorm.Open(sqlite.Open(dsn), &gorm.Config{})
 	if err != nil {
 		panic("failed to connect database")
 	}
 
+	// SQLite has no gen_random_uuid(), so generate IDs from random bytes
+	db.Callback().Raw().Before("gorm:raw").Register("sqlite_uuid_default", func(tx *gorm.DB) {
+		sql := strings.ReplaceAll(tx.Statement.SQL.String(), "DEFAULT gen_random_uuid()", "DEFAULT (lower(hex(randomblob(16))))")
+		tx.Statement.SQL.Reset()
+		tx.Statement.SQL.WriteString(sql)
+	})
+
 	// Auto-migrate the schema
-	db.AutoMigrate(&models.User{}, &models.Role{}, &models.UserRole{}, &models.Permission{}, &models.RolePermission{})
+	db.AutoMigrate(&models.User{}, &models.Role{}, &models.UserRole{}, &models.Permission{}, &models.RolePermission{},
+		&models.Token{}, &models.UserIdentity{}, &models.UserMFA{}, &models.MFARecoveryCode{}, &models.MFAChallenge{},
+		&models.PasswordHistory{}, &models.LoginAttempt{}, &models.PersonalAccessToken{}, &models.AccountToken{},
+		&models.SecurityEvent{}, &models.Team{}, &models.TeamMember{}, &models.TeamRole{},
+		&models.Task{}, &models.TaskWatcher{}, &models.TaskTeamShare{}, &models.TaskUserShare{}, &models.TaskPublicLink{},
+		&models.Notification{}, &models.NotificationPreference{}, &models.EmailOutbox{},
+		&models.Webhook{}, &models.WebhookDelivery{}, &models.OutboxEvent{})
 
 	return db
 }
@@ -64,7 +83,7 @@ func TestAuthService_LoginUser(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			user, err := authService.LoginUser(db, tt.username, tt.password)
+			user, err := authService.LoginUser(db, tt.username, tt.password, ClientInfo{})
 			if tt.wantErr {
 				assert.Error(t, err)
 				assert.Nil(t, user)
@@ -124,7 +143,7 @@ func TestAuthService_GenerateToken(t *testing.T) {
 	db.Create(&userRole)
 
 	// Test token generation
-	accessToken, refreshToken, err := authService.GenerateToken(db, userID)
+	accessToken, refreshToken, err := authService.GenerateToken(db, userID, ClientInfo{})
 	assert.NoError(t, err)
 	assert.NotEmpty(t, accessToken)
 	assert.NotEmpty(t, refreshToken)
@@ -136,24 +155,69 @@ func TestAuthService_ValidateRefreshToken(t *testing.T) {
 
 	// Create test user
 	userID := uuid.Must(uuid.NewV4())
-	refreshTokenUUID := uuid.Must(uuid.NewV4())
+	refreshToken := uuid.Must(uuid.NewV4()).String()
 
 	// Create token
+	tokenID := uuid.Must(uuid.NewV4())
 	token := models.Token{
-		ID:           uuid.Must(uuid.NewV4()),
-		UserID:       userID,
-		RefreshToken: refreshTokenUUID,
+		ID:        tokenID,
+		UserID:    userID,
+		FamilyID:  tokenID,
+		TokenHash: HashRefreshToken(refreshToken),
+		ExpiresAt: time.Now().Add(time.Hour),
 	}
 	db.Create(&token)
 
 	// Test valid refresh token
-	validToken, err := authService.ValidateRefreshToken(db, refreshTokenUUID)
+	validToken, err := authService.ValidateRefreshToken(db, refreshToken)
 	assert.NoError(t, err)
 	assert.NotNil(t, validToken)
 	assert.Equal(t, userID, validToken.UserID)
 
 	// Test invalid refresh token
-	invalidTokenUUID := uuid.Must(uuid.NewV4())
-	_, err = authService.ValidateRefreshToken(db, invalidTokenUUID)
+	invalidToken := uuid.Must(uuid.NewV4()).String()
+	_, err = authService.ValidateRefreshToken(db, invalidToken)
 	assert.Error(t, err)
 }
+
+func TestAuthService_PurgeExpiredTokensKeepsLiveFamilies(t *testing.T) {
+	db := setupTestDB()
+	authService := NewAuthService()
+	user := createTestUser(db, "ada", "password123")
+	now := time.Now()
+
+	// A session that was refreshed: its first token expired after rotation
+	familyID := uuid.Must(uuid.NewV4())
+	usedAt := now.Add(-2 * time.Hour)
+	db.Create(&models.Token{ID: familyID, UserID: user.ID, FamilyID: familyID, TokenHash: HashRefreshToken("rotated"), ExpiresAt: now.Add(-time.Hour), UsedAt: &usedAt})
+	db.Create(&models.Token{ID: uuid.Must(uuid.NewV4()), UserID: user.ID, FamilyID: familyID, ParentID: &familyID, TokenHash: HashRefreshToken("current"), ExpiresAt: now.Add(time.Hour)})
+
+	// A session that ran out
+	staleID := uuid.Must(uuid.NewV4())
+	db.Create(&models.Token{ID: staleID, UserID: user.ID, FamilyID: staleID, TokenHash: HashRefreshToken("stale"), ExpiresAt: now.Add(-time.Hour)})
+
+	purged, err := authService.PurgeExpiredTokens(db, now)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), purged)
+
+	// Replaying the rotated token is still caught and ends the session
+	_, _, err = authService.RefreshSession(db, "rotated", ClientInfo{})
+	assert.EqualError(t, err, "refresh token reuse detected")
+
+	var remaining int64
+	db.Model(&models.Token{}).Count(&remaining)
+	assert.Zero(t, remaining)
+}
+
+func TestHashRefreshToken(t *testing.T) {
+	// Reference value from: echo -n 'refresh' | sha256sum
+	assert.Equal(t, "d6cc0a088c07683c65cd266860cab8d94b3a1937b17420d9da30ca299c09fb77", HashRefreshToken("refresh"))
+
+	first, err := generateRefreshToken()
+	assert.NoError(t, err)
+	second, err := generateRefreshToken()
+	assert.NoError(t, err)
+	assert.Len(t, first, 43)
+	assert.NotEqual(t, first, second)
+	assert.NotEqual(t, HashRefreshToken(first), HashRefreshToken(second))
+}
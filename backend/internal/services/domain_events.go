@@ -0,0 +1,138 @@
+package services
+
+import (
+	"encoding/json"
+	"task-manager/backend/internal/models"
+	"time"
+
+	"github.com/gofrs/uuid"
+	"gorm.io/gorm"
+)
+
+// DomainEvent describes a change that other parts of the system react to.
+// It is written to the outbox in the same transaction as the change itself.
+type DomainEvent struct {
+	ID          uuid.UUID       `json:"id"`
+	Type        string          `json:"type"`
+	AggregateID uuid.UUID       `json:"aggregate_id"`
+	ActorID     uuid.UUID       `json:"actor_id"`
+	Payload     json.RawMessage `json:"payload"`
+	OccurredAt  time.Time       `json:"occurred_at"`
+}
+
+// TaskEventPayload is the payload of the task.* events
+type TaskEventPayload struct {
+	Task    models.Task `json:"task"`
+	ActorID uuid.UUID   `json:"actor_id"`
+	Access  *TaskAccess `json:"access,omitempty"`
+	// Watchers is only set on task.deleted, whose watch rows are removed
+	// with the task
+	Watchers []uuid.UUID `json:"watchers,omitempty"`
+}
+
+// TaskAccess is who the task was shared with when the event was recorded.
+// The live stream filters on it; it is not part of the webhook payload.
+type TaskAccess struct {
+	TeamAccess map[uuid.UUID]string `json:"team_access,omitempty"`
+	UserAccess map[uuid.UUID]string `json:"user_access,omitempty"`
+}
+
+// UserEventPayload is the payload of the user.* events
+type UserEventPayload struct {
+	UserID  uuid.UUID `json:"user_id"`
+	ActorID uuid.UUID `json:"actor_id"`
+}
+
+// RoleEventPayload is the payload of the role.* events
+type RoleEventPayload struct {
+	RoleID  uuid.UUID `json:"role_id"`
+	ActorID uuid.UUID `json:"actor_id"`
+}
+
+// EventNotifier is told when new events were committed so they can be
+// dispatched without waiting for the next poll
+type EventNotifier interface {
+	Notify()
+}
+
+func NewTaskEvent(eventType string, task models.Task, actorID uuid.UUID) (DomainEvent, error) {
+	return newDomainEvent(eventType, task.ID, actorID, TaskEventPayload{Task: task, ActorID: actorID})
+}
+
+func NewUserDeletedEvent(userID uuid.UUID, actorID uuid.UUID) (DomainEvent, error) {
+	return newDomainEvent(models.DomainEventUserDeleted, userID, actorID, UserEventPayload{UserID: userID, ActorID: actorID})
+}
+
+func NewUserRolesChangedEvent(userID uuid.UUID, actorID uuid.UUID) (DomainEvent, error) {
+	return newDomainEvent(models.DomainEventUserRolesChanged, userID, actorID, UserEventPayload{UserID: userID, ActorID: actorID})
+}
+
+func NewRolePermissionsChangedEvent(roleID uuid.UUID, actorID uuid.UUID) (DomainEvent, error) {
+	return newDomainEvent(models.DomainEventRolePermissionsChanged, roleID, actorID, RoleEventPayload{RoleID: roleID, ActorID: actorID})
+}
+
+func newDomainEvent(eventType string, aggregateID uuid.UUID, actorID uuid.UUID, payload interface{}) (DomainEvent, error) {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return DomainEvent{}, err
+	}
+
+	return DomainEvent{
+		ID:          uuid.Must(uuid.NewV4()),
+		Type:        eventType,
+		AggregateID: aggregateID,
+		ActorID:     actorID,
+		Payload:     body,
+		OccurredAt:  time.Now().UTC(),
+	}, nil
+}
+
+// TaskPayload decodes the payload of a task.* event
+func (e DomainEvent) TaskPayload() (TaskEventPayload, error) {
+	var payload TaskEventPayload
+	err := json.Unmarshal(e.Payload, &payload)
+	return payload, err
+}
+
+// UserPayload decodes the payload of a user.* event
+func (e DomainEvent) UserPayload() (UserEventPayload, error) {
+	var payload UserEventPayload
+	err := json.Unmarshal(e.Payload, &payload)
+	return payload, err
+}
+
+// RolePayload decodes the payload of a role.* event
+func (e DomainEvent) RolePayload() (RoleEventPayload, error) {
+	var payload RoleEventPayload
+	err := json.Unmarshal(e.Payload, &payload)
+	return payload, err
+}
+
+// RecordDomainEvent stores the event in the outbox. Call it with the
+// transaction that makes the change so both commit or neither does.
+func RecordDomainEvent(tx *gorm.DB, event DomainEvent) error {
+	row := models.OutboxEvent{
+		ID:            event.ID,
+		EventType:     event.Type,
+		AggregateID:   event.AggregateID,
+		ActorID:       event.ActorID,
+		Payload:       string(event.Payload),
+		Status:        models.OutboxEventPending,
+		DeliveredTo:   []string{},
+		NextAttemptAt: event.OccurredAt,
+		CreatedAt:     event.OccurredAt,
+	}
+
+	return tx.Create(&row).Error
+}
+
+func domainEventFromOutbox(row models.OutboxEvent) DomainEvent {
+	return DomainEvent{
+		ID:          row.ID,
+		Type:        row.EventType,
+		AggregateID: row.AggregateID,
+		ActorID:     row.ActorID,
+		Payload:     json.RawMessage(row.Payload),
+		OccurredAt:  row.CreatedAt,
+	}
+}
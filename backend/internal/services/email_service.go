@@ -0,0 +1,273 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"task-manager/backend/internal/models"
+	"task-manager/backend/internal/utils"
+	"time"
+
+	"github.com/gofrs/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+const (
+	maxEmailAttempts = 5
+
+	// emailClaimLease is how long a claimed email is left to its sender
+	// before another dispatcher may pick it up again
+	emailClaimLease = 10 * time.Minute
+)
+
+type EmailService interface {
+	GetPreference(db *gorm.DB, userID uuid.UUID) (models.NotificationPreference, error)
+	UpdatePreference(db *gorm.DB, userID uuid.UUID, emailMode string) (models.NotificationPreference, error)
+	QueueNotificationEmail(db *gorm.DB, notification models.Notification) error
+	EnqueueEmail(db *gorm.DB, userID *uuid.UUID, msg MailMessage) error
+	SendDigests(db *gorm.DB, emailMode string, now time.Time) error
+	ProcessOutbox(db *gorm.DB, sender MailSender, limit int) (int, error)
+}
+
+type EmailServiceImpl struct {
+	appURL string
+}
+
+func NewEmailService() *EmailServiceImpl {
+	return &EmailServiceImpl{appURL: utils.GetEnv("APP_URL", "http://localhost:3000")}
+}
+
+func (s *EmailServiceImpl) GetPreference(db *gorm.DB, userID uuid.UUID) (models.NotificationPreference, error) {
+	var preference models.NotificationPreference
+
+	result := db.Where("user_id = ?", userID).First(&preference)
+	if result.Error != nil {
+		if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+			return models.NotificationPreference{UserID: userID, EmailMode: models.EmailModeImmediate}, nil
+		}
+		return models.NotificationPreference{}, result.Error
+	}
+
+	return preference, nil
+}
+
+func (s *EmailServiceImpl) UpdatePreference(db *gorm.DB, userID uuid.UUID, emailMode string) (models.NotificationPreference, error) {
+	preference, err := s.GetPreference(db, userID)
+	if err != nil {
+		return models.NotificationPreference{}, err
+	}
+
+	preference.EmailMode = emailMode
+
+	if err := db.Save(&preference).Error; err != nil {
+		return models.NotificationPreference{}, err
+	}
+
+	return preference, nil
+}
+
+// QueueNotificationEmail emails the recipient right away or parks the
+// notification for their next digest, depending on their preference
+func (s *EmailServiceImpl) QueueNotificationEmail(db *gorm.DB, notification models.Notification) error {
+	preference, err := s.GetPreference(db, notification.UserID)
+	if err != nil {
+		return err
+	}
+
+	switch preference.EmailMode {
+	case models.EmailModeOff:
+		return nil
+	case models.EmailModeHourly, models.EmailModeDaily:
+		return db.Model(&notification).Update("email_pending", true).Error
+	}
+
+	var user models.User
+	if err := db.Where("id = ?", notification.UserID).First(&user).Error; err != nil {
+		return err
+	}
+
+	textBody, htmlBody, err := renderTemplates(notificationText, notificationHTML, notificationEmailData{
+		Username:     user.Username,
+		AppURL:       s.appURL,
+		Notification: notification,
+	})
+	if err != nil {
+		return err
+	}
+
+	return s.EnqueueEmail(db, &user.ID, MailMessage{
+		To:       user.Email,
+		Subject:  notification.Message,
+		TextBody: textBody,
+		HTMLBody: htmlBody,
+	})
+}
+
+func (s *EmailServiceImpl) EnqueueEmail(db *gorm.DB, userID *uuid.UUID, msg MailMessage) error {
+	email := models.EmailOutbox{
+		ID:            uuid.Must(uuid.NewV4()),
+		UserID:        userID,
+		ToAddress:     msg.To,
+		Subject:       msg.Subject,
+		TextBody:      msg.TextBody,
+		HTMLBody:      msg.HTMLBody,
+		Status:        models.EmailStatusPending,
+		NextAttemptAt: time.Now(),
+	}
+
+	return db.Create(&email).Error
+}
+
+// SendDigests bundles the pending notifications of every user on the given
+// digest schedule whose last digest is at least one period old
+func (s *EmailServiceImpl) SendDigests(db *gorm.DB, emailMode string, now time.Time) error {
+	period := time.Hour
+	if emailMode == models.EmailModeDaily {
+		period = 24 * time.Hour
+	}
+
+	var preferences []models.NotificationPreference
+	result := db.Where("email_mode = ? AND (last_digest_at IS NULL OR last_digest_at <= ?)", emailMode, now.Add(-period)).Find(&preferences)
+	if result.Error != nil {
+		return result.Error
+	}
+
+	for _, preference := range preferences {
+		if err := s.sendDigest(db, preference, now); err != nil {
+			log.Printf("Failed to build email digest for user %s: %v", preference.UserID, err)
+		}
+	}
+
+	return nil
+}
+
+func (s *EmailServiceImpl) sendDigest(db *gorm.DB, preference models.NotificationPreference, now time.Time) error {
+	return db.Transaction(func(tx *gorm.DB) error {
+		var notifications []models.Notification
+		result := tx.Where("user_id = ? AND email_pending = ?", preference.UserID, true).Order("created_at asc").Find(&notifications)
+		if result.Error != nil {
+			return result.Error
+		}
+
+		if len(notifications) > 0 {
+			var user models.User
+			if err := tx.Where("id = ?", preference.UserID).First(&user).Error; err != nil {
+				return err
+			}
+
+			textBody, htmlBody, err := renderTemplates(digestText, digestHTML, digestEmailData{
+				Username:      user.Username,
+				AppURL:        s.appURL,
+				Notifications: notifications,
+			})
+			if err != nil {
+				return err
+			}
+
+			err = s.EnqueueEmail(tx, &user.ID, MailMessage{
+				To:       user.Email,
+				Subject:  fmt.Sprintf("Your Taskify digest: %d task updates", len(notifications)),
+				TextBody: textBody,
+				HTMLBody: htmlBody,
+			})
+			if err != nil {
+				return err
+			}
+
+			ids := make([]uuid.UUID, 0, len(notifications))
+			for _, n := range notifications {
+				ids = append(ids, n.ID)
+			}
+			if err := tx.Model(&models.Notification{}).Where("id IN ?", ids).Update("email_pending", false).Error; err != nil {
+				return err
+			}
+		}
+
+		return tx.Model(&preference).Update("last_digest_at", now).Error
+	})
+}
+
+// ProcessOutbox sends up to limit due emails. Rows are claimed with SKIP
+// LOCKED and leased by pushing next_attempt_at forward, so several replicas
+// can drain the outbox without sending a mail twice. Mail goes out after
+// the claim is committed and each result is recorded on its own; a mail
+// whose result could not be recorded is retried once the lease runs out.
+func (s *EmailServiceImpl) ProcessOutbox(db *gorm.DB, sender MailSender, limit int) (int, error) {
+	emails, err := claimOutboxEmails(db, limit)
+	if err != nil {
+		return 0, err
+	}
+
+	sent := 0
+	for _, email := range emails {
+		err := sender.Send(MailMessage{
+			To:       email.ToAddress,
+			Subject:  email.Subject,
+			TextBody: email.TextBody,
+			HTMLBody: email.HTMLBody,
+		})
+
+		updates := map[string]interface{}{"attempts": email.Attempts + 1}
+		if err == nil {
+			updates["status"] = models.EmailStatusSent
+			updates["sent_at"] = time.Now()
+			updates["last_error"] = ""
+			sent++
+		} else {
+			updates["last_error"] = err.Error()
+			if email.Attempts+1 >= maxEmailAttempts {
+				updates["status"] = models.EmailStatusFailed
+			} else {
+				// Back off exponentially: 1, 2, 4, 8 minutes
+				updates["next_attempt_at"] = time.Now().Add(time.Minute << email.Attempts)
+			}
+		}
+
+		if err := db.Model(&email).Updates(updates).Error; err != nil {
+			log.Printf("Failed to record result of email %s: %v", email.ID, err)
+		}
+	}
+
+	return sent, nil
+}
+
+// claimOutboxEmails leases up to limit due emails to the caller
+func claimOutboxEmails(db *gorm.DB, limit int) ([]models.EmailOutbox, error) {
+	var emails []models.EmailOutbox
+
+	err := db.Transaction(func(tx *gorm.DB) error {
+		result := tx.Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+			Where("status = ? AND next_attempt_at <= ?", models.EmailStatusPending, time.Now()).
+			Order("next_attempt_at asc").
+			Limit(limit).
+			Find(&emails)
+		if result.Error != nil || len(emails) == 0 {
+			return result.Error
+		}
+
+		ids := make([]uuid.UUID, 0, len(emails))
+		for _, email := range emails {
+			ids = append(ids, email.ID)
+		}
+		return tx.Model(&models.EmailOutbox{}).Where("id IN ?", ids).Update("next_attempt_at", time.Now().Add(emailClaimLease)).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return emails, nil
+}
+
+// RunEmailDispatcher drains the outbox until the process exits; digests are
+// built by the email-digests job. Mails already in the outbox survive restarts.
+func RunEmailDispatcher(db *gorm.DB, emailService EmailService, sender MailSender, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		if _, err := emailService.ProcessOutbox(db, sender, 50); err != nil {
+			log.Printf("Failed to process email outbox: %v", err)
+		}
+	}
+}
@@ -0,0 +1,96 @@
+package services
+
+import (
+	"errors"
+	"task-manager/backend/internal/models"
+	"testing"
+	"time"
+
+	"github.com/gofrs/uuid"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+)
+
+type recordingMailSender struct {
+	sent []MailMessage
+	err  error
+}
+
+func (s *recordingMailSender) Send(msg MailMessage) error {
+	if s.err != nil {
+		return s.err
+	}
+	s.sent = append(s.sent, msg)
+	return nil
+}
+
+func queueTestEmail(db *gorm.DB, to string) models.EmailOutbox {
+	email := models.EmailOutbox{
+		ID:            uuid.Must(uuid.NewV4()),
+		ToAddress:     to,
+		Subject:       "Task updated",
+		Status:        models.EmailStatusPending,
+		NextAttemptAt: time.Now().Add(-time.Minute),
+	}
+	db.Create(&email)
+	return email
+}
+
+func TestEmailService_ProcessOutbox(t *testing.T) {
+	db := setupTestDB()
+	emailService := NewEmailService()
+	sender := &recordingMailSender{}
+
+	first := queueTestEmail(db, "ada@example.com")
+	queueTestEmail(db, "grace@example.com")
+
+	sent, err := emailService.ProcessOutbox(db, sender, 10)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, sent)
+	assert.Len(t, sender.sent, 2)
+
+	var email models.EmailOutbox
+	db.First(&email, "id = ?", first.ID)
+	assert.Equal(t, models.EmailStatusSent, email.Status)
+	assert.Equal(t, 1, email.Attempts)
+	assert.NotNil(t, email.SentAt)
+
+	// Sent mail is never picked up again
+	sent, err = emailService.ProcessOutbox(db, sender, 10)
+	assert.NoError(t, err)
+	assert.Equal(t, 0, sent)
+	assert.Len(t, sender.sent, 2)
+}
+
+func TestEmailService_ProcessOutboxBacksOffFailures(t *testing.T) {
+	db := setupTestDB()
+	emailService := NewEmailService()
+	sender := &recordingMailSender{err: errors.New("connection refused")}
+
+	queued := queueTestEmail(db, "ada@example.com")
+
+	sent, err := emailService.ProcessOutbox(db, sender, 10)
+	assert.NoError(t, err)
+	assert.Equal(t, 0, sent)
+
+	var email models.EmailOutbox
+	db.First(&email, "id = ?", queued.ID)
+	assert.Equal(t, models.EmailStatusPending, email.Status)
+	assert.Equal(t, 1, email.Attempts)
+	assert.Equal(t, "connection refused", email.LastError)
+	assert.True(t, email.NextAttemptAt.After(time.Now()))
+}
+
+func TestClaimOutboxEmails_LeasesClaimedRows(t *testing.T) {
+	db := setupTestDB()
+	queueTestEmail(db, "ada@example.com")
+
+	claimed, err := claimOutboxEmails(db, 10)
+	assert.NoError(t, err)
+	assert.Len(t, claimed, 1)
+
+	// Another dispatcher finds nothing until the lease runs out
+	claimed, err = claimOutboxEmails(db, 10)
+	assert.NoError(t, err)
+	assert.Empty(t, claimed)
+}
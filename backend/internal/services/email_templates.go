@@ -0,0 +1,135 @@
+package services
+
+import (
+	"bytes"
+	htmltemplate "html/template"
+	"task-manager/backend/internal/models"
+	texttemplate "text/template"
+)
+
+const notificationTextTemplate = `Hi {{.Username}},
+
+{{.Notification.Message}}.
+
+Open Taskify to see the details: {{.AppURL}}
+
+You are receiving this because you own this task. Change your email preferences in Taskify.
+`
+
+const notificationHTMLTemplate = `<!DOCTYPE html>
+<html>
+<body style="font-family: Arial, sans-serif; color: #333;">
+  <p>Hi {{.Username}},</p>
+  <p>{{.Notification.Message}}.</p>
+  <p><a href="{{.AppURL}}" style="color: #1976d2;">Open Taskify</a> to see the details.</p>
+  <p style="font-size: 12px; color: #888;">You are receiving this because you own this task. Change your email preferences in Taskify.</p>
+</body>
+</html>
+`
+
+const digestTextTemplate = `Hi {{.Username}},
+
+Here is what happened to your tasks since your last digest:
+{{range .Notifications}}
+- {{.Message}} ({{.CreatedAt.Format "Jan 2 15:04"}})
+{{- end}}
+
+Open Taskify to see the details: {{.AppURL}}
+`
+
+const digestHTMLTemplate = `<!DOCTYPE html>
+<html>
+<body style="font-family: Arial, sans-serif; color: #333;">
+  <p>Hi {{.Username}},</p>
+  <p>Here is what happened to your tasks since your last digest:</p>
+  <ul>
+  {{- range .Notifications}}
+    <li>{{.Message}} <span style="color: #888;">({{.CreatedAt.Format "Jan 2 15:04"}})</span></li>
+  {{- end}}
+  </ul>
+  <p><a href="{{.AppURL}}" style="color: #1976d2;">Open Taskify</a> to see the details.</p>
+</body>
+</html>
+`
+
+const verificationTextTemplate = `Hi {{.Username}},
+
+Please confirm your email address for Taskify by opening this link:
+
+{{.Link}}
+
+The link expires in {{.ExpiresIn}}. If you did not create a Taskify account, you can ignore this email.
+`
+
+const verificationHTMLTemplate = `<!DOCTYPE html>
+<html>
+<body style="font-family: Arial, sans-serif; color: #333;">
+  <p>Hi {{.Username}},</p>
+  <p>Please confirm your email address for Taskify.</p>
+  <p><a href="{{.Link}}" style="color: #1976d2;">Confirm email address</a></p>
+  <p style="font-size: 12px; color: #888;">The link expires in {{.ExpiresIn}}. If you did not create a Taskify account, you can ignore this email.</p>
+</body>
+</html>
+`
+
+const passwordResetTextTemplate = `Hi {{.Username}},
+
+Someone asked to reset the password of your Taskify account. Choose a new password here:
+
+{{.Link}}
+
+The link expires in {{.ExpiresIn}} and works once. If you did not ask for this, you can ignore this email; your password stays the same.
+`
+
+const passwordResetHTMLTemplate = `<!DOCTYPE html>
+<html>
+<body style="font-family: Arial, sans-serif; color: #333;">
+  <p>Hi {{.Username}},</p>
+  <p>Someone asked to reset the password of your Taskify account.</p>
+  <p><a href="{{.Link}}" style="color: #1976d2;">Choose a new password</a></p>
+  <p style="font-size: 12px; color: #888;">The link expires in {{.ExpiresIn}} and works once. If you did not ask for this, you can ignore this email; your password stays the same.</p>
+</body>
+</html>
+`
+
+var (
+	notificationText  = texttemplate.Must(texttemplate.New("notification_text").Parse(notificationTextTemplate))
+	notificationHTML  = htmltemplate.Must(htmltemplate.New("notification_html").Parse(notificationHTMLTemplate))
+	digestText        = texttemplate.Must(texttemplate.New("digest_text").Parse(digestTextTemplate))
+	digestHTML        = htmltemplate.Must(htmltemplate.New("digest_html").Parse(digestHTMLTemplate))
+	verificationText  = texttemplate.Must(texttemplate.New("verification_text").Parse(verificationTextTemplate))
+	verificationHTML  = htmltemplate.Must(htmltemplate.New("verification_html").Parse(verificationHTMLTemplate))
+	passwordResetText = texttemplate.Must(texttemplate.New("password_reset_text").Parse(passwordResetTextTemplate))
+	passwordResetHTML = htmltemplate.Must(htmltemplate.New("password_reset_html").Parse(passwordResetHTMLTemplate))
+)
+
+type notificationEmailData struct {
+	Username     string
+	AppURL       string
+	Notification models.Notification
+}
+
+type digestEmailData struct {
+	Username      string
+	AppURL        string
+	Notifications []models.Notification
+}
+
+type accountEmailData struct {
+	Username  string
+	Link      string
+	ExpiresIn string
+}
+
+func renderTemplates(text *texttemplate.Template, html *htmltemplate.Template, data interface{}) (string, string, error) {
+	var textBody, htmlBody bytes.Buffer
+
+	if err := text.Execute(&textBody, data); err != nil {
+		return "", "", err
+	}
+	if err := html.Execute(&htmlBody, data); err != nil {
+		return "", "", err
+	}
+
+	return textBody.String(), htmlBody.String(), nil
+}
@@ -0,0 +1,198 @@
+package services
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"strings"
+	"sync"
+	"task-manager/backend/internal/models"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+const (
+	maxOutboxAttempts = 10
+	maxOutboxBackoff  = 30 * time.Minute
+)
+
+// EventHandler reacts to a domain event. Delivery is at-least-once, so a
+// handler may see the same event again after a crash or a failed sibling.
+type EventHandler func(db *gorm.DB, event DomainEvent) error
+
+type eventSubscription struct {
+	name       string
+	eventTypes map[string]bool
+	handler    EventHandler
+}
+
+// EventDispatcher delivers outbox events to the registered subscribers.
+// Each subscriber is tracked separately, so a retry only re-runs the ones
+// that have not handled the event yet.
+type EventDispatcher struct {
+	mutex         sync.RWMutex
+	subscriptions []eventSubscription
+	wake          chan struct{}
+}
+
+func NewEventDispatcher() *EventDispatcher {
+	return &EventDispatcher{wake: make(chan struct{}, 1)}
+}
+
+// Subscribe registers a handler for the given event types. The name is
+// stored with every event it handled and must stay stable across releases.
+func (d *EventDispatcher) Subscribe(name string, handler EventHandler, eventTypes ...string) {
+	types := make(map[string]bool, len(eventTypes))
+	for _, eventType := range eventTypes {
+		types[eventType] = true
+	}
+
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+	d.subscriptions = append(d.subscriptions, eventSubscription{name: name, eventTypes: types, handler: handler})
+}
+
+// Notify wakes the dispatcher loop; it never blocks
+func (d *EventDispatcher) Notify() {
+	select {
+	case d.wake <- struct{}{}:
+	default:
+	}
+}
+
+// ProcessOutbox delivers up to limit pending events in the order they were
+// recorded and returns how many were fully processed
+func (d *EventDispatcher) ProcessOutbox(db *gorm.DB, limit int) (int, error) {
+	processed := 0
+
+	err := db.Transaction(func(tx *gorm.DB) error {
+		var rows []models.OutboxEvent
+		result := tx.Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+			Where("status = ? AND next_attempt_at <= ?", models.OutboxEventPending, time.Now()).
+			Order("created_at asc").
+			Limit(limit).
+			Find(&rows)
+		if result.Error != nil {
+			return result.Error
+		}
+
+		for _, row := range rows {
+			// Handlers write through db, not tx, so their work is kept even
+			// when a later event in this batch fails
+			delivered, deliverErr := d.deliver(db, domainEventFromOutbox(row), row.DeliveredTo)
+
+			updates := map[string]interface{}{
+				"attempts": row.Attempts + 1,
+				// Map updates bypass the json serializer, so encode by hand
+				"delivered_to": encodeDeliveredTo(delivered),
+			}
+			if deliverErr == nil {
+				updates["status"] = models.OutboxEventProcessed
+				updates["processed_at"] = time.Now()
+				updates["last_error"] = ""
+				processed++
+			} else {
+				updates["last_error"] = deliverErr.Error()
+				if row.Attempts+1 >= maxOutboxAttempts {
+					updates["status"] = models.OutboxEventFailed
+					log.Printf("Giving up on outbox event %s (%s): %v", row.ID, row.EventType, deliverErr)
+				} else {
+					updates["next_attempt_at"] = time.Now().Add(OutboxBackoff(row.Attempts + 1))
+				}
+			}
+
+			if err := tx.Model(&row).Updates(updates).Error; err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+
+	return processed, err
+}
+
+// deliver runs every matching subscriber that is not in delivered yet and
+// returns the updated list of subscribers that handled the event
+func (d *EventDispatcher) deliver(db *gorm.DB, event DomainEvent, delivered []string) ([]string, error) {
+	d.mutex.RLock()
+	subscriptions := d.subscriptions
+	d.mutex.RUnlock()
+
+	done := make(map[string]bool, len(delivered))
+	for _, name := range delivered {
+		done[name] = true
+	}
+
+	var failures []string
+	for _, sub := range subscriptions {
+		if !sub.eventTypes[event.Type] || done[sub.name] {
+			continue
+		}
+
+		if err := sub.handler(db, event); err != nil {
+			failures = append(failures, fmt.Sprintf("%s: %v", sub.name, err))
+			continue
+		}
+
+		delivered = append(delivered, sub.name)
+		done[sub.name] = true
+	}
+
+	if len(failures) > 0 {
+		return delivered, fmt.Errorf("%s", strings.Join(failures, "; "))
+	}
+	return delivered, nil
+}
+
+func encodeDeliveredTo(delivered []string) string {
+	if delivered == nil {
+		delivered = []string{}
+	}
+	body, _ := json.Marshal(delivered)
+	return string(body)
+}
+
+func OutboxBackoff(attempts int) time.Duration {
+	backoff := 5 * time.Second
+	for i := 1; i < attempts; i++ {
+		backoff *= 2
+		if backoff >= maxOutboxBackoff {
+			return maxOutboxBackoff
+		}
+	}
+	return backoff
+}
+
+// PurgeProcessedEvents removes delivered outbox events older than before
+func PurgeProcessedEvents(db *gorm.DB, before time.Time) error {
+	return db.Where("status = ? AND processed_at < ?", models.OutboxEventProcessed, before).
+		Delete(&models.OutboxEvent{}).Error
+}
+
+// RunEventDispatcher processes the outbox on every tick and whenever a
+// service reports newly committed events
+func RunEventDispatcher(db *gorm.DB, dispatcher *EventDispatcher, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+		case <-dispatcher.wake:
+		}
+
+		for {
+			processed, err := dispatcher.ProcessOutbox(db, 100)
+			if err != nil {
+				log.Printf("Failed to process outbox events: %v", err)
+				break
+			}
+			if processed < 100 {
+				break
+			}
+		}
+	}
+}
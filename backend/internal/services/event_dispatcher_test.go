@@ -0,0 +1,73 @@
+package services
+
+import (
+	"errors"
+	"task-manager/backend/internal/models"
+	"testing"
+	"time"
+
+	"github.com/gofrs/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+func TestNewTaskEvent_RoundTripsPayload(t *testing.T) {
+	task := models.Task{ID: uuid.Must(uuid.NewV4()), Title: "Ship release"}
+	actorID := uuid.Must(uuid.NewV4())
+
+	event, err := NewTaskEvent(models.DomainEventTaskUpdated, task, actorID)
+	require.NoError(t, err)
+	assert.Equal(t, task.ID, event.AggregateID)
+
+	payload, err := event.TaskPayload()
+	require.NoError(t, err)
+	assert.Equal(t, "Ship release", payload.Task.Title)
+	assert.Equal(t, actorID, payload.ActorID)
+}
+
+func TestEventDispatcher_DeliverSkipsHandledSubscribers(t *testing.T) {
+	dispatcher := NewEventDispatcher()
+
+	var calls []string
+	dispatcher.Subscribe("notifications", func(db *gorm.DB, event DomainEvent) error {
+		calls = append(calls, "notifications")
+		return nil
+	}, TaskEventTypes...)
+	dispatcher.Subscribe("webhooks", func(db *gorm.DB, event DomainEvent) error {
+		calls = append(calls, "webhooks")
+		return errors.New("queue unavailable")
+	}, TaskEventTypes...)
+	dispatcher.Subscribe("user-cleanup", func(db *gorm.DB, event DomainEvent) error {
+		calls = append(calls, "user-cleanup")
+		return nil
+	}, models.DomainEventUserDeleted)
+
+	event, err := NewTaskEvent(models.DomainEventTaskCreated, models.Task{}, uuid.Nil)
+	require.NoError(t, err)
+
+	delivered, err := dispatcher.deliver(nil, event, nil)
+	assert.EqualError(t, err, "webhooks: queue unavailable")
+	assert.Equal(t, []string{"notifications"}, delivered)
+	assert.Equal(t, []string{"notifications", "webhooks"}, calls)
+
+	// The retry only runs the subscriber that failed
+	calls = nil
+	delivered, err = dispatcher.deliver(nil, event, delivered)
+	assert.Error(t, err)
+	assert.Equal(t, []string{"notifications"}, delivered)
+	assert.Equal(t, []string{"webhooks"}, calls)
+}
+
+func TestEventDispatcher_NotifyNeverBlocks(t *testing.T) {
+	dispatcher := NewEventDispatcher()
+	dispatcher.Notify()
+	dispatcher.Notify()
+	assert.Len(t, dispatcher.wake, 1)
+}
+
+func TestOutboxBackoff(t *testing.T) {
+	assert.Equal(t, 5*time.Second, OutboxBackoff(1))
+	assert.Equal(t, 40*time.Second, OutboxBackoff(4))
+	assert.Equal(t, 30*time.Minute, OutboxBackoff(20))
+}
@@ -0,0 +1,115 @@
+package services
+
+import (
+	"task-manager/backend/internal/models"
+
+	"gorm.io/gorm"
+)
+
+// TaskEventTypes are the events raised by task mutations
+var TaskEventTypes = []string{
+	models.DomainEventTaskCreated,
+	models.DomainEventTaskUpdated,
+	models.DomainEventTaskDeleted,
+}
+
+// PermissionEventTypes are the events that change what a user may do
+var PermissionEventTypes = []string{
+	models.DomainEventUserRolesChanged,
+	models.DomainEventRolePermissionsChanged,
+	models.DomainEventUserDeleted,
+}
+
+// NotificationEventSubscriber fills the watchers' inboxes. The watch rows
+// of a deleted task go with it, so task.deleted carries its watchers.
+func NotificationEventSubscriber(notificationService NotificationService) EventHandler {
+	return func(db *gorm.DB, event DomainEvent) error {
+		payload, err := event.TaskPayload()
+		if err != nil {
+			return err
+		}
+
+		if event.Type == models.DomainEventTaskDeleted {
+			return notificationService.NotifyWatchers(db, payload.Task, payload.ActorID, event.Type, payload.Watchers)
+		}
+		return notificationService.NotifyTaskEvent(db, payload.Task, payload.ActorID, event.Type)
+	}
+}
+
+// WebhookEventSubscriber queues a delivery for every subscribed webhook
+func WebhookEventSubscriber(webhookService WebhookService) EventHandler {
+	return func(db *gorm.DB, event DomainEvent) error {
+		payload, err := event.TaskPayload()
+		if err != nil {
+			return err
+		}
+
+		// Who the task is shared with and watched by stays internal
+		payload.Access = nil
+		payload.Watchers = nil
+		return webhookService.EnqueueEvent(db, event.Type, payload)
+	}
+}
+
+// StreamEventSubscriber pushes task changes to live stream clients
+func StreamEventSubscriber(broker StreamBroker) EventHandler {
+	return func(db *gorm.DB, event DomainEvent) error {
+		payload, err := event.TaskPayload()
+		if err != nil {
+			return err
+		}
+
+		var access TaskAccess
+		if payload.Access != nil {
+			access = *payload.Access
+		}
+		broker.Publish(event.Type, payload.Task, payload.ActorID, access)
+		return nil
+	}
+}
+
+// UserCleanupEventSubscriber stops notifying and emailing deleted users
+func UserCleanupEventSubscriber() EventHandler {
+	return func(db *gorm.DB, event DomainEvent) error {
+		payload, err := event.UserPayload()
+		if err != nil {
+			return err
+		}
+
+		if err := db.Where("user_id = ?", payload.UserID).Delete(&models.TaskWatcher{}).Error; err != nil {
+			return err
+		}
+		if err := db.Where("user_id = ?", payload.UserID).Delete(&models.TeamMember{}).Error; err != nil {
+			return err
+		}
+		if err := db.Where("user_id = ?", payload.UserID).Delete(&models.TaskUserShare{}).Error; err != nil {
+			return err
+		}
+
+		return db.Model(&models.EmailOutbox{}).
+			Where("user_id = ? AND status = ?", payload.UserID, models.EmailStatusPending).
+			Updates(map[string]interface{}{"status": models.EmailStatusFailed, "last_error": "user deleted"}).Error
+	}
+}
+
+// PermissionCacheEventSubscriber keeps the resolver's cache in step with
+// role and permission changes
+func PermissionCacheEventSubscriber(resolver *PermissionResolver) EventHandler {
+	return func(db *gorm.DB, event DomainEvent) error {
+		switch event.Type {
+		case models.DomainEventRolePermissionsChanged:
+			payload, err := event.RolePayload()
+			if err != nil {
+				return err
+			}
+			resolver.InvalidateRole(payload.RoleID)
+		default:
+			payload, err := event.UserPayload()
+			if err != nil {
+				return err
+			}
+			resolver.InvalidateUser(payload.UserID)
+		}
+		return nil
+	}
+}
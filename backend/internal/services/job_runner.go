@@ -0,0 +1,484 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"sort"
+	"sync"
+	"task-manager/backend/internal/models"
+	"task-manager/backend/internal/utils"
+	"time"
+
+	"github.com/gofrs/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+const (
+	// Arbitrary key for pg_try_advisory_xact_lock; only the replica holding
+	// it enqueues scheduled runs in a given tick
+	jobSchedulerLockKey = 720033
+
+	// Running jobs whose worker has not reported back for this long are
+	// assumed lost and queued again
+	jobLockTimeout = 15 * time.Minute
+
+	// Workers renew the lock of the run they are working on this often, so
+	// only runs whose worker is gone reach jobLockTimeout
+	jobHeartbeatInterval = jobLockTimeout / 3
+
+	maxJobBackoff = time.Hour
+)
+
+// JobHandler does the work of one job run; returning an error retries the
+// run with backoff until its attempts are used up
+type JobHandler func(db *gorm.DB, job models.Job) error
+
+type JobService interface {
+	GetScheduledJobs(db *gorm.DB) ([]models.ScheduledJob, error)
+	GetJobRuns(db *gorm.DB, name string, pagination utils.PaginationParams) (utils.PaginationResponse, error)
+	TriggerJob(db *gorm.DB, name string, triggeredBy uuid.UUID) (*models.Job, error)
+	SetJobPaused(db *gorm.DB, name string, paused bool) (*models.ScheduledJob, error)
+}
+
+type registeredJob struct {
+	spec        string
+	schedule    Schedule
+	maxAttempts int
+	handler     JobHandler
+}
+
+// JobRunner runs registered jobs from the Postgres backed jobs queue. Every
+// replica works the queue; scheduled runs are enqueued by whichever replica
+// holds the scheduler lock.
+type JobRunner struct {
+	mutex     sync.RWMutex
+	jobs      map[string]registeredJob
+	heartbeat time.Duration
+}
+
+func NewJobRunner() *JobRunner {
+	return &JobRunner{jobs: make(map[string]registeredJob), heartbeat: jobHeartbeatInterval}
+}
+
+// Register adds a job. It panics on an invalid schedule, which is a
+// programming error rather than something to handle at runtime.
+func (r *JobRunner) Register(name string, spec string, maxAttempts int, handler JobHandler) {
+	schedule, err := ParseSchedule(spec)
+	if err != nil {
+		panic(fmt.Sprintf("job %s: %v", name, err))
+	}
+	if maxAttempts < 1 {
+		maxAttempts = 1
+	}
+
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	r.jobs[name] = registeredJob{spec: spec, schedule: schedule, maxAttempts: maxAttempts, handler: handler}
+}
+
+func (r *JobRunner) job(name string) (registeredJob, bool) {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+	job, ok := r.jobs[name]
+	return job, ok
+}
+
+func (r *JobRunner) names() []string {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	names := make([]string, 0, len(r.jobs))
+	for name := range r.jobs {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// SyncSchedules makes sure every registered job has a scheduled_jobs row and
+// picks up schedule changes, keeping the paused flag set by admins
+func (r *JobRunner) SyncSchedules(db *gorm.DB, now time.Time) error {
+	for _, name := range r.names() {
+		job, _ := r.job(name)
+		next := job.schedule.Next(now)
+
+		var scheduled models.ScheduledJob
+		result := db.Where("name = ?", name).Limit(1).Find(&scheduled)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			scheduled = models.ScheduledJob{Name: name, Schedule: job.spec, NextRunAt: &next}
+			if err := db.Clauses(clause.OnConflict{DoNothing: true}).Create(&scheduled).Error; err != nil {
+				return err
+			}
+			continue
+		}
+
+		if scheduled.Schedule != job.spec {
+			err := db.Model(&scheduled).Updates(map[string]interface{}{"schedule": job.spec, "next_run_at": next}).Error
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// ScheduleDueJobs enqueues a run for every due, unpaused job. A job that
+// still has a run queued or in progress is not queued a second time.
+func (r *JobRunner) ScheduleDueJobs(db *gorm.DB, now time.Time) (int, error) {
+	queued := 0
+
+	err := db.Transaction(func(tx *gorm.DB) error {
+		leader, err := tryAdvisoryXactLock(tx, jobSchedulerLockKey)
+		if err != nil || !leader {
+			return err
+		}
+
+		// Recover runs abandoned by a crashed worker
+		err = tx.Model(&models.Job{}).
+			Where("status = ? AND locked_at < ?", models.JobStatusRunning, now.Add(-jobLockTimeout)).
+			Updates(map[string]interface{}{"status": models.JobStatusQueued, "locked_at": nil, "last_error": "worker timed out"}).Error
+		if err != nil {
+			return err
+		}
+
+		var due []models.ScheduledJob
+		result := tx.Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+			Where("paused = ? AND next_run_at <= ?", false, now).
+			Find(&due)
+		if result.Error != nil {
+			return result.Error
+		}
+
+		for _, scheduled := range due {
+			job, ok := r.job(scheduled.Name)
+			if !ok {
+				continue
+			}
+
+			var active int64
+			err := tx.Model(&models.Job{}).
+				Where("name = ? AND status IN ?", scheduled.Name, []string{models.JobStatusQueued, models.JobStatusRunning}).
+				Count(&active).Error
+			if err != nil {
+				return err
+			}
+
+			if active == 0 {
+				if err := tx.Create(newJobRun(scheduled.Name, job.maxAttempts, now, nil)).Error; err != nil {
+					return err
+				}
+				queued++
+			}
+
+			next := job.schedule.Next(now)
+			if err := tx.Model(&scheduled).Update("next_run_at", next).Error; err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+
+	return queued, err
+}
+
+// ProcessJobs runs up to limit due jobs one at a time and returns how many
+// succeeded
+func (r *JobRunner) ProcessJobs(db *gorm.DB, limit int) (int, error) {
+	succeeded := 0
+
+	for i := 0; i < limit; i++ {
+		job, err := r.claimJob(db)
+		if err != nil {
+			return succeeded, err
+		}
+		if job == nil {
+			break
+		}
+
+		stop := make(chan struct{})
+		stopped := make(chan struct{})
+		go func() {
+			defer close(stopped)
+			r.keepLocked(db, *job, stop)
+		}()
+
+		runErr := r.runJob(db, *job)
+		close(stop)
+		<-stopped
+
+		if runErr == nil {
+			succeeded++
+		}
+		if err := r.finishJob(db, *job, runErr); err != nil {
+			return succeeded, err
+		}
+	}
+
+	return succeeded, nil
+}
+
+// claimJob locks the oldest due job with SKIP LOCKED and marks it running,
+// so concurrent workers never pick the same run
+func (r *JobRunner) claimJob(db *gorm.DB) (*models.Job, error) {
+	var claimed *models.Job
+
+	err := db.Transaction(func(tx *gorm.DB) error {
+		var job models.Job
+		result := tx.Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+			Where("status = ? AND run_at <= ?", models.JobStatusQueued, time.Now()).
+			Where("name NOT IN (?)", tx.Model(&models.ScheduledJob{}).Select("name").Where("paused = ?", true)).
+			Order("run_at asc").
+			Limit(1).
+			Find(&job)
+		if result.Error != nil || result.RowsAffected == 0 {
+			return result.Error
+		}
+
+		now := time.Now()
+		job.Status = models.JobStatusRunning
+		job.Attempts++
+		job.LockedAt = &now
+		err := tx.Model(&job).Updates(map[string]interface{}{
+			"status":    job.Status,
+			"attempts":  job.Attempts,
+			"locked_at": now,
+		}).Error
+		if err != nil {
+			return err
+		}
+
+		claimed = &job
+		return nil
+	})
+
+	return claimed, err
+}
+
+// keepLocked renews the run's lock until stop is closed, so a slow run is
+// not mistaken for one abandoned by a crashed worker
+func (r *JobRunner) keepLocked(db *gorm.DB, job models.Job, stop <-chan struct{}) {
+	ticker := time.NewTicker(r.heartbeat)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+			result := db.Model(&models.Job{}).
+				Where("id = ? AND status = ?", job.ID, models.JobStatusRunning).
+				Update("locked_at", time.Now())
+			if result.Error != nil {
+				log.Printf("Failed to renew the lock of job %s (%s): %v", job.Name, job.ID, result.Error)
+				continue
+			}
+			if result.RowsAffected == 0 {
+				log.Printf("Job %s (%s) lost its lock while running", job.Name, job.ID)
+				return
+			}
+		}
+	}
+}
+
+func (r *JobRunner) runJob(db *gorm.DB, job models.Job) (err error) {
+	registered, ok := r.job(job.Name)
+	if !ok {
+		return errors.New("job is not registered")
+	}
+
+	defer func() {
+		if recovered := recover(); recovered != nil {
+			err = fmt.Errorf("panic: %v", recovered)
+		}
+	}()
+
+	return registered.handler(db, job)
+}
+
+func (r *JobRunner) finishJob(db *gorm.DB, job models.Job, runErr error) error {
+	now := time.Now()
+	updates := map[string]interface{}{"locked_at": nil}
+
+	switch {
+	case runErr == nil:
+		updates["status"] = models.JobStatusSucceeded
+		updates["finished_at"] = now
+		updates["last_error"] = ""
+	case job.Attempts >= job.MaxAttempts:
+		updates["status"] = models.JobStatusFailed
+		updates["finished_at"] = now
+		updates["last_error"] = runErr.Error()
+		log.Printf("Job %s (%s) failed after %d attempts: %v", job.Name, job.ID, job.Attempts, runErr)
+	default:
+		updates["status"] = models.JobStatusQueued
+		updates["run_at"] = now.Add(JobBackoff(job.Attempts))
+		updates["last_error"] = runErr.Error()
+	}
+
+	if err := db.Model(&job).Updates(updates).Error; err != nil {
+		return err
+	}
+
+	if updates["status"] == models.JobStatusQueued {
+		return nil
+	}
+
+	return db.Model(&models.ScheduledJob{}).Where("name = ?", job.Name).Updates(map[string]interface{}{
+		"last_run_at": now,
+		"last_status": updates["status"],
+		"last_error":  updates["last_error"],
+	}).Error
+}
+
+func (r *JobRunner) GetScheduledJobs(db *gorm.DB) ([]models.ScheduledJob, error) {
+	var jobs []models.ScheduledJob
+
+	result := db.Where("name IN ?", r.names()).Order("name asc").Find(&jobs)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+
+	return jobs, nil
+}
+
+func (r *JobRunner) GetJobRuns(db *gorm.DB, name string, pagination utils.PaginationParams) (utils.PaginationResponse, error) {
+	var runs []models.Job
+	var total int64
+
+	query := db.Model(&models.Job{})
+	if name != "" {
+		query = query.Where("name = ?", name)
+	}
+
+	if err := query.Count(&total).Error; err != nil {
+		return utils.PaginationResponse{}, err
+	}
+
+	result := query.Order("created_at desc").Offset(pagination.Offset).Limit(pagination.Limit).Find(&runs)
+	if result.Error != nil {
+		return utils.PaginationResponse{}, result.Error
+	}
+
+	return utils.CreatePaginationResponse(runs, total, pagination), nil
+}
+
+// TriggerJob queues an immediate run outside the job's schedule
+func (r *JobRunner) TriggerJob(db *gorm.DB, name string, triggeredBy uuid.UUID) (*models.Job, error) {
+	registered, ok := r.job(name)
+	if !ok {
+		return nil, errors.New("job not found")
+	}
+
+	var scheduled models.ScheduledJob
+	if err := db.Where("name = ?", name).Limit(1).Find(&scheduled).Error; err != nil {
+		return nil, err
+	}
+	if scheduled.Paused {
+		return nil, errors.New("job is paused")
+	}
+
+	run := newJobRun(name, registered.maxAttempts, time.Now(), &triggeredBy)
+	if err := db.Create(run).Error; err != nil {
+		return nil, err
+	}
+
+	return run, nil
+}
+
+// SetJobPaused stops or restarts scheduled runs of a job; queued runs of a
+// paused job stay in the queue until it is resumed
+func (r *JobRunner) SetJobPaused(db *gorm.DB, name string, paused bool) (*models.ScheduledJob, error) {
+	registered, ok := r.job(name)
+	if !ok {
+		return nil, errors.New("job not found")
+	}
+
+	var scheduled models.ScheduledJob
+	result := db.Where("name = ?", name).First(&scheduled)
+	if result.Error != nil {
+		if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+			return nil, errors.New("job not found")
+		}
+		return nil, result.Error
+	}
+
+	updates := map[string]interface{}{"paused": paused}
+	if !paused {
+		// Do not catch up on every run missed while paused
+		updates["next_run_at"] = registered.schedule.Next(time.Now())
+	}
+
+	if err := db.Model(&scheduled).Updates(updates).Error; err != nil {
+		return nil, err
+	}
+
+	return &scheduled, nil
+}
+
+// PurgeFinishedJobs removes succeeded and failed runs older than before
+func (r *JobRunner) PurgeFinishedJobs(db *gorm.DB, before time.Time) error {
+	return db.Where("status IN ? AND finished_at < ?", []string{models.JobStatusSucceeded, models.JobStatusFailed}, before).
+		Delete(&models.Job{}).Error
+}
+
+func newJobRun(name string, maxAttempts int, runAt time.Time, triggeredBy *uuid.UUID) *models.Job {
+	return &models.Job{
+		ID:          uuid.Must(uuid.NewV4()),
+		Name:        name,
+		Status:      models.JobStatusQueued,
+		MaxAttempts: maxAttempts,
+		RunAt:       runAt,
+		TriggeredBy: triggeredBy,
+	}
+}
+
+// tryAdvisoryXactLock takes a Postgres advisory lock for the rest of the
+// transaction; other databases have a single instance and always succeed
+func tryAdvisoryXactLock(tx *gorm.DB, key int64) (bool, error) {
+	if tx.Dialector.Name() != "postgres" {
+		return true, nil
+	}
+
+	var locked bool
+	err := tx.Raw("SELECT pg_try_advisory_xact_lock(?)", key).Scan(&locked).Error
+	return locked, err
+}
+
+func JobBackoff(attempts int) time.Duration {
+	backoff := 30 * time.Second
+	for i := 1; i < attempts; i++ {
+		backoff *= 2
+		if backoff >= maxJobBackoff {
+			return maxJobBackoff
+		}
+	}
+	return backoff
+}
+
+// Run syncs the registered schedules and then polls for due jobs until the
+// process exits
+func (r *JobRunner) Run(db *gorm.DB, interval time.Duration) {
+	if err := r.SyncSchedules(db, time.Now()); err != nil {
+		log.Printf("Failed to sync job schedules: %v", err)
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		if _, err := r.ScheduleDueJobs(db, time.Now()); err != nil {
+			log.Printf("Failed to schedule jobs: %v", err)
+		}
+
+		if _, err := r.ProcessJobs(db, 10); err != nil {
+			log.Printf("Failed to process jobs: %v", err)
+		}
+	}
+}
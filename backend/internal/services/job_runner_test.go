@@ -0,0 +1,48 @@
+package services
+
+import (
+	"task-manager/backend/internal/models"
+	"testing"
+	"time"
+
+	"github.com/gofrs/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+func TestJobRunner_RenewsLockWhileRunning(t *testing.T) {
+	// Shared cache, since the heartbeat writes while the handler runs
+	db := openTestDB("file:" + t.Name() + "?mode=memory&cache=shared")
+	require.NoError(t, db.AutoMigrate(&models.Job{}, &models.ScheduledJob{}))
+
+	runner := NewJobRunner()
+	runner.heartbeat = 10 * time.Millisecond
+
+	var claimedAt, renewedAt time.Time
+	runner.Register("digest", "@hourly", 1, func(db *gorm.DB, job models.Job) error {
+		claimedAt = *job.LockedAt
+		time.Sleep(100 * time.Millisecond)
+
+		var running models.Job
+		if err := db.First(&running, "id = ?", job.ID).Error; err != nil {
+			return err
+		}
+		renewedAt = *running.LockedAt
+		return nil
+	})
+	require.NoError(t, runner.SyncSchedules(db, time.Now()))
+
+	run, err := runner.TriggerJob(db, "digest", uuid.Nil)
+	require.NoError(t, err)
+
+	succeeded, err := runner.ProcessJobs(db, 1)
+	require.NoError(t, err)
+	assert.Equal(t, 1, succeeded)
+	assert.True(t, renewedAt.After(claimedAt), "lock was not renewed")
+
+	var finished models.Job
+	require.NoError(t, db.First(&finished, "id = ?", run.ID).Error)
+	assert.Equal(t, models.JobStatusSucceeded, finished.Status)
+	assert.Nil(t, finished.LockedAt)
+}
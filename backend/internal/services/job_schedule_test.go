@@ -0,0 +1,58 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseSchedule_Next(t *testing.T) {
+	// Saturday
+	from := time.Date(2024, 3, 16, 10, 7, 30, 0, time.UTC)
+
+	cases := []struct {
+		spec     string
+		expected time.Time
+	}{
+		{"*/5 * * * *", time.Date(2024, 3, 16, 10, 10, 0, 0, time.UTC)},
+		{"0 * * * *", time.Date(2024, 3, 16, 11, 0, 0, 0, time.UTC)},
+		{"@hourly", time.Date(2024, 3, 16, 11, 0, 0, 0, time.UTC)},
+		{"30 3 * * *", time.Date(2024, 3, 17, 3, 30, 0, 0, time.UTC)},
+		{"0 9 * * 1-5", time.Date(2024, 3, 18, 9, 0, 0, 0, time.UTC)},
+		{"0 0 1 * *", time.Date(2024, 4, 1, 0, 0, 0, 0, time.UTC)},
+		{"0 12 29 2 *", time.Date(2028, 2, 29, 12, 0, 0, 0, time.UTC)},
+		{"15,45 10 * * 7", time.Date(2024, 3, 17, 10, 15, 0, 0, time.UTC)},
+		{"@every 90s", from.Add(90 * time.Second)},
+	}
+
+	for _, tc := range cases {
+		schedule, err := ParseSchedule(tc.spec)
+		require.NoError(t, err, tc.spec)
+		assert.Equal(t, tc.expected, schedule.Next(from), tc.spec)
+	}
+}
+
+func TestParseSchedule_DayFieldsAreOred(t *testing.T) {
+	// The 20th, or any Monday
+	schedule, err := ParseSchedule("0 0 20 * 1")
+	require.NoError(t, err)
+
+	from := time.Date(2024, 3, 16, 0, 0, 0, 0, time.UTC)
+	assert.Equal(t, time.Date(2024, 3, 18, 0, 0, 0, 0, time.UTC), schedule.Next(from))
+	assert.Equal(t, time.Date(2024, 3, 20, 0, 0, 0, 0, time.UTC), schedule.Next(time.Date(2024, 3, 18, 0, 0, 0, 0, time.UTC)))
+}
+
+func TestParseSchedule_Invalid(t *testing.T) {
+	for _, spec := range []string{"", "* * * *", "60 * * * *", "* 24 * * *", "*/0 * * * *", "5-1 * * * *", "@every 10ms", "@every soon"} {
+		_, err := ParseSchedule(spec)
+		assert.Error(t, err, spec)
+	}
+}
+
+func TestJobBackoff(t *testing.T) {
+	assert.Equal(t, 30*time.Second, JobBackoff(1))
+	assert.Equal(t, 2*time.Minute, JobBackoff(3))
+	assert.Equal(t, time.Hour, JobBackoff(10))
+}
@@ -0,0 +1,62 @@
+package services
+
+import (
+	"task-manager/backend/internal/models"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// EmailDigestJob builds the hourly and daily digests that are due
+func EmailDigestJob(emailService EmailService) JobHandler {
+	return func(db *gorm.DB, job models.Job) error {
+		now := time.Now()
+		for _, mode := range []string{models.EmailModeHourly, models.EmailModeDaily} {
+			if err := emailService.SendDigests(db, mode, now); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+}
+
+// OutboxCleanupJob drops domain events that every subscriber has handled
+func OutboxCleanupJob(retention time.Duration) JobHandler {
+	return func(db *gorm.DB, job models.Job) error {
+		return PurgeProcessedEvents(db, time.Now().Add(-retention))
+	}
+}
+
+// JobHistoryCleanupJob drops finished job runs
+func JobHistoryCleanupJob(runner *JobRunner, retention time.Duration) JobHandler {
+	return func(db *gorm.DB, job models.Job) error {
+		return runner.PurgeFinishedJobs(db, time.Now().Add(-retention))
+	}
+}
+
+// TokenCleanupJob removes expired and revoked refresh tokens and the
+// revocation entries of access tokens that have expired
+func TokenCleanupJob(authService AuthService, revocations *RevocationStore) JobHandler {
+	return func(db *gorm.DB, job models.Job) error {
+		now := time.Now()
+		if _, err := authService.PurgeExpiredTokens(db, now); err != nil {
+			return err
+		}
+		return revocations.PurgeExpired(db, now)
+	}
+}
+
+// LoginAttemptCleanupJob drops login history older than the retention
+func LoginAttemptCleanupJob(loginGuard LoginGuardService, retention time.Duration) JobHandler {
+	return func(db *gorm.DB, job models.Job) error {
+		return loginGuard.PurgeLoginAttempts(db, time.Now().Add(-retention))
+	}
+}
+
+// AccountDeletionJob deletes the accounts whose deletion grace period is over
+func AccountDeletionJob(userService UserService) JobHandler {
+	return func(db *gorm.DB, job models.Job) error {
+		_, err := userService.DeleteScheduledUsers(db, time.Now())
+		return err
+	}
+}
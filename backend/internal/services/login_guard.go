@@ -0,0 +1,233 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"task-manager/backend/internal/models"
+	"task-manager/backend/internal/utils"
+	"time"
+
+	"github.com/gofrs/uuid"
+	"gorm.io/gorm"
+)
+
+// LoginGuardService slows down and locks out password guessing per account,
+// whichever IP addresses the guesses come from, and keeps the login history
+type LoginGuardService interface {
+	Blocked(user *models.User, now time.Time) bool
+	RecordFailure(db *gorm.DB, user *models.User, username string, client ClientInfo) error
+	RecordSuccess(db *gorm.DB, user *models.User, client ClientInfo) error
+	Unlock(db *gorm.DB, userID, adminID uuid.UUID) error
+	GetLoginAttempts(db *gorm.DB, userID uuid.UUID, pagination utils.PaginationParams) (utils.PaginationResponse, error)
+	PurgeLoginAttempts(db *gorm.DB, before time.Time) error
+}
+
+type LoginGuardServiceImpl struct {
+	// FreeAttempts failures in a row are allowed before delays start
+	FreeAttempts int
+	// BaseDelay doubles with every further failure up to MaxDelay
+	BaseDelay time.Duration
+	MaxDelay  time.Duration
+	// LockoutThreshold failures in a row lock the account for LockoutDuration
+	LockoutThreshold int
+	LockoutDuration  time.Duration
+}
+
+func NewLoginGuardService() *LoginGuardServiceImpl {
+	return &LoginGuardServiceImpl{
+		FreeAttempts:     utils.GetEnvAsInt("LOGIN_FREE_ATTEMPTS", 3),
+		BaseDelay:        time.Second,
+		MaxDelay:         utils.GetEnvAsDuration("LOGIN_MAX_DELAY", 30*time.Second),
+		LockoutThreshold: utils.GetEnvAsInt("LOGIN_LOCKOUT_THRESHOLD", 10),
+		LockoutDuration:  utils.GetEnvAsDuration("LOGIN_LOCKOUT_DURATION", 15*time.Minute),
+	}
+}
+
+// Delay is how long the account stays blocked after the given number of
+// failures in a row
+func (s *LoginGuardServiceImpl) Delay(failures int) time.Duration {
+	if failures >= s.LockoutThreshold {
+		return s.LockoutDuration
+	}
+	if failures <= s.FreeAttempts {
+		return 0
+	}
+
+	delay := s.BaseDelay
+	for i := s.FreeAttempts + 1; i < failures && delay < s.MaxDelay; i++ {
+		delay *= 2
+	}
+	if delay > s.MaxDelay {
+		delay = s.MaxDelay
+	}
+	return delay
+}
+
+func (s *LoginGuardServiceImpl) Blocked(user *models.User, now time.Time) bool {
+	return user.LockedUntil != nil && user.LockedUntil.After(now)
+}
+
+// RecordFailure logs the attempt and, unless the account is already
+// blocked, counts it towards the next delay or lockout. Attempts made while
+// blocked do not count, so they cannot keep an account locked forever.
+func (s *LoginGuardServiceImpl) RecordFailure(db *gorm.DB, user *models.User, username string, client ClientInfo) error {
+	now := time.Now()
+	return db.Transaction(func(tx *gorm.DB) error {
+		var userID *uuid.UUID
+		if user != nil {
+			userID = &user.ID
+		}
+		if err := recordLoginAttempt(tx, userID, username, false, client); err != nil {
+			return err
+		}
+		if user == nil || s.Blocked(user, now) {
+			return nil
+		}
+
+		if err := tx.Model(&models.User{}).Where("id = ?", user.ID).
+			Update("failed_login_attempts", gorm.Expr("failed_login_attempts + 1")).Error; err != nil {
+			return err
+		}
+		var failures int
+		if err := tx.Model(&models.User{}).Where("id = ?", user.ID).Select("failed_login_attempts").Scan(&failures).Error; err != nil {
+			return err
+		}
+
+		delay := s.Delay(failures)
+		if delay == 0 {
+			return nil
+		}
+		lockedUntil := now.Add(delay)
+		updates := map[string]interface{}{"locked_until": lockedUntil}
+		if failures < s.LockoutThreshold {
+			return tx.Model(&models.User{}).Where("id = ?", user.ID).Updates(updates).Error
+		}
+
+		// A lockout starts the count afresh once it is over
+		updates["failed_login_attempts"] = 0
+		if err := tx.Model(&models.User{}).Where("id = ?", user.ID).Updates(updates).Error; err != nil {
+			return err
+		}
+		return RecordSecurityEvent(tx, models.SecurityEvent{
+			UserID:    userID,
+			Type:      models.SecurityEventAccountLocked,
+			IPAddress: client.IPAddress,
+			UserAgent: client.UserAgent,
+			Details:   fmt.Sprintf("%d failed logins in a row; locked until %s", failures, lockedUntil.UTC().Format(time.RFC3339)),
+		})
+	})
+}
+
+// RecordSuccess logs the login, clears the failure count and flags logins
+// from an IP address or device the user has not signed in from before
+func (s *LoginGuardServiceImpl) RecordSuccess(db *gorm.DB, user *models.User, client ClientInfo) error {
+	device := DescribeDevice(client.UserAgent)
+	return db.Transaction(func(tx *gorm.DB) error {
+		history := tx.Model(&models.LoginAttempt{}).Where("user_id = ? AND success = ?", user.ID, true)
+
+		var previous int64
+		if err := history.Session(&gorm.Session{}).Count(&previous).Error; err != nil {
+			return err
+		}
+		// Nothing is unfamiliar on the very first login
+		if previous > 0 {
+			var sameIP, sameDevice int64
+			if err := history.Session(&gorm.Session{}).Where("ip_address = ?", client.IPAddress).Count(&sameIP).Error; err != nil {
+				return err
+			}
+			if err := history.Session(&gorm.Session{}).Where("device = ?", device).Count(&sameDevice).Error; err != nil {
+				return err
+			}
+
+			if sameIP == 0 {
+				if err := recordLoginAnomaly(tx, user.ID, models.SecurityEventNewLoginIP, client, "login from new IP address "+client.IPAddress); err != nil {
+					return err
+				}
+			}
+			if sameDevice == 0 {
+				if err := recordLoginAnomaly(tx, user.ID, models.SecurityEventNewLoginDevice, client, "login from new device "+device); err != nil {
+					return err
+				}
+			}
+		}
+
+		if user.FailedLoginAttempts > 0 || user.LockedUntil != nil {
+			if err := tx.Model(&models.User{}).Where("id = ?", user.ID).Updates(map[string]interface{}{
+				"failed_login_attempts": 0,
+				"locked_until":          nil,
+			}).Error; err != nil {
+				return err
+			}
+		}
+
+		return recordLoginAttempt(tx, &user.ID, user.Username, true, client)
+	})
+}
+
+// Unlock lifts a delay or lockout before it runs out
+func (s *LoginGuardServiceImpl) Unlock(db *gorm.DB, userID, adminID uuid.UUID) error {
+	return db.Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&models.User{}).Where("id = ?", userID).Updates(map[string]interface{}{
+			"failed_login_attempts": 0,
+			"locked_until":          nil,
+		})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return errors.New("user not found")
+		}
+
+		return RecordSecurityEvent(tx, models.SecurityEvent{
+			UserID:  &userID,
+			Type:    models.SecurityEventAccountUnlocked,
+			Details: "unlocked by admin " + adminID.String(),
+		})
+	})
+}
+
+// GetLoginAttempts returns the user's logins, newest first
+func (s *LoginGuardServiceImpl) GetLoginAttempts(db *gorm.DB, userID uuid.UUID, pagination utils.PaginationParams) (utils.PaginationResponse, error) {
+	var attempts []models.LoginAttempt
+	var total int64
+
+	query := db.Model(&models.LoginAttempt{}).Where("user_id = ?", userID)
+	if err := query.Count(&total).Error; err != nil {
+		return utils.PaginationResponse{}, err
+	}
+
+	result := query.Order("created_at desc").Offset(pagination.Offset).Limit(pagination.Limit).Find(&attempts)
+	if result.Error != nil {
+		return utils.PaginationResponse{}, result.Error
+	}
+
+	return utils.CreatePaginationResponse(attempts, total, pagination), nil
+}
+
+func (s *LoginGuardServiceImpl) PurgeLoginAttempts(db *gorm.DB, before time.Time) error {
+	return db.Where("created_at < ?", before).Delete(&models.LoginAttempt{}).Error
+}
+
+func recordLoginAttempt(db *gorm.DB, userID *uuid.UUID, username string, success bool, client ClientInfo) error {
+	attempt := models.LoginAttempt{
+		ID:        uuid.Must(uuid.NewV4()),
+		UserID:    userID,
+		Username:  strings.ToLower(strings.TrimSpace(username)),
+		Success:   success,
+		IPAddress: client.IPAddress,
+		UserAgent: client.UserAgent,
+		Device:    DescribeDevice(client.UserAgent),
+	}
+	return db.Create(&attempt).Error
+}
+
+func recordLoginAnomaly(db *gorm.DB, userID uuid.UUID, eventType string, client ClientInfo, details string) error {
+	return RecordSecurityEvent(db, models.SecurityEvent{
+		UserID:    &userID,
+		Type:      eventType,
+		IPAddress: client.IPAddress,
+		UserAgent: client.UserAgent,
+		Details:   details,
+	})
+}
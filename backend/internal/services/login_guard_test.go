@@ -0,0 +1,26 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLoginGuard_Delay(t *testing.T) {
+	guard := &LoginGuardServiceImpl{
+		FreeAttempts:     3,
+		BaseDelay:        time.Second,
+		MaxDelay:         10 * time.Second,
+		LockoutThreshold: 8,
+		LockoutDuration:  15 * time.Minute,
+	}
+
+	expected := []time.Duration{0, 0, 0, 0, time.Second, 2 * time.Second, 4 * time.Second, 8 * time.Second, 15 * time.Minute, 15 * time.Minute}
+	for failures, delay := range expected {
+		assert.Equal(t, delay, guard.Delay(failures), "after %d failures", failures)
+	}
+
+	guard.LockoutThreshold = 20
+	assert.Equal(t, 10*time.Second, guard.Delay(12))
+}
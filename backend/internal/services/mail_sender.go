@@ -0,0 +1,131 @@
+package services
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"log"
+	"mime"
+	"mime/quotedprintable"
+	"net"
+	"net/mail"
+	"net/smtp"
+	"task-manager/backend/internal/utils"
+	"time"
+)
+
+// MailMessage is a rendered email with plain text and HTML alternatives
+type MailMessage struct {
+	To       string
+	Subject  string
+	TextBody string
+	HTMLBody string
+}
+
+type MailSender interface {
+	Send(msg MailMessage) error
+}
+
+// NewMailSender picks the sender from MAIL_SENDER: "smtp" (default) or
+// "log", which writes messages to the log instead of sending them
+func NewMailSender() MailSender {
+	if utils.GetEnv("MAIL_SENDER", "smtp") == "log" {
+		return NewLogSender(log.Default())
+	}
+	return NewSMTPSender(NewSMTPConfig())
+}
+
+type SMTPConfig struct {
+	Host     string
+	Port     string
+	Username string
+	Password string
+	From     string
+}
+
+func NewSMTPConfig() *SMTPConfig {
+	return &SMTPConfig{
+		Host:     utils.GetEnv("SMTP_HOST", "localhost"),
+		Port:     utils.GetEnv("SMTP_PORT", "1025"),
+		Username: utils.GetEnv("SMTP_USERNAME", ""),
+		Password: utils.GetEnv("SMTP_PASSWORD", ""),
+		From:     utils.GetEnv("SMTP_FROM", "Taskify <no-reply@taskify.local>"),
+	}
+}
+
+type SMTPSender struct {
+	config *SMTPConfig
+}
+
+func NewSMTPSender(config *SMTPConfig) *SMTPSender {
+	return &SMTPSender{config: config}
+}
+
+func (s *SMTPSender) Send(msg MailMessage) error {
+	from, err := mail.ParseAddress(s.config.From)
+	if err != nil {
+		return fmt.Errorf("invalid sender address: %w", err)
+	}
+
+	// Local stand-ins such as MailHog accept mail without authentication
+	var auth smtp.Auth
+	if s.config.Username != "" {
+		auth = smtp.PlainAuth("", s.config.Username, s.config.Password, s.config.Host)
+	}
+
+	addr := net.JoinHostPort(s.config.Host, s.config.Port)
+	return smtp.SendMail(addr, auth, from.Address, []string{msg.To}, BuildMIMEMessage(s.config.From, msg))
+}
+
+// LogSender prints emails instead of sending them, for local development
+// without a mail server
+type LogSender struct {
+	logger *log.Logger
+}
+
+func NewLogSender(logger *log.Logger) *LogSender {
+	return &LogSender{logger: logger}
+}
+
+func (s *LogSender) Send(msg MailMessage) error {
+	s.logger.Printf("Email to %s: %s\n%s", msg.To, msg.Subject, msg.TextBody)
+	return nil
+}
+
+// BuildMIMEMessage renders msg as a multipart/alternative message so clients
+// can pick between the text and HTML bodies
+func BuildMIMEMessage(from string, msg MailMessage) []byte {
+	boundary := randomBoundary()
+
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "From: %s\r\n", from)
+	fmt.Fprintf(&buf, "To: %s\r\n", msg.To)
+	fmt.Fprintf(&buf, "Subject: %s\r\n", mime.QEncoding.Encode("utf-8", msg.Subject))
+	fmt.Fprintf(&buf, "Date: %s\r\n", time.Now().Format(time.RFC1123Z))
+	buf.WriteString("MIME-Version: 1.0\r\n")
+	fmt.Fprintf(&buf, "Content-Type: multipart/alternative; boundary=%q\r\n\r\n", boundary)
+
+	writePart(&buf, boundary, "text/plain", msg.TextBody)
+	writePart(&buf, boundary, "text/html", msg.HTMLBody)
+
+	fmt.Fprintf(&buf, "--%s--\r\n", boundary)
+	return buf.Bytes()
+}
+
+func writePart(buf *bytes.Buffer, boundary, contentType, body string) {
+	fmt.Fprintf(buf, "--%s\r\n", boundary)
+	fmt.Fprintf(buf, "Content-Type: %s; charset=utf-8\r\n", contentType)
+	buf.WriteString("Content-Transfer-Encoding: quoted-printable\r\n\r\n")
+
+	w := quotedprintable.NewWriter(buf)
+	w.Write([]byte(body))
+	w.Close()
+	buf.WriteString("\r\n")
+}
+
+func randomBoundary() string {
+	b := make([]byte, 16)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
@@ -0,0 +1,121 @@
+package services
+
+import (
+	"bufio"
+	"bytes"
+	"log"
+	"net"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// startFakeSMTPServer accepts a single message and hands its DATA section to
+// the returned channel
+func startFakeSMTPServer(t *testing.T) (string, string, <-chan string) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("failed to listen: %v", err)
+	}
+	t.Cleanup(func() { listener.Close() })
+
+	messages := make(chan string, 1)
+
+	go func() {
+		conn, err := listener.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+
+		reader := bufio.NewReader(conn)
+		write := func(line string) { conn.Write([]byte(line + "\r\n")) }
+
+		write("220 localhost ESMTP fake")
+		for {
+			line, err := reader.ReadString('\n')
+			if err != nil {
+				return
+			}
+			command := strings.ToUpper(strings.TrimSpace(line))
+
+			switch {
+			case strings.HasPrefix(command, "EHLO"), strings.HasPrefix(command, "HELO"):
+				write("250 localhost")
+			case strings.HasPrefix(command, "DATA"):
+				write("354 end with .")
+				var data strings.Builder
+				for {
+					dataLine, err := reader.ReadString('\n')
+					if err != nil {
+						return
+					}
+					if dataLine == ".\r\n" {
+						break
+					}
+					data.WriteString(dataLine)
+				}
+				messages <- data.String()
+				write("250 queued")
+			case strings.HasPrefix(command, "QUIT"):
+				write("221 bye")
+				return
+			default:
+				write("250 ok")
+			}
+		}
+	}()
+
+	host, port, _ := net.SplitHostPort(listener.Addr().String())
+	return host, port, messages
+}
+
+func TestSMTPSender_Send(t *testing.T) {
+	host, port, messages := startFakeSMTPServer(t)
+
+	sender := NewSMTPSender(&SMTPConfig{
+		Host: host,
+		Port: port,
+		From: "Taskify <no-reply@taskify.local>",
+	})
+
+	err := sender.Send(MailMessage{
+		To:       "owner@example.com",
+		Subject:  "Task \"Write docs\" was updated",
+		TextBody: "Plain text body",
+		HTMLBody: "<p>HTML body</p>",
+	})
+	assert.NoError(t, err)
+
+	data := <-messages
+	assert.Contains(t, data, "To: owner@example.com")
+	assert.Contains(t, data, "multipart/alternative")
+	assert.Contains(t, data, "Content-Type: text/plain; charset=utf-8")
+	assert.Contains(t, data, "Plain text body")
+	assert.Contains(t, data, "Content-Type: text/html; charset=utf-8")
+	assert.Contains(t, data, "<p>HTML body</p>")
+}
+
+func TestLogSender_Send(t *testing.T) {
+	var buf bytes.Buffer
+	sender := NewLogSender(log.New(&buf, "", 0))
+
+	err := sender.Send(MailMessage{To: "owner@example.com", Subject: "Reset your password", TextBody: "Open the link"})
+	assert.NoError(t, err)
+	assert.Equal(t, "Email to owner@example.com: Reset your password\nOpen the link\n", buf.String())
+}
+
+func TestRenderTemplates_EscapesHTML(t *testing.T) {
+	text, html, err := renderTemplates(notificationText, notificationHTML, notificationEmailData{
+		Username: "alice",
+		AppURL:   "http://localhost:3000",
+	})
+	assert.NoError(t, err)
+	assert.Contains(t, text, "Hi alice")
+	assert.Contains(t, html, "Hi alice")
+
+	_, html, err = renderTemplates(digestText, digestHTML, digestEmailData{Username: "<script>"})
+	assert.NoError(t, err)
+	assert.NotContains(t, html, "<script>")
+}
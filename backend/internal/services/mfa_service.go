@@ -0,0 +1,482 @@
+package services
+
+import (
+	"crypto/rand"
+	"encoding/base32"
+	"errors"
+	"strings"
+	"task-manager/backend/internal/models"
+	"task-manager/backend/internal/utils"
+	"time"
+
+	"github.com/gofrs/uuid"
+	"gorm.io/gorm"
+)
+
+const (
+	// mfaChallengeLifetime is how long a password login waits for its code
+	mfaChallengeLifetime = 5 * time.Minute
+	// maxMFAAttempts wrong codes end the challenge; the user has to log in again
+	maxMFAAttempts     = 5
+	recoveryCodeCount  = 10
+	recoveryCodeLength = 16
+)
+
+// MFAChallengeToken is handed out by a password login that still needs a
+// second factor
+type MFAChallengeToken struct {
+	Token              string `json:"mfa_token"`
+	EnrollmentRequired bool   `json:"mfa_enrollment_required"`
+	ExpiresIn          int    `json:"expires_in"`
+}
+
+type MFAService interface {
+	GetStatus(db *gorm.DB, userID uuid.UUID) (*models.MFAStatus, error)
+	BeginEnrollment(db *gorm.DB, userID uuid.UUID) (*models.MFAEnrollment, error)
+	ConfirmEnrollment(db *gorm.DB, userID uuid.UUID, code string) ([]string, error)
+	Disable(db *gorm.DB, userID uuid.UUID, code string) error
+	RegenerateRecoveryCodes(db *gorm.DB, userID uuid.UUID, code string) ([]string, error)
+	ResetMFA(db *gorm.DB, userID uuid.UUID) error
+	LoginChallenge(db *gorm.DB, userID uuid.UUID) (*MFAChallengeToken, error)
+	BeginChallengeEnrollment(db *gorm.DB, challengeToken string) (*models.MFAEnrollment, error)
+	VerifyChallenge(db *gorm.DB, challengeToken, code string) (uuid.UUID, []string, error)
+	SetRoleRequirement(db *gorm.DB, roleID uuid.UUID, required bool) (*models.Role, error)
+}
+
+type MFAServiceImpl struct {
+	issuer string
+}
+
+func NewMFAService() *MFAServiceImpl {
+	return &MFAServiceImpl{issuer: utils.GetEnv("MFA_ISSUER", "Taskify")}
+}
+
+func (s *MFAServiceImpl) GetStatus(db *gorm.DB, userID uuid.UUID) (*models.MFAStatus, error) {
+	mfa, err := s.findMFA(db, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	required, err := s.isRequired(db, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	status := &models.MFAStatus{Required: required}
+	if mfa != nil && mfa.Enabled {
+		status.Enabled = true
+		status.ConfirmedAt = mfa.ConfirmedAt
+		if err := db.Model(&models.MFARecoveryCode{}).Where("user_id = ? AND used_at IS NULL", userID).Count(&status.RecoveryCodesRemaining).Error; err != nil {
+			return nil, err
+		}
+	}
+	return status, nil
+}
+
+// BeginEnrollment creates a new secret for the user. Starting over replaces
+// an unconfirmed secret; an enabled one has to be disabled first.
+func (s *MFAServiceImpl) BeginEnrollment(db *gorm.DB, userID uuid.UUID) (*models.MFAEnrollment, error) {
+	mfa, err := s.findMFA(db, userID)
+	if err != nil {
+		return nil, err
+	}
+	if mfa != nil && mfa.Enabled {
+		return nil, errors.New("two-factor authentication is already enabled")
+	}
+
+	var user models.User
+	if err := db.First(&user, "id = ?", userID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("user not found")
+		}
+		return nil, err
+	}
+
+	secret, err := GenerateTOTPSecret()
+	if err != nil {
+		return nil, err
+	}
+
+	if mfa == nil {
+		err = db.Create(&models.UserMFA{UserID: userID, Secret: secret}).Error
+	} else {
+		err = db.Model(mfa).Updates(map[string]interface{}{"secret": secret, "last_used_step": 0}).Error
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &models.MFAEnrollment{
+		Secret:          secret,
+		ProvisioningURI: TOTPProvisioningURI(s.issuer, user.Username, secret),
+	}, nil
+}
+
+// ConfirmEnrollment turns 2FA on once the user proves their app produces
+// the right codes, and returns the recovery codes to show them once
+func (s *MFAServiceImpl) ConfirmEnrollment(db *gorm.DB, userID uuid.UUID, code string) ([]string, error) {
+	mfa, err := s.findMFA(db, userID)
+	if err != nil {
+		return nil, err
+	}
+	if mfa == nil {
+		return nil, errors.New("no enrollment in progress")
+	}
+	if mfa.Enabled {
+		return nil, errors.New("two-factor authentication is already enabled")
+	}
+
+	step, ok := ValidateTOTP(mfa.Secret, code, time.Now(), mfa.LastUsedStep)
+	if !ok {
+		return nil, errors.New("invalid code")
+	}
+
+	var codes []string
+	err = db.Transaction(func(tx *gorm.DB) error {
+		now := time.Now()
+		if err := tx.Model(mfa).Updates(map[string]interface{}{"enabled": true, "confirmed_at": now, "last_used_step": step}).Error; err != nil {
+			return err
+		}
+
+		codes, err = replaceRecoveryCodes(tx, userID)
+		if err != nil {
+			return err
+		}
+
+		uid := userID
+		return RecordSecurityEvent(tx, models.SecurityEvent{UserID: &uid, Type: models.SecurityEventMFAEnabled})
+	})
+	if err != nil {
+		return nil, err
+	}
+	return codes, nil
+}
+
+// Disable turns 2FA off after checking a current code. Users whose role
+// requires 2FA cannot turn it off.
+func (s *MFAServiceImpl) Disable(db *gorm.DB, userID uuid.UUID, code string) error {
+	required, err := s.isRequired(db, userID)
+	if err != nil {
+		return err
+	}
+	if required {
+		return errors.New("two-factor authentication is required for your role")
+	}
+
+	if err := s.checkCode(db, userID, code); err != nil {
+		return err
+	}
+
+	return db.Transaction(func(tx *gorm.DB) error {
+		if err := deleteMFA(tx, userID); err != nil {
+			return err
+		}
+		uid := userID
+		return RecordSecurityEvent(tx, models.SecurityEvent{UserID: &uid, Type: models.SecurityEventMFADisabled})
+	})
+}
+
+func (s *MFAServiceImpl) RegenerateRecoveryCodes(db *gorm.DB, userID uuid.UUID, code string) ([]string, error) {
+	if err := s.checkCode(db, userID, code); err != nil {
+		return nil, err
+	}
+
+	var codes []string
+	err := db.Transaction(func(tx *gorm.DB) error {
+		var err error
+		codes, err = replaceRecoveryCodes(tx, userID)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return codes, nil
+}
+
+// ResetMFA removes a user's 2FA for an admin, e.g. when the user lost both
+// their device and their recovery codes
+func (s *MFAServiceImpl) ResetMFA(db *gorm.DB, userID uuid.UUID) error {
+	return db.Transaction(func(tx *gorm.DB) error {
+		var count int64
+		if err := tx.Model(&models.UserMFA{}).Where("user_id = ?", userID).Count(&count).Error; err != nil {
+			return err
+		}
+		if count == 0 {
+			return errors.New("two-factor authentication is not enabled")
+		}
+
+		if err := deleteMFA(tx, userID); err != nil {
+			return err
+		}
+		uid := userID
+		return RecordSecurityEvent(tx, models.SecurityEvent{UserID: &uid, Type: models.SecurityEventMFADisabled, Details: "reset by an admin"})
+	})
+}
+
+// LoginChallenge decides whether a password login needs a second factor.
+// It returns nil when the user can be logged in straight away.
+func (s *MFAServiceImpl) LoginChallenge(db *gorm.DB, userID uuid.UUID) (*MFAChallengeToken, error) {
+	mfa, err := s.findMFA(db, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	enrollment := false
+	if mfa == nil || !mfa.Enabled {
+		required, err := s.isRequired(db, userID)
+		if err != nil {
+			return nil, err
+		}
+		if !required {
+			return nil, nil
+		}
+		enrollment = true
+	}
+
+	token, err := generateRefreshToken()
+	if err != nil {
+		return nil, err
+	}
+
+	// Expired challenges are cleared out as new ones are created
+	if err := db.Where("expires_at <= ?", time.Now()).Delete(&models.MFAChallenge{}).Error; err != nil {
+		return nil, err
+	}
+
+	challenge := models.MFAChallenge{
+		ID:         uuid.Must(uuid.NewV4()),
+		UserID:     userID,
+		TokenHash:  HashRefreshToken(token),
+		Enrollment: enrollment,
+		ExpiresAt:  time.Now().Add(mfaChallengeLifetime),
+	}
+	if err := db.Create(&challenge).Error; err != nil {
+		return nil, err
+	}
+
+	return &MFAChallengeToken{Token: token, EnrollmentRequired: enrollment, ExpiresIn: int(mfaChallengeLifetime.Seconds())}, nil
+}
+
+// BeginChallengeEnrollment lets a user whose role requires 2FA set it up
+// in the middle of logging in
+func (s *MFAServiceImpl) BeginChallengeEnrollment(db *gorm.DB, challengeToken string) (*models.MFAEnrollment, error) {
+	challenge, err := s.findChallenge(db, challengeToken)
+	if err != nil {
+		return nil, err
+	}
+	if !challenge.Enrollment {
+		return nil, errors.New("two-factor authentication is already enabled")
+	}
+	return s.BeginEnrollment(db, challenge.UserID)
+}
+
+// VerifyChallenge completes a login with a TOTP or recovery code and returns
+// the user to issue tokens for. Enrollment challenges confirm the new
+// secret and also return the fresh recovery codes.
+func (s *MFAServiceImpl) VerifyChallenge(db *gorm.DB, challengeToken, code string) (uuid.UUID, []string, error) {
+	challenge, err := s.findChallenge(db, challengeToken)
+	if err != nil {
+		return uuid.Nil, nil, err
+	}
+
+	// Use up an attempt before checking the code so parallel requests
+	// cannot try more codes than the limit
+	result := db.Model(&models.MFAChallenge{}).
+		Where("id = ? AND attempts < ?", challenge.ID, maxMFAAttempts).
+		Update("attempts", gorm.Expr("attempts + 1"))
+	if result.Error != nil {
+		return uuid.Nil, nil, result.Error
+	}
+	if result.RowsAffected == 0 {
+		if err := db.Where("id = ?", challenge.ID).Delete(&models.MFAChallenge{}).Error; err != nil {
+			return uuid.Nil, nil, err
+		}
+		return uuid.Nil, nil, errors.New("too many invalid codes")
+	}
+
+	mfa, err := s.findMFA(db, challenge.UserID)
+	if err != nil {
+		return uuid.Nil, nil, err
+	}
+
+	var recoveryCodes []string
+	if challenge.Enrollment && (mfa == nil || !mfa.Enabled) {
+		recoveryCodes, err = s.ConfirmEnrollment(db, challenge.UserID, code)
+	} else {
+		err = s.checkCode(db, challenge.UserID, code)
+	}
+
+	if err != nil {
+		if err.Error() == "invalid code" {
+			return uuid.Nil, nil, s.failChallenge(db, challenge)
+		}
+		return uuid.Nil, nil, err
+	}
+
+	// Each challenge completes one login
+	result = db.Where("id = ?", challenge.ID).Delete(&models.MFAChallenge{})
+	if result.Error != nil {
+		return uuid.Nil, nil, result.Error
+	}
+	if result.RowsAffected == 0 {
+		return uuid.Nil, nil, errors.New("invalid or expired mfa token")
+	}
+
+	return challenge.UserID, recoveryCodes, nil
+}
+
+func (s *MFAServiceImpl) SetRoleRequirement(db *gorm.DB, roleID uuid.UUID, required bool) (*models.Role, error) {
+	var role models.Role
+	if err := db.First(&role, "id = ?", roleID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("role not found")
+		}
+		return nil, err
+	}
+
+	if err := db.Model(&role).Update("require_mfa", required).Error; err != nil {
+		return nil, err
+	}
+	return &role, nil
+}
+
+// failChallenge ends the challenge when the wrong code used up its last
+// attempt. VerifyChallenge has already counted the attempt.
+func (s *MFAServiceImpl) failChallenge(db *gorm.DB, challenge *models.MFAChallenge) error {
+	if challenge.Attempts+1 >= maxMFAAttempts {
+		if err := db.Where("id = ?", challenge.ID).Delete(&models.MFAChallenge{}).Error; err != nil {
+			return err
+		}
+		return errors.New("too many invalid codes")
+	}
+	return errors.New("invalid code")
+}
+
+// checkCode accepts a current TOTP code or an unused recovery code
+func (s *MFAServiceImpl) checkCode(db *gorm.DB, userID uuid.UUID, code string) error {
+	mfa, err := s.findMFA(db, userID)
+	if err != nil {
+		return err
+	}
+	if mfa == nil || !mfa.Enabled {
+		return errors.New("two-factor authentication is not enabled")
+	}
+
+	if step, ok := ValidateTOTP(mfa.Secret, code, time.Now(), mfa.LastUsedStep); ok {
+		// Recording the step only if it moved forward keeps two concurrent
+		// logins from both using the same code
+		result := db.Model(&models.UserMFA{}).
+			Where("user_id = ? AND last_used_step < ?", userID, step).
+			Update("last_used_step", step)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 1 {
+			return nil
+		}
+		return errors.New("invalid code")
+	}
+
+	hash := HashRefreshToken(normalizeRecoveryCode(code))
+	result := db.Model(&models.MFARecoveryCode{}).
+		Where("user_id = ? AND code_hash = ? AND used_at IS NULL", userID, hash).
+		Update("used_at", time.Now())
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return errors.New("invalid code")
+	}
+
+	uid := userID
+	return RecordSecurityEvent(db, models.SecurityEvent{UserID: &uid, Type: models.SecurityEventMFARecoveryCodeUsed})
+}
+
+// isRequired reports whether any of the user's roles, direct or through a
+// team, requires 2FA
+func (s *MFAServiceImpl) isRequired(db *gorm.DB, userID uuid.UUID) (bool, error) {
+	var count int64
+	err := db.Model(&models.Role{}).
+		Where("id IN ("+heldRoleIDs+") AND require_mfa = ?", userID, userID, true).
+		Count(&count).Error
+	return count > 0, err
+}
+
+func (s *MFAServiceImpl) findMFA(db *gorm.DB, userID uuid.UUID) (*models.UserMFA, error) {
+	var mfa models.UserMFA
+	result := db.Where("user_id = ?", userID).Limit(1).Find(&mfa)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	if result.RowsAffected == 0 {
+		return nil, nil
+	}
+	return &mfa, nil
+}
+
+func (s *MFAServiceImpl) findChallenge(db *gorm.DB, challengeToken string) (*models.MFAChallenge, error) {
+	var challenge models.MFAChallenge
+	result := db.Where("token_hash = ? AND expires_at > ?", HashRefreshToken(challengeToken), time.Now()).Limit(1).Find(&challenge)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	if result.RowsAffected == 0 {
+		return nil, errors.New("invalid or expired mfa token")
+	}
+	return &challenge, nil
+}
+
+func deleteMFA(tx *gorm.DB, userID uuid.UUID) error {
+	if err := tx.Where("user_id = ?", userID).Delete(&models.MFARecoveryCode{}).Error; err != nil {
+		return err
+	}
+	return tx.Where("user_id = ?", userID).Delete(&models.UserMFA{}).Error
+}
+
+// replaceRecoveryCodes invalidates the old codes and stores hashes of new ones
+func replaceRecoveryCodes(tx *gorm.DB, userID uuid.UUID) ([]string, error) {
+	if err := tx.Where("user_id = ?", userID).Delete(&models.MFARecoveryCode{}).Error; err != nil {
+		return nil, err
+	}
+
+	codes := make([]string, 0, recoveryCodeCount)
+	for i := 0; i < recoveryCodeCount; i++ {
+		code, err := generateRecoveryCode()
+		if err != nil {
+			return nil, err
+		}
+
+		record := models.MFARecoveryCode{
+			ID:       uuid.Must(uuid.NewV4()),
+			UserID:   userID,
+			CodeHash: HashRefreshToken(normalizeRecoveryCode(code)),
+		}
+		if err := tx.Create(&record).Error; err != nil {
+			return nil, err
+		}
+		codes = append(codes, code)
+	}
+	return codes, nil
+}
+
+// generateRecoveryCode returns 80 random bits as xxxx-xxxx-xxxx-xxxx. That is
+// enough entropy for a fast hash, like refresh tokens.
+func generateRecoveryCode() (string, error) {
+	buf := make([]byte, 10)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	raw := strings.ToLower(base32.StdEncoding.EncodeToString(buf))[:recoveryCodeLength]
+
+	var groups []string
+	for i := 0; i < len(raw); i += 4 {
+		groups = append(groups, raw[i:i+4])
+	}
+	return strings.Join(groups, "-"), nil
+}
+
+// normalizeRecoveryCode ignores case, spaces and dashes people type or leave out
+func normalizeRecoveryCode(code string) string {
+	code = strings.ToLower(code)
+	return strings.NewReplacer("-", "", " ", "").Replace(code)
+}
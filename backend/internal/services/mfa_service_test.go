@@ -0,0 +1,71 @@
+package services
+
+import (
+	"task-manager/backend/internal/models"
+	"testing"
+
+	"github.com/gofrs/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMFAService_LoginChallengeForTeamRoles(t *testing.T) {
+	db := setupTestDB()
+	mfaService := NewMFAService()
+	admin := createTestRole(db, "admin")
+	require.NoError(t, db.Model(&models.Role{}).Where("id = ?", admin.ID).Update("require_mfa", true).Error)
+
+	ada := createTestUser(db, "ada", "password123")
+	grace := createTestUser(db, "grace", "password123")
+
+	challenge, err := mfaService.LoginChallenge(db, ada.ID)
+	require.NoError(t, err)
+	assert.Nil(t, challenge)
+
+	// Holding the role through a team requires 2FA as well
+	createTestTeamAdmin(db, ada.ID, admin.ID)
+	challenge, err = mfaService.LoginChallenge(db, ada.ID)
+	require.NoError(t, err)
+	require.NotNil(t, challenge)
+	assert.True(t, challenge.EnrollmentRequired)
+
+	grantTestRole(db, grace.ID, admin.ID)
+	challenge, err = mfaService.LoginChallenge(db, grace.ID)
+	require.NoError(t, err)
+	require.NotNil(t, challenge)
+	assert.True(t, challenge.EnrollmentRequired)
+}
+
+func TestMFAService_VerifyChallengeLimitsAttempts(t *testing.T) {
+	db := setupTestDB()
+	mfaService := NewMFAService()
+	ada := createTestUser(db, "ada", "password123")
+
+	require.NoError(t, db.Create(&models.UserMFA{UserID: ada.ID, Secret: "JBSWY3DPEHPK3PXP", Enabled: true}).Error)
+	require.NoError(t, db.Create(&models.MFARecoveryCode{ID: uuid.Must(uuid.NewV4()), UserID: ada.ID, CodeHash: HashRefreshToken("abcdeabcde")}).Error)
+
+	challenge, err := mfaService.LoginChallenge(db, ada.ID)
+	require.NoError(t, err)
+	require.NotNil(t, challenge)
+
+	for i := 1; i < maxMFAAttempts; i++ {
+		_, _, err = mfaService.VerifyChallenge(db, challenge.Token, "wrong-code")
+		assert.EqualError(t, err, "invalid code")
+	}
+	_, _, err = mfaService.VerifyChallenge(db, challenge.Token, "wrong-code")
+	assert.EqualError(t, err, "too many invalid codes")
+	_, _, err = mfaService.VerifyChallenge(db, challenge.Token, "abcde-abcde")
+	assert.EqualError(t, err, "invalid or expired mfa token")
+
+	// Attempts taken by requests running in parallel count before the code
+	// is looked at, so even a right code is refused once they are used up
+	challenge, err = mfaService.LoginChallenge(db, ada.ID)
+	require.NoError(t, err)
+	require.NoError(t, db.Model(&models.MFAChallenge{}).Where("user_id = ?", ada.ID).Update("attempts", maxMFAAttempts).Error)
+	_, _, err = mfaService.VerifyChallenge(db, challenge.Token, "abcde-abcde")
+	assert.EqualError(t, err, "too many invalid codes")
+
+	var challenges int64
+	db.Model(&models.MFAChallenge{}).Count(&challenges)
+	assert.Zero(t, challenges)
+}
@@ -0,0 +1,190 @@
+package services
+
+import (
+	"errors"
+	"task-manager/backend/internal/models"
+	"time"
+
+	"github.com/gofrs/uuid"
+	"gorm.io/gorm"
+)
+
+type MilestoneService interface {
+	CreateMilestone(db *gorm.DB, milestone models.Milestone) (*models.Milestone, error)
+	UpdateMilestone(db *gorm.DB, milestoneID uuid.UUID, updateReq models.MilestoneUpdateRequest) (*models.Milestone, error)
+	DeleteMilestone(db *gorm.DB, milestoneID uuid.UUID) error
+	GetMilestoneByID(db *gorm.DB, milestoneID uuid.UUID) (*models.Milestone, error)
+	GetMilestones(db *gorm.DB) ([]models.Milestone, error)
+	GetBurndown(db *gorm.DB, milestoneID uuid.UUID) (*models.Burndown, error)
+}
+
+type MilestoneServiceImpl struct{}
+
+func NewMilestoneService() *MilestoneServiceImpl {
+	return &MilestoneServiceImpl{}
+}
+
+func (s *MilestoneServiceImpl) CreateMilestone(db *gorm.DB, milestone models.Milestone) (*models.Milestone, error) {
+	if !milestone.EndDate.After(milestone.StartDate) {
+		return nil, errors.New("end date must be after start date")
+	}
+
+	milestone.ID = uuid.Must(uuid.NewV4())
+
+	if err := db.Create(&milestone).Error; err != nil {
+		return nil, err
+	}
+
+	return &milestone, nil
+}
+
+func (s *MilestoneServiceImpl) UpdateMilestone(db *gorm.DB, milestoneID uuid.UUID, updateReq models.MilestoneUpdateRequest) (*models.Milestone, error) {
+	milestone, err := s.GetMilestoneByID(db, milestoneID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Update fields if provided
+	if updateReq.Name != nil {
+		milestone.Name = *updateReq.Name
+	}
+	if updateReq.Goal != nil {
+		milestone.Goal = *updateReq.Goal
+	}
+	if updateReq.StartDate != nil {
+		milestone.StartDate = *updateReq.StartDate
+	}
+	if updateReq.EndDate != nil {
+		milestone.EndDate = *updateReq.EndDate
+	}
+
+	if !milestone.EndDate.After(milestone.StartDate) {
+		return nil, errors.New("end date must be after start date")
+	}
+
+	if err := db.Save(milestone).Error; err != nil {
+		return nil, err
+	}
+
+	return milestone, nil
+}
+
+func (s *MilestoneServiceImpl) DeleteMilestone(db *gorm.DB, milestoneID uuid.UUID) error {
+	return db.Transaction(func(tx *gorm.DB) error {
+		// Detach tasks so they fall back to the backlog
+		if err := tx.Model(&models.Task{}).Where("milestone_id = ?", milestoneID).Update("milestone_id", nil).Error; err != nil {
+			return err
+		}
+
+		result := tx.Delete(&models.Milestone{}, "id = ?", milestoneID)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return errors.New("milestone not found")
+		}
+		return nil
+	})
+}
+
+func (s *MilestoneServiceImpl) GetMilestoneByID(db *gorm.DB, milestoneID uuid.UUID) (*models.Milestone, error) {
+	var milestone models.Milestone
+
+	result := db.Where("id = ?", milestoneID).First(&milestone)
+	if result.Error != nil {
+		if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+			return nil, errors.New("milestone not found")
+		}
+		return nil, result.Error
+	}
+
+	return &milestone, nil
+}
+
+func (s *MilestoneServiceImpl) GetMilestones(db *gorm.DB) ([]models.Milestone, error) {
+	var milestones []models.Milestone
+
+	result := db.Order("start_date desc").Find(&milestones)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+
+	return milestones, nil
+}
+
+func (s *MilestoneServiceImpl) GetBurndown(db *gorm.DB, milestoneID uuid.UUID) (*models.Burndown, error) {
+	milestone, err := s.GetMilestoneByID(db, milestoneID)
+	if err != nil {
+		return nil, err
+	}
+
+	var tasks []models.Task
+	result := db.Select("id", "created_at", "completed_at").Where("milestone_id = ?", milestoneID).Find(&tasks)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+
+	points := ComputeBurndown(tasks, milestone.StartDate, milestone.EndDate, time.Now())
+
+	return &models.Burndown{
+		MilestoneID: milestone.ID,
+		StartDate:   milestone.StartDate,
+		EndDate:     milestone.EndDate,
+		TotalTasks:  len(tasks),
+		Points:      points,
+	}, nil
+}
+
+// ComputeBurndown returns one point per day between start and end (capped at
+// now) holding the number of tasks that existed but were not yet completed at
+// the end of that day. The ideal line falls linearly from the first day's
+// remaining count to zero on the last day of the milestone.
+func ComputeBurndown(tasks []models.Task, start, end, now time.Time) []models.BurndownPoint {
+	firstDay := truncateToDay(start)
+	lastDay := truncateToDay(end)
+	totalDays := int(lastDay.Sub(firstDay).Hours()/24) + 1
+
+	points := []models.BurndownPoint{}
+	var initial int
+
+	for i := 0; i < totalDays; i++ {
+		day := firstDay.AddDate(0, 0, i)
+		if day.After(now) {
+			break
+		}
+		endOfDay := day.AddDate(0, 0, 1)
+
+		remaining := 0
+		for _, task := range tasks {
+			if !task.CreatedAt.Before(endOfDay) {
+				continue
+			}
+			if task.CompletedAt != nil && task.CompletedAt.Before(endOfDay) {
+				continue
+			}
+			remaining++
+		}
+
+		if i == 0 {
+			initial = remaining
+		}
+
+		ideal := float64(initial)
+		if totalDays > 1 {
+			ideal = float64(initial) * float64(totalDays-1-i) / float64(totalDays-1)
+		}
+
+		points = append(points, models.BurndownPoint{
+			Date:      day.Format("2006-01-02"),
+			Remaining: remaining,
+			Ideal:     ideal,
+		})
+	}
+
+	return points
+}
+
+func truncateToDay(t time.Time) time.Time {
+	t = t.UTC()
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
@@ -0,0 +1,51 @@
+package services
+
+import (
+	"task-manager/backend/internal/models"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestComputeBurndown(t *testing.T) {
+	start := time.Date(2024, 3, 4, 9, 0, 0, 0, time.UTC)
+	end := time.Date(2024, 3, 8, 17, 0, 0, 0, time.UTC)
+
+	completedDay2 := time.Date(2024, 3, 5, 15, 0, 0, 0, time.UTC)
+	completedDay4 := time.Date(2024, 3, 7, 10, 0, 0, 0, time.UTC)
+
+	tasks := []models.Task{
+		{CreatedAt: start.Add(-24 * time.Hour)},
+		{CreatedAt: start.Add(-24 * time.Hour), CompletedAt: &completedDay2},
+		{CreatedAt: start, CompletedAt: &completedDay4},
+		// Added to the milestone mid-sprint
+		{CreatedAt: time.Date(2024, 3, 6, 12, 0, 0, 0, time.UTC)},
+	}
+
+	points := ComputeBurndown(tasks, start, end, end)
+
+	assert.Len(t, points, 5)
+	assert.Equal(t, "2024-03-04", points[0].Date)
+	assert.Equal(t, "2024-03-08", points[4].Date)
+
+	remaining := []int{}
+	for _, p := range points {
+		remaining = append(remaining, p.Remaining)
+	}
+	assert.Equal(t, []int{3, 2, 3, 2, 2}, remaining)
+
+	assert.Equal(t, 3.0, points[0].Ideal)
+	assert.Equal(t, 0.0, points[4].Ideal)
+}
+
+func TestComputeBurndown_StopsAtNow(t *testing.T) {
+	start := time.Date(2024, 3, 4, 0, 0, 0, 0, time.UTC)
+	end := time.Date(2024, 3, 17, 0, 0, 0, 0, time.UTC)
+	now := time.Date(2024, 3, 6, 8, 0, 0, 0, time.UTC)
+
+	points := ComputeBurndown([]models.Task{{CreatedAt: start}}, start, end, now)
+
+	assert.Len(t, points, 3)
+	assert.Equal(t, "2024-03-06", points[2].Date)
+}
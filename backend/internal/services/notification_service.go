@@ -0,0 +1,224 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"task-manager/backend/internal/models"
+	"task-manager/backend/internal/utils"
+	"time"
+
+	"github.com/gofrs/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type NotificationService interface {
+	AddWatcher(db *gorm.DB, taskID uuid.UUID, userID uuid.UUID) error
+	RemoveWatcher(db *gorm.DB, taskID uuid.UUID, userID uuid.UUID) error
+	GetWatchers(db *gorm.DB, taskID uuid.UUID) ([]models.TaskWatcher, error)
+	NotifyTaskEvent(db *gorm.DB, task models.Task, actorID uuid.UUID, eventType string) error
+	NotifyWatchers(db *gorm.DB, task models.Task, actorID uuid.UUID, eventType string, watcherIDs []uuid.UUID) error
+	GetNotifications(db *gorm.DB, userID uuid.UUID, unreadOnly bool, pagination utils.PaginationParams) (utils.PaginationResponse, error)
+	GetUnreadCount(db *gorm.DB, userID uuid.UUID) (int64, error)
+	MarkRead(db *gorm.DB, notificationID uuid.UUID, userID uuid.UUID) error
+	MarkAllRead(db *gorm.DB, userID uuid.UUID) (int64, error)
+}
+
+type NotificationServiceImpl struct {
+	emailService EmailService
+}
+
+func NewNotificationService() *NotificationServiceImpl {
+	return &NotificationServiceImpl{emailService: NewEmailService()}
+}
+
+func (s *NotificationServiceImpl) AddWatcher(db *gorm.DB, taskID uuid.UUID, userID uuid.UUID) error {
+	watcher := models.TaskWatcher{
+		ID:     uuid.Must(uuid.NewV4()),
+		TaskID: taskID,
+		UserID: userID,
+	}
+
+	// Subscribing twice is a no-op
+	return db.Clauses(clause.OnConflict{DoNothing: true}).Create(&watcher).Error
+}
+
+func (s *NotificationServiceImpl) RemoveWatcher(db *gorm.DB, taskID uuid.UUID, userID uuid.UUID) error {
+	return db.Where("task_id = ? AND user_id = ?", taskID, userID).Delete(&models.TaskWatcher{}).Error
+}
+
+func (s *NotificationServiceImpl) GetWatchers(db *gorm.DB, taskID uuid.UUID) ([]models.TaskWatcher, error) {
+	var watchers []models.TaskWatcher
+
+	result := db.Where("task_id = ?", taskID).Order("created_at asc").Find(&watchers)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+
+	return watchers, nil
+}
+
+// NotifyTaskEvent writes an inbox entry for every watcher of the task who
+// can still read it, except the user who made the change
+func (s *NotificationServiceImpl) NotifyTaskEvent(db *gorm.DB, task models.Task, actorID uuid.UUID, eventType string) error {
+	watchers, err := s.GetWatchers(db, task.ID)
+	if err != nil {
+		return err
+	}
+
+	watcherIDs := make([]uuid.UUID, 0, len(watchers))
+	for _, watcher := range watchers {
+		watcherIDs = append(watcherIDs, watcher.UserID)
+	}
+
+	resource, err := taskPolicyResource(db, task)
+	if err != nil {
+		return err
+	}
+	watcherIDs, err = watchersWithReadAccess(db, resource, watcherIDs)
+	if err != nil {
+		return err
+	}
+	return s.NotifyWatchers(db, task, actorID, eventType, watcherIDs)
+}
+
+// NotifyWatchers writes an inbox entry for the given watchers except the
+// user who made the change. Deleted tasks no longer have watcher rows, so
+// their event carries the watchers instead.
+func (s *NotificationServiceImpl) NotifyWatchers(db *gorm.DB, task models.Task, actorID uuid.UUID, eventType string, watcherIDs []uuid.UUID) error {
+	message := taskEventMessage(task, eventType)
+
+	var notifications []models.Notification
+	for _, watcherID := range watcherIDs {
+		if watcherID == actorID {
+			continue
+		}
+
+		taskID := task.ID
+		actor := actorID
+		notifications = append(notifications, models.Notification{
+			ID:      uuid.Must(uuid.NewV4()),
+			UserID:  watcherID,
+			ActorID: &actor,
+			TaskID:  &taskID,
+			Type:    eventType,
+			Message: message,
+		})
+	}
+
+	if len(notifications) == 0 {
+		return nil
+	}
+
+	if err := db.Create(&notifications).Error; err != nil {
+		return err
+	}
+
+	// Owners are emailed about changes to their own tasks
+	for _, notification := range notifications {
+		if notification.UserID != task.UserID {
+			continue
+		}
+		if err := s.emailService.QueueNotificationEmail(db, notification); err != nil {
+			log.Printf("Failed to queue email for notification %s: %v", notification.ID, err)
+		}
+	}
+
+	return nil
+}
+
+func (s *NotificationServiceImpl) GetNotifications(db *gorm.DB, userID uuid.UUID, unreadOnly bool, pagination utils.PaginationParams) (utils.PaginationResponse, error) {
+	var notifications []models.Notification
+	var total int64
+
+	query := db.Model(&models.Notification{}).Where("user_id = ?", userID)
+	if unreadOnly {
+		query = query.Where("read_at IS NULL")
+	}
+
+	if err := query.Count(&total).Error; err != nil {
+		return utils.PaginationResponse{}, err
+	}
+
+	result := query.Order("created_at desc").Offset(pagination.Offset).Limit(pagination.Limit).Find(&notifications)
+	if result.Error != nil {
+		return utils.PaginationResponse{}, result.Error
+	}
+
+	return utils.CreatePaginationResponse(notifications, total, pagination), nil
+}
+
+func (s *NotificationServiceImpl) GetUnreadCount(db *gorm.DB, userID uuid.UUID) (int64, error) {
+	var count int64
+
+	err := db.Model(&models.Notification{}).Where("user_id = ? AND read_at IS NULL", userID).Count(&count).Error
+	return count, err
+}
+
+func (s *NotificationServiceImpl) MarkRead(db *gorm.DB, notificationID uuid.UUID, userID uuid.UUID) error {
+	var notification models.Notification
+
+	result := db.Where("id = ? AND user_id = ?", notificationID, userID).First(&notification)
+	if result.Error != nil {
+		if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+			return errors.New("notification not found")
+		}
+		return result.Error
+	}
+
+	if notification.ReadAt != nil {
+		return nil
+	}
+
+	return db.Model(&notification).Update("read_at", time.Now()).Error
+}
+
+func (s *NotificationServiceImpl) MarkAllRead(db *gorm.DB, userID uuid.UUID) (int64, error) {
+	result := db.Model(&models.Notification{}).
+		Where("user_id = ? AND read_at IS NULL", userID).
+		Update("read_at", time.Now())
+
+	return result.RowsAffected, result.Error
+}
+
+// watchersWithReadAccess drops the watchers the task read policy no longer
+// lets see the task, e.g. after it was unshared with them or they left the
+// team it is shared with
+func watchersWithReadAccess(db *gorm.DB, resource PolicyResource, watcherIDs []uuid.UUID) ([]uuid.UUID, error) {
+	if len(watcherIDs) == 0 {
+		return watcherIDs, nil
+	}
+
+	adminIDs, err := enabledAdminIDs(db)
+	if err != nil {
+		return nil, err
+	}
+	admins := make(map[uuid.UUID]bool, len(adminIDs))
+	for _, id := range adminIDs {
+		admins[id] = true
+	}
+
+	allowed := make([]uuid.UUID, 0, len(watcherIDs))
+	for _, watcherID := range watcherIDs {
+		subject, err := taskPolicySubject(db, watcherID, admins[watcherID])
+		if err != nil {
+			return nil, err
+		}
+		if Policies().Evaluate(subject, PolicyActionRead, resource).Allowed {
+			allowed = append(allowed, watcherID)
+		}
+	}
+	return allowed, nil
+}
+
+func taskEventMessage(task models.Task, eventType string) string {
+	switch eventType {
+	case models.NotificationTaskCreated:
+		return fmt.Sprintf("Task %q was created", task.Title)
+	case models.NotificationTaskDeleted:
+		return fmt.Sprintf("Task %q was deleted", task.Title)
+	default:
+		return fmt.Sprintf("Task %q was updated", task.Title)
+	}
+}
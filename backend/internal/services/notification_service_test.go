@@ -0,0 +1,234 @@
+package services
+
+import (
+	"task-manager/backend/internal/models"
+	"task-manager/backend/internal/utils"
+	"testing"
+
+	"github.com/gofrs/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+func TestNotificationService_AddAndRemoveWatcher(t *testing.T) {
+	db := setupTestDB()
+	notificationService := NewNotificationService()
+
+	taskID := uuid.Must(uuid.NewV4())
+	userID := uuid.Must(uuid.NewV4())
+
+	// Watching twice keeps a single subscription
+	assert.NoError(t, notificationService.AddWatcher(db, taskID, userID))
+	assert.NoError(t, notificationService.AddWatcher(db, taskID, userID))
+
+	watchers, err := notificationService.GetWatchers(db, taskID)
+	assert.NoError(t, err)
+	assert.Len(t, watchers, 1)
+	assert.Equal(t, userID, watchers[0].UserID)
+
+	assert.NoError(t, notificationService.RemoveWatcher(db, taskID, userID))
+
+	watchers, err = notificationService.GetWatchers(db, taskID)
+	assert.NoError(t, err)
+	assert.Empty(t, watchers)
+}
+
+func TestNotificationService_NotifyTaskEventSkipsActor(t *testing.T) {
+	db := setupTestDB()
+	notificationService := NewNotificationService()
+
+	actorID := uuid.Must(uuid.NewV4())
+	watcherID := uuid.Must(uuid.NewV4())
+	task := models.Task{
+		ID:     uuid.Must(uuid.NewV4()),
+		Title:  "Write report",
+		UserID: actorID,
+	}
+
+	// Only watchers who can read the task are notified
+	db.Create(&models.TaskUserShare{ID: uuid.Must(uuid.NewV4()), TaskID: task.ID, UserID: watcherID, Access: models.TaskShareAccessRead, SharedBy: actorID})
+
+	assert.NoError(t, notificationService.AddWatcher(db, task.ID, actorID))
+	assert.NoError(t, notificationService.AddWatcher(db, task.ID, watcherID))
+
+	err := notificationService.NotifyTaskEvent(db, task, actorID, models.NotificationTaskUpdated)
+	assert.NoError(t, err)
+
+	var notifications []models.Notification
+	db.Find(&notifications)
+	assert.Len(t, notifications, 1)
+	assert.Equal(t, watcherID, notifications[0].UserID)
+	assert.Equal(t, actorID, *notifications[0].ActorID)
+	assert.Equal(t, task.ID, *notifications[0].TaskID)
+	assert.Equal(t, models.NotificationTaskUpdated, notifications[0].Type)
+	assert.Equal(t, `Task "Write report" was updated`, notifications[0].Message)
+
+	// A change nobody else watches notifies no one
+	assert.NoError(t, notificationService.RemoveWatcher(db, task.ID, watcherID))
+	assert.NoError(t, notificationService.NotifyTaskEvent(db, task, actorID, models.NotificationTaskDeleted))
+
+	var count int64
+	db.Model(&models.Notification{}).Count(&count)
+	assert.Equal(t, int64(1), count)
+}
+
+func TestNotificationService_MarkRead(t *testing.T) {
+	db := setupTestDB()
+	notificationService := NewNotificationService()
+
+	userID := uuid.Must(uuid.NewV4())
+	notifications := createTestNotifications(db, userID, 3)
+
+	count, err := notificationService.GetUnreadCount(db, userID)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(3), count)
+
+	assert.NoError(t, notificationService.MarkRead(db, notifications[0].ID, userID))
+	// Marking an already read notification is a no-op
+	assert.NoError(t, notificationService.MarkRead(db, notifications[0].ID, userID))
+
+	count, err = notificationService.GetUnreadCount(db, userID)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(2), count)
+
+	// Users cannot mark someone else's notifications
+	err = notificationService.MarkRead(db, notifications[1].ID, uuid.Must(uuid.NewV4()))
+	assert.EqualError(t, err, "notification not found")
+
+	pagination := utils.PaginationParams{Page: 1, PageSize: 10, Offset: 0, Limit: 10}
+	response, err := notificationService.GetNotifications(db, userID, true, pagination)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(2), response.Pagination.Total)
+
+	response, err = notificationService.GetNotifications(db, userID, false, pagination)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(3), response.Pagination.Total)
+}
+
+func TestNotificationService_MarkAllRead(t *testing.T) {
+	db := setupTestDB()
+	notificationService := NewNotificationService()
+
+	userID := uuid.Must(uuid.NewV4())
+	otherUserID := uuid.Must(uuid.NewV4())
+	notifications := createTestNotifications(db, userID, 3)
+	createTestNotifications(db, otherUserID, 2)
+
+	assert.NoError(t, notificationService.MarkRead(db, notifications[0].ID, userID))
+
+	updated, err := notificationService.MarkAllRead(db, userID)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(2), updated)
+
+	count, err := notificationService.GetUnreadCount(db, userID)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(0), count)
+
+	// Other users' inboxes are untouched
+	count, err = notificationService.GetUnreadCount(db, otherUserID)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(2), count)
+}
+
+func createTestNotifications(db *gorm.DB, userID uuid.UUID, count int) []models.Notification {
+	notifications := make([]models.Notification, 0, count)
+	for i := 0; i < count; i++ {
+		notification := models.Notification{
+			ID:      uuid.Must(uuid.NewV4()),
+			UserID:  userID,
+			Type:    models.NotificationTaskUpdated,
+			Message: "Task updated",
+		}
+		db.Create(&notification)
+		notifications = append(notifications, notification)
+	}
+	return notifications
+}
+
+func TestNotificationEventSubscriber_TaskDeletedWithForeignKeys(t *testing.T) {
+	// Enforce the cascade the Postgres schema has, which removes the watch
+	// rows in the same transaction as the task
+	db := openTestDB("file:" + t.Name() + "?mode=memory&cache=shared&_foreign_keys=1")
+	taskService := NewTaskService()
+	cacheService, _ := NewCacheService()
+
+	require.NoError(t, db.Migrator().DropTable(&models.TaskWatcher{}))
+	require.NoError(t, db.Exec(`CREATE TABLE task_watchers (
+		id TEXT NOT NULL PRIMARY KEY,
+		task_id TEXT NOT NULL REFERENCES tasks(id) ON DELETE CASCADE,
+		user_id TEXT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+		created_at DATETIME NOT NULL,
+		UNIQUE(task_id, user_id)
+	)`).Error)
+
+	owner := createTestUser(db, "owner", "password123")
+	watcher := createTestUser(db, "watcher", "password123")
+
+	task, err := taskService.CreateTask(db, models.Task{Title: "Write report", Status: "pending", Priority: "medium", UserID: owner.ID}, cacheService)
+	require.NoError(t, err)
+	_, err = taskService.ShareWithUser(db, task.ID, watcher.ID, models.TaskShareAccessRead, owner.ID)
+	require.NoError(t, err)
+	require.NoError(t, taskService.WatchTask(db, task.ID, watcher.ID, false, cacheService))
+	require.NoError(t, taskService.DeleteTask(db, task.ID, owner.ID, false, cacheService))
+
+	// The subscriber runs once the deletion is committed
+	var row models.OutboxEvent
+	require.NoError(t, db.Where("event_type = ?", models.DomainEventTaskDeleted).First(&row).Error)
+	handler := NotificationEventSubscriber(NewNotificationService())
+	require.NoError(t, handler(db, domainEventFromOutbox(row)))
+
+	var watchers int64
+	db.Model(&models.TaskWatcher{}).Where("task_id = ?", task.ID).Count(&watchers)
+	assert.Zero(t, watchers)
+
+	var notifications []models.Notification
+	db.Where("type = ?", models.DomainEventTaskDeleted).Find(&notifications)
+	require.Len(t, notifications, 1)
+	assert.Equal(t, watcher.ID, notifications[0].UserID)
+	assert.Equal(t, `Task "Write report" was deleted`, notifications[0].Message)
+}
+
+func TestNotificationEventSubscriber_UnsharedWatchersAreNotNotified(t *testing.T) {
+	db := setupTestDB()
+	taskService := NewTaskService()
+	notificationService := NewNotificationService()
+	cacheService, _ := NewCacheService()
+
+	owner := createTestUser(db, "owner", "password123")
+	reader := createTestUser(db, "reader", "password123")
+	member := createTestUser(db, "member", "password123")
+	team := models.Team{ID: uuid.Must(uuid.NewV4()), Name: "platform", CreatedBy: owner.ID}
+	db.Create(&team)
+	db.Create(&models.TeamMember{ID: uuid.Must(uuid.NewV4()), TeamID: team.ID, UserID: member.ID})
+
+	task, err := taskService.CreateTask(db, models.Task{Title: "Write report", Status: "pending", Priority: "medium", UserID: owner.ID}, cacheService)
+	require.NoError(t, err)
+	_, err = taskService.ShareWithUser(db, task.ID, reader.ID, models.TaskShareAccessRead, owner.ID)
+	require.NoError(t, err)
+	_, err = taskService.ShareWithTeam(db, task.ID, team.ID, models.TaskShareAccessRead, owner.ID)
+	require.NoError(t, err)
+	require.NoError(t, taskService.WatchTask(db, task.ID, reader.ID, false, cacheService))
+	require.NoError(t, taskService.WatchTask(db, task.ID, member.ID, false, cacheService))
+
+	require.NoError(t, taskService.UnshareWithUser(db, task.ID, reader.ID, owner.ID))
+	require.NoError(t, taskService.UnshareWithTeam(db, task.ID, team.ID, owner.ID))
+
+	title := "Write the report"
+	_, err = taskService.UpdateTask(db, task.ID, models.TaskUpdateRequest{Title: &title}, owner.ID, cacheService)
+	require.NoError(t, err)
+	require.NoError(t, taskService.DeleteTask(db, task.ID, owner.ID, false, cacheService))
+
+	handler := NotificationEventSubscriber(notificationService)
+	var events []models.OutboxEvent
+	require.NoError(t, db.Where("event_type IN ?", []string{models.DomainEventTaskUpdated, models.DomainEventTaskDeleted}).Find(&events).Error)
+	require.Len(t, events, 2)
+	for _, event := range events {
+		require.NoError(t, handler(db, domainEventFromOutbox(event)))
+	}
+
+	// The owner made both changes, and nobody else can see the task
+	var count int64
+	db.Model(&models.Notification{}).Count(&count)
+	assert.Zero(t, count)
+}
@@ -0,0 +1,633 @@
+package services
+
+import (
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"math/big"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+	"sync"
+	"task-manager/backend/internal/models"
+	"task-manager/backend/internal/utils"
+	"time"
+
+	"github.com/gofrs/uuid"
+	"github.com/golang-jwt/jwt/v5"
+	"gorm.io/gorm"
+)
+
+const (
+	// oidcLoginTimeout is how long the user has to sign in at the identity provider
+	oidcLoginTimeout = 10 * time.Minute
+
+	// oidcJWKSRefreshInterval limits refetching the provider's keys when a
+	// token names a kid we have not seen
+	oidcJWKSRefreshInterval = time.Minute
+)
+
+type OIDCConfig struct {
+	IssuerURL    string
+	ClientID     string
+	ClientSecret string
+	RedirectURL  string
+	Scopes       []string
+	// GroupsClaim names the ID token claim listing the user's groups
+	GroupsClaim string
+	// RoleMapping maps provider groups to role names. Mapped roles are kept in
+	// sync on every login; roles that no group maps to are left alone.
+	RoleMapping          map[string]string
+	AutoProvision        bool
+	RequireVerifiedEmail bool
+}
+
+func NewOIDCConfig() *OIDCConfig {
+	return &OIDCConfig{
+		IssuerURL:            strings.TrimSuffix(utils.GetEnv("OIDC_ISSUER_URL", ""), "/"),
+		ClientID:             utils.GetEnv("OIDC_CLIENT_ID", ""),
+		ClientSecret:         utils.GetEnv("OIDC_CLIENT_SECRET", ""),
+		RedirectURL:          utils.GetEnv("OIDC_REDIRECT_URL", utils.GetEnv("APP_URL", "http://localhost:3000")+"/auth/callback"),
+		Scopes:               strings.Fields(utils.GetEnv("OIDC_SCOPES", "openid email profile")),
+		GroupsClaim:          utils.GetEnv("OIDC_GROUPS_CLAIM", "groups"),
+		RoleMapping:          ParseRoleMapping(utils.GetEnv("OIDC_ROLE_MAPPING", "")),
+		AutoProvision:        utils.GetEnv("OIDC_AUTO_PROVISION", "true") == "true",
+		RequireVerifiedEmail: utils.GetEnv("OIDC_REQUIRE_VERIFIED_EMAIL", "true") == "true",
+	}
+}
+
+// Enabled reports whether single sign-on has been configured
+func (c *OIDCConfig) Enabled() bool {
+	return c.IssuerURL != "" && c.ClientID != ""
+}
+
+// ParseRoleMapping reads a comma separated group=role list
+func ParseRoleMapping(value string) map[string]string {
+	mapping := make(map[string]string)
+	for _, entry := range strings.Split(value, ",") {
+		group, role, ok := strings.Cut(strings.TrimSpace(entry), "=")
+		if ok && group != "" && role != "" {
+			mapping[strings.TrimSpace(group)] = strings.TrimSpace(role)
+		}
+	}
+	return mapping
+}
+
+// OIDCIdentity is what the identity provider asserted about the user
+type OIDCIdentity struct {
+	Issuer            string
+	Subject           string
+	Email             string
+	EmailVerified     bool
+	PreferredUsername string
+	Groups            []string
+}
+
+type OIDCService interface {
+	Enabled() bool
+	BeginLogin(db *gorm.DB) (string, error)
+	CompleteLogin(db *gorm.DB, state, code string) (*models.User, error)
+}
+
+type OIDCServiceImpl struct {
+	config *OIDCConfig
+	client *http.Client
+
+	mu            sync.Mutex
+	provider      *oidcProviderMetadata
+	keys          map[string]interface{}
+	keysFetchedAt time.Time
+}
+
+type oidcProviderMetadata struct {
+	Issuer                string `json:"issuer"`
+	AuthorizationEndpoint string `json:"authorization_endpoint"`
+	TokenEndpoint         string `json:"token_endpoint"`
+	JWKSURI               string `json:"jwks_uri"`
+}
+
+func NewOIDCService(config *OIDCConfig) *OIDCServiceImpl {
+	return &OIDCServiceImpl{config: config, client: &http.Client{Timeout: 10 * time.Second}}
+}
+
+func (s *OIDCServiceImpl) Enabled() bool {
+	return s.config.Enabled()
+}
+
+// BeginLogin records a new login attempt and returns the provider URL to
+// send the browser to. The state ties the callback to this attempt, the
+// nonce ties the ID token to it and the PKCE verifier proves the code is
+// redeemed by whoever started it.
+func (s *OIDCServiceImpl) BeginLogin(db *gorm.DB) (string, error) {
+	if !s.Enabled() {
+		return "", errors.New("single sign-on is not configured")
+	}
+
+	provider, err := s.discover()
+	if err != nil {
+		return "", err
+	}
+
+	loginState := models.OIDCLoginState{
+		State:        randomURLToken(24),
+		Nonce:        randomURLToken(24),
+		CodeVerifier: randomURLToken(32),
+		ExpiresAt:    time.Now().Add(oidcLoginTimeout),
+	}
+
+	// Abandoned logins are cleared out as new ones start
+	if err := db.Where("expires_at <= ?", time.Now()).Delete(&models.OIDCLoginState{}).Error; err != nil {
+		return "", err
+	}
+	if err := db.Create(&loginState).Error; err != nil {
+		return "", err
+	}
+
+	query := url.Values{
+		"response_type":         {"code"},
+		"client_id":             {s.config.ClientID},
+		"redirect_uri":          {s.config.RedirectURL},
+		"scope":                 {strings.Join(s.config.Scopes, " ")},
+		"state":                 {loginState.State},
+		"nonce":                 {loginState.Nonce},
+		"code_challenge":        {PKCEChallenge(loginState.CodeVerifier)},
+		"code_challenge_method": {"S256"},
+	}
+
+	separator := "?"
+	if strings.Contains(provider.AuthorizationEndpoint, "?") {
+		separator = "&"
+	}
+	return provider.AuthorizationEndpoint + separator + query.Encode(), nil
+}
+
+// CompleteLogin redeems the authorization code and returns the matching
+// local user, creating one if allowed
+func (s *OIDCServiceImpl) CompleteLogin(db *gorm.DB, state, code string) (*models.User, error) {
+	if !s.Enabled() {
+		return nil, errors.New("single sign-on is not configured")
+	}
+
+	// Each state can only be redeemed once
+	var loginState models.OIDCLoginState
+	result := db.Where("state = ? AND expires_at > ?", state, time.Now()).Limit(1).Find(&loginState)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	if result.RowsAffected == 0 {
+		return nil, errors.New("invalid or expired login state")
+	}
+	result = db.Where("state = ?", state).Delete(&models.OIDCLoginState{})
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	if result.RowsAffected == 0 {
+		return nil, errors.New("invalid or expired login state")
+	}
+
+	identity, err := s.exchangeCode(code, loginState.CodeVerifier, loginState.Nonce)
+	if err != nil {
+		log.Printf("Single sign-on login failed: %v", err)
+		return nil, errors.New("identity provider rejected the login")
+	}
+
+	return s.resolveUser(db, identity)
+}
+
+// exchangeCode trades the authorization code for tokens and verifies the
+// ID token
+func (s *OIDCServiceImpl) exchangeCode(code, codeVerifier, nonce string) (*OIDCIdentity, error) {
+	provider, err := s.discover()
+	if err != nil {
+		return nil, err
+	}
+
+	form := url.Values{
+		"grant_type":    {"authorization_code"},
+		"code":          {code},
+		"redirect_uri":  {s.config.RedirectURL},
+		"code_verifier": {codeVerifier},
+		"client_id":     {s.config.ClientID},
+	}
+	req, err := http.NewRequest(http.MethodPost, provider.TokenEndpoint, strings.NewReader(form.Encode()))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+	if s.config.ClientSecret != "" {
+		req.SetBasicAuth(url.QueryEscape(s.config.ClientID), url.QueryEscape(s.config.ClientSecret))
+	}
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	var tokens struct {
+		IDToken          string `json:"id_token"`
+		Error            string `json:"error"`
+		ErrorDescription string `json:"error_description"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&tokens); err != nil {
+		return nil, fmt.Errorf("token endpoint returned %d: %w", resp.StatusCode, err)
+	}
+	if resp.StatusCode != http.StatusOK || tokens.Error != "" {
+		return nil, fmt.Errorf("token endpoint returned %d: %s %s", resp.StatusCode, tokens.Error, tokens.ErrorDescription)
+	}
+	if tokens.IDToken == "" {
+		return nil, errors.New("token response has no id_token")
+	}
+
+	return s.verifyIDToken(tokens.IDToken, nonce)
+}
+
+func (s *OIDCServiceImpl) verifyIDToken(idToken, nonce string) (*OIDCIdentity, error) {
+	provider, err := s.discover()
+	if err != nil {
+		return nil, err
+	}
+
+	claims := jwt.MapClaims{}
+	_, err = jwt.ParseWithClaims(idToken, claims, s.keyFor,
+		jwt.WithValidMethods([]string{"RS256", "ES256", "EdDSA"}),
+		jwt.WithIssuer(provider.Issuer),
+		jwt.WithAudience(s.config.ClientID),
+		jwt.WithLeeway(time.Minute),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if exp, err := claims.GetExpirationTime(); err != nil || exp == nil {
+		return nil, errors.New("id_token has no expiry")
+	}
+	if tokenNonce, _ := claims["nonce"].(string); tokenNonce != nonce {
+		return nil, errors.New("id_token nonce does not match")
+	}
+
+	identity := &OIDCIdentity{Issuer: provider.Issuer}
+	identity.Subject, _ = claims["sub"].(string)
+	identity.Email, _ = claims["email"].(string)
+	identity.PreferredUsername, _ = claims["preferred_username"].(string)
+	switch verified := claims["email_verified"].(type) {
+	case bool:
+		identity.EmailVerified = verified
+	case string:
+		identity.EmailVerified = verified == "true"
+	}
+	switch groups := claims[s.config.GroupsClaim].(type) {
+	case []interface{}:
+		for _, group := range groups {
+			if name, ok := group.(string); ok {
+				identity.Groups = append(identity.Groups, name)
+			}
+		}
+	case string:
+		identity.Groups = []string{groups}
+	}
+
+	if identity.Subject == "" {
+		return nil, errors.New("id_token has no subject")
+	}
+	return identity, nil
+}
+
+// resolveUser finds the user linked to the identity, or else the user with
+// the same email, or else provisions one, and syncs the mapped roles
+func (s *OIDCServiceImpl) resolveUser(db *gorm.DB, identity *OIDCIdentity) (*models.User, error) {
+	var user models.User
+	err := db.Transaction(func(tx *gorm.DB) error {
+		var link models.UserIdentity
+		result := tx.Where("issuer = ? AND subject = ?", identity.Issuer, identity.Subject).Limit(1).Find(&link)
+		if result.Error != nil {
+			return result.Error
+		}
+
+		if result.RowsAffected > 0 {
+			if err := tx.First(&user, "id = ?", link.UserID).Error; err != nil {
+				return err
+			}
+			if err := tx.Model(&link).Updates(map[string]interface{}{"email": identity.Email, "last_login_at": time.Now()}).Error; err != nil {
+				return err
+			}
+		} else {
+			// Matching by email hands over an existing account, so the
+			// provider has to vouch for the address
+			if identity.Email == "" {
+				return errors.New("identity provider did not share an email address")
+			}
+			if s.config.RequireVerifiedEmail && !identity.EmailVerified {
+				return errors.New("email address is not verified")
+			}
+
+			result = tx.Where("LOWER(email) = LOWER(?)", identity.Email).Limit(1).Find(&user)
+			if result.Error != nil {
+				return result.Error
+			}
+			// Nobody proved they own the local address, so it may have been
+			// registered by someone waiting for the real owner to sign in
+			if result.RowsAffected > 0 && user.EmailVerifiedAt == nil {
+				return errors.New("account email address is not verified")
+			}
+			if result.RowsAffected == 0 {
+				if !s.config.AutoProvision {
+					return errors.New("no account for this email")
+				}
+				created, err := provisionUser(tx, identity)
+				if err != nil {
+					return err
+				}
+				user = *created
+			}
+
+			link = models.UserIdentity{
+				ID:          uuid.Must(uuid.NewV4()),
+				UserID:      user.ID,
+				Issuer:      identity.Issuer,
+				Subject:     identity.Subject,
+				Email:       identity.Email,
+				LastLoginAt: time.Now(),
+			}
+			if err := tx.Create(&link).Error; err != nil {
+				return err
+			}
+		}
+
+		if user.DisabledAt != nil {
+			return errors.New("account disabled")
+		}
+
+		return s.syncRoles(tx, user.ID, identity.Groups)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &user, nil
+}
+
+var usernameDisallowed = regexp.MustCompile(`[^a-zA-Z0-9._-]+`)
+
+// provisionUser creates a password-less account with the default user role
+func provisionUser(tx *gorm.DB, identity *OIDCIdentity) (*models.User, error) {
+	base := identity.PreferredUsername
+	if base == "" {
+		base, _, _ = strings.Cut(identity.Email, "@")
+	}
+	base = usernameDisallowed.ReplaceAllString(base, "")
+	if base == "" {
+		base = "user"
+	}
+
+	username := base
+	for i := 2; ; i++ {
+		var count int64
+		if err := tx.Model(&models.User{}).Where("username = ?", username).Count(&count).Error; err != nil {
+			return nil, err
+		}
+		if count == 0 {
+			break
+		}
+		username = fmt.Sprintf("%s%d", base, i)
+	}
+
+	// An empty password hash never verifies, so the account can only sign
+	// in through the identity provider
+	user := models.User{
+		ID:       uuid.Must(uuid.NewV4()),
+		Username: username,
+		Email:    identity.Email,
+	}
+	if identity.EmailVerified {
+		now := time.Now()
+		user.EmailVerifiedAt = &now
+	}
+	if err := tx.Create(&user).Error; err != nil {
+		return nil, err
+	}
+
+	userRole := models.UserRole{
+		UserID: user.ID,
+		RoleID: uuid.FromStringOrNil("550e8400-e29b-41d4-a716-446655440001"), // user role ID
+	}
+	if err := tx.Create(&userRole).Error; err != nil {
+		return nil, err
+	}
+
+	return &user, nil
+}
+
+// syncRoles grants the roles the user's groups map to and removes mapped
+// roles they no longer qualify for
+func (s *OIDCServiceImpl) syncRoles(tx *gorm.DB, userID uuid.UUID, groups []string) error {
+	if len(s.config.RoleMapping) == 0 {
+		return nil
+	}
+
+	wanted := make(map[string]bool)
+	for _, group := range groups {
+		if role, ok := s.config.RoleMapping[group]; ok {
+			wanted[role] = true
+		}
+	}
+
+	var managed []string
+	for _, role := range s.config.RoleMapping {
+		managed = append(managed, role)
+	}
+
+	var roles []models.Role
+	if err := tx.Where("name IN ?", managed).Find(&roles).Error; err != nil {
+		return err
+	}
+
+	changed := false
+	for _, role := range roles {
+		var count int64
+		if err := tx.Model(&models.UserRole{}).Where("user_id = ? AND role_id = ?", userID, role.ID).Count(&count).Error; err != nil {
+			return err
+		}
+
+		switch {
+		case wanted[role.Name] && count == 0:
+			if err := tx.Create(&models.UserRole{UserID: userID, RoleID: role.ID}).Error; err != nil {
+				return err
+			}
+			changed = true
+		case !wanted[role.Name] && count > 0:
+			err := guardLastAdmin(tx, func(tx *gorm.DB) error {
+				return tx.Unscoped().Where("user_id = ? AND role_id = ?", userID, role.ID).Delete(&models.UserRole{}).Error
+			})
+			if err != nil {
+				// Leaving the provider's admin group must not lock
+				// everyone out of the admin API
+				if err.Error() == "cannot remove the last admin" {
+					log.Printf("Keeping role %s of user %s: they are the last admin", role.Name, userID)
+					continue
+				}
+				return err
+			}
+			changed = true
+		}
+	}
+
+	if changed {
+		return recordUserRolesChanged(tx, userID, uuid.Nil)
+	}
+	return nil
+}
+
+// discover loads the provider metadata once
+func (s *OIDCServiceImpl) discover() (*oidcProviderMetadata, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.provider != nil {
+		return s.provider, nil
+	}
+
+	var provider oidcProviderMetadata
+	if err := s.getJSON(s.config.IssuerURL+"/.well-known/openid-configuration", &provider); err != nil {
+		return nil, fmt.Errorf("oidc discovery failed: %w", err)
+	}
+	if provider.Issuer != s.config.IssuerURL {
+		return nil, fmt.Errorf("oidc discovery returned issuer %q, expected %q", provider.Issuer, s.config.IssuerURL)
+	}
+	if provider.AuthorizationEndpoint == "" || provider.TokenEndpoint == "" || provider.JWKSURI == "" {
+		return nil, errors.New("oidc discovery document is incomplete")
+	}
+
+	s.provider = &provider
+	return s.provider, nil
+}
+
+// keyFor returns the provider key an ID token was signed with, refetching the
+// key set when the provider has rotated to a key we have not seen
+func (s *OIDCServiceImpl) keyFor(token *jwt.Token) (interface{}, error) {
+	kid, _ := token.Header["kid"].(string)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if key, ok := s.keys[kid]; ok {
+		return key, nil
+	}
+	if time.Since(s.keysFetchedAt) < oidcJWKSRefreshInterval {
+		return nil, fmt.Errorf("unknown signing key %q", kid)
+	}
+
+	var set struct {
+		Keys []struct {
+			KeyType string `json:"kty"`
+			KeyID   string `json:"kid"`
+			Use     string `json:"use"`
+			N       string `json:"n"`
+			E       string `json:"e"`
+			Curve   string `json:"crv"`
+			X       string `json:"x"`
+			Y       string `json:"y"`
+		} `json:"keys"`
+	}
+	if err := s.getJSON(s.provider.JWKSURI, &set); err != nil {
+		return nil, fmt.Errorf("fetching provider keys failed: %w", err)
+	}
+	s.keysFetchedAt = time.Now()
+
+	keys := make(map[string]interface{})
+	for _, jwk := range set.Keys {
+		if jwk.Use != "" && jwk.Use != "sig" {
+			continue
+		}
+		key, err := parseJWK(jwk.KeyType, jwk.Curve, jwk.N, jwk.E, jwk.X, jwk.Y)
+		if err != nil {
+			log.Printf("Skipping provider key %q: %v", jwk.KeyID, err)
+			continue
+		}
+		keys[jwk.KeyID] = key
+	}
+	s.keys = keys
+
+	if key, ok := s.keys[kid]; ok {
+		return key, nil
+	}
+	return nil, fmt.Errorf("unknown signing key %q", kid)
+}
+
+func parseJWK(keyType, curve, n, e, x, y string) (interface{}, error) {
+	decode := base64.RawURLEncoding.DecodeString
+	switch keyType {
+	case "RSA":
+		nBytes, err := decode(n)
+		if err != nil {
+			return nil, err
+		}
+		eBytes, err := decode(e)
+		if err != nil {
+			return nil, err
+		}
+		return &rsa.PublicKey{N: new(big.Int).SetBytes(nBytes), E: int(new(big.Int).SetBytes(eBytes).Int64())}, nil
+	case "EC":
+		if curve != "P-256" {
+			return nil, fmt.Errorf("unsupported curve %q", curve)
+		}
+		xBytes, err := decode(x)
+		if err != nil {
+			return nil, err
+		}
+		yBytes, err := decode(y)
+		if err != nil {
+			return nil, err
+		}
+		return &ecdsa.PublicKey{Curve: elliptic.P256(), X: new(big.Int).SetBytes(xBytes), Y: new(big.Int).SetBytes(yBytes)}, nil
+	case "OKP":
+		if curve != "Ed25519" {
+			return nil, fmt.Errorf("unsupported curve %q", curve)
+		}
+		xBytes, err := decode(x)
+		if err != nil {
+			return nil, err
+		}
+		if len(xBytes) != ed25519.PublicKeySize {
+			return nil, errors.New("invalid Ed25519 key")
+		}
+		return ed25519.PublicKey(xBytes), nil
+	}
+	return nil, fmt.Errorf("unsupported key type %q", keyType)
+}
+
+func (s *OIDCServiceImpl) getJSON(endpoint string, v interface{}) error {
+	resp, err := s.client.Get(endpoint)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("%s returned %d", endpoint, resp.StatusCode)
+	}
+	return json.NewDecoder(resp.Body).Decode(v)
+}
+
+// PKCEChallenge is the S256 code challenge for a verifier (RFC 7636)
+func PKCEChallenge(verifier string) string {
+	sum := sha256.Sum256([]byte(verifier))
+	return base64.RawURLEncoding.EncodeToString(sum[:])
+}
+
+func randomURLToken(size int) string {
+	buf := make([]byte, size)
+	if _, err := rand.Read(buf); err != nil {
+		panic(err)
+	}
+	return base64.RawURLEncoding.EncodeToString(buf)
+}
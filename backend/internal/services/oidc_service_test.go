@@ -0,0 +1,224 @@
+package services
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"task-manager/backend/internal/models"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+// mockIdP is a minimal OpenID provider: it hands out one code per call to
+// authorize and checks the PKCE verifier when the code is redeemed
+type mockIdP struct {
+	server   *httptest.Server
+	key      *rsa.PrivateKey
+	audience string
+	claims   jwt.MapClaims
+	codes    map[string]url.Values
+}
+
+func newMockIdP(t *testing.T) *mockIdP {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+
+	idp := &mockIdP{key: key, audience: "taskify", codes: map[string]url.Values{}}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/.well-known/openid-configuration", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(map[string]string{
+			"issuer":                 idp.server.URL,
+			"authorization_endpoint": idp.server.URL + "/authorize",
+			"token_endpoint":         idp.server.URL + "/token",
+			"jwks_uri":               idp.server.URL + "/jwks",
+		})
+	})
+	mux.HandleFunc("/jwks", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(map[string]interface{}{"keys": []map[string]string{{
+			"kty": "RSA",
+			"kid": "idp-key",
+			"use": "sig",
+			"n":   base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+			"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+		}}})
+	})
+	mux.HandleFunc("/token", func(w http.ResponseWriter, r *http.Request) {
+		r.ParseForm()
+		clientID, secret, _ := r.BasicAuth()
+		authorize, ok := idp.codes[r.PostForm.Get("code")]
+		if !ok || clientID != "taskify" || secret != "s3cret" ||
+			PKCEChallenge(r.PostForm.Get("code_verifier")) != authorize.Get("code_challenge") {
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(map[string]string{"error": "invalid_grant"})
+			return
+		}
+		delete(idp.codes, r.PostForm.Get("code"))
+
+		claims := jwt.MapClaims{
+			"iss":   idp.server.URL,
+			"aud":   idp.audience,
+			"exp":   time.Now().Add(time.Minute).Unix(),
+			"iat":   time.Now().Unix(),
+			"nonce": authorize.Get("nonce"),
+		}
+		for name, value := range idp.claims {
+			claims[name] = value
+		}
+		token := jwt.NewWithClaims(jwt.SigningMethodRS256, claims)
+		token.Header["kid"] = "idp-key"
+		idToken, _ := token.SignedString(key)
+		json.NewEncoder(w).Encode(map[string]string{"id_token": idToken, "access_token": "at", "token_type": "Bearer"})
+	})
+	idp.server = httptest.NewServer(mux)
+	t.Cleanup(idp.server.Close)
+	return idp
+}
+
+// authorize plays the user signing in and returns the callback parameters
+func (idp *mockIdP) authorize(t *testing.T, authorizationURL string) (string, string) {
+	parsed, err := url.Parse(authorizationURL)
+	require.NoError(t, err)
+	query := parsed.Query()
+
+	code := randomURLToken(8)
+	idp.codes[code] = query
+	return query.Get("state"), code
+}
+
+func newTestOIDCService(idp *mockIdP) *OIDCServiceImpl {
+	return NewOIDCService(&OIDCConfig{
+		IssuerURL:    idp.server.URL,
+		ClientID:     "taskify",
+		ClientSecret: "s3cret",
+		RedirectURL:  "http://localhost:3000/auth/callback",
+		Scopes:       []string{"openid", "email"},
+		GroupsClaim:  "groups",
+	})
+}
+
+func setupOIDCStateDB(t *testing.T) *gorm.DB {
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	require.NoError(t, err)
+	require.NoError(t, db.AutoMigrate(&models.OIDCLoginState{}))
+	return db
+}
+
+func TestOIDCService_BeginLoginAndExchangeCode(t *testing.T) {
+	idp := newMockIdP(t)
+	idp.claims = jwt.MapClaims{"sub": "user-1", "email": "ada@example.com", "email_verified": true, "groups": []string{"engineering", "admins"}}
+	service := newTestOIDCService(idp)
+	db := setupOIDCStateDB(t)
+
+	authorizationURL, err := service.BeginLogin(db)
+	require.NoError(t, err)
+
+	parsed, _ := url.Parse(authorizationURL)
+	assert.Equal(t, idp.server.URL+"/authorize", parsed.Scheme+"://"+parsed.Host+parsed.Path)
+	assert.Equal(t, "S256", parsed.Query().Get("code_challenge_method"))
+	assert.Equal(t, "openid email", parsed.Query().Get("scope"))
+	assert.Equal(t, "http://localhost:3000/auth/callback", parsed.Query().Get("redirect_uri"))
+
+	state, code := idp.authorize(t, authorizationURL)
+
+	var loginState models.OIDCLoginState
+	require.NoError(t, db.First(&loginState, "state = ?", state).Error)
+	assert.Equal(t, PKCEChallenge(loginState.CodeVerifier), parsed.Query().Get("code_challenge"))
+
+	identity, err := service.exchangeCode(code, loginState.CodeVerifier, loginState.Nonce)
+	require.NoError(t, err)
+	assert.Equal(t, idp.server.URL, identity.Issuer)
+	assert.Equal(t, "user-1", identity.Subject)
+	assert.Equal(t, "ada@example.com", identity.Email)
+	assert.True(t, identity.EmailVerified)
+	assert.Equal(t, []string{"engineering", "admins"}, identity.Groups)
+
+	// Codes are single use
+	_, err = service.exchangeCode(code, loginState.CodeVerifier, loginState.Nonce)
+	assert.Error(t, err)
+}
+
+func TestOIDCService_ExchangeCodeRejectsBadTokens(t *testing.T) {
+	idp := newMockIdP(t)
+	idp.claims = jwt.MapClaims{"sub": "user-1"}
+	service := newTestOIDCService(idp)
+	db := setupOIDCStateDB(t)
+
+	login := func() (models.OIDCLoginState, string) {
+		authorizationURL, err := service.BeginLogin(db)
+		require.NoError(t, err)
+		state, code := idp.authorize(t, authorizationURL)
+		var loginState models.OIDCLoginState
+		require.NoError(t, db.First(&loginState, "state = ?", state).Error)
+		return loginState, code
+	}
+
+	// Wrong PKCE verifier
+	loginState, code := login()
+	_, err := service.exchangeCode(code, "not-the-verifier", loginState.Nonce)
+	assert.Error(t, err)
+
+	// ID token minted for another login
+	loginState, code = login()
+	_, err = service.exchangeCode(code, loginState.CodeVerifier, "other-nonce")
+	assert.Error(t, err)
+
+	// ID token meant for another client
+	idp.audience = "someone-else"
+	loginState, code = login()
+	_, err = service.exchangeCode(code, loginState.CodeVerifier, loginState.Nonce)
+	assert.Error(t, err)
+}
+
+func TestOIDCService_CompleteLoginRejectsUnknownState(t *testing.T) {
+	idp := newMockIdP(t)
+	service := newTestOIDCService(idp)
+	db := setupOIDCStateDB(t)
+
+	_, err := service.CompleteLogin(db, "forged-state", "code")
+	assert.EqualError(t, err, "invalid or expired login state")
+}
+
+func TestPKCEChallenge(t *testing.T) {
+	// Reference value from: printf '<verifier>' | openssl dgst -sha256 -binary | basenc --base64url | tr -d '='
+	assert.Equal(t, "t-rqABVaddcbDW5fvadX5i-pX10JMcqomK_LxIK24mU", PKCEChallenge("correct-horse-battery-staple-0123456789abcdefg"))
+}
+
+func TestParseRoleMapping(t *testing.T) {
+	assert.Equal(t, map[string]string{"idp-admins": "admin", "staff": "user"}, ParseRoleMapping(" idp-admins=admin, staff=user ,broken,=x"))
+	assert.Empty(t, ParseRoleMapping(""))
+}
+
+func TestOIDCService_ResolveUserLinksVerifiedAccountsOnly(t *testing.T) {
+	db := setupTestDB()
+	service := NewOIDCService(&OIDCConfig{IssuerURL: "https://idp.example.com"})
+
+	// Someone registered the address without confirming it
+	squatter := createTestUser(db, "squatter", "password123")
+	require.NoError(t, db.Model(&models.User{}).Where("id = ?", squatter.ID).Update("email_verified_at", nil).Error)
+
+	identity := &OIDCIdentity{Issuer: "https://idp.example.com", Subject: "victim", Email: "SQUATTER@example.com", EmailVerified: true}
+	_, err := service.resolveUser(db, identity)
+	assert.EqualError(t, err, "account email address is not verified")
+
+	var links int64
+	db.Model(&models.UserIdentity{}).Count(&links)
+	assert.Zero(t, links)
+
+	// A confirmed address is linked
+	ada := createTestUser(db, "ada", "password123")
+	identity = &OIDCIdentity{Issuer: "https://idp.example.com", Subject: "ada", Email: "ada@example.com", EmailVerified: true}
+	user, err := service.resolveUser(db, identity)
+	require.NoError(t, err)
+	assert.Equal(t, ada.ID, user.ID)
+}
@@ -0,0 +1,250 @@
+package services
+
+import (
+	"bufio"
+	"crypto/sha1"
+	_ "embed"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"task-manager/backend/internal/models"
+	"task-manager/backend/internal/utils"
+	"unicode"
+
+	"github.com/gofrs/uuid"
+	"gorm.io/gorm"
+)
+
+// bcrypt ignores everything after the 72nd byte
+const maxPasswordBytes = 72
+
+//go:embed data/breached_passwords.txt
+var bundledBreachedPasswords string
+
+// PasswordPolicyError lists every rule a password breaks so clients can show
+// them all at once
+type PasswordPolicyError struct {
+	Violations []models.PasswordViolation
+}
+
+func (e *PasswordPolicyError) Error() string {
+	return "password does not meet the policy"
+}
+
+// BreachedPasswords is a set of SHA-1 hashes of passwords known from breaches
+type BreachedPasswords map[[sha1.Size]byte]struct{}
+
+// ParseBreachedPasswords reads hex SHA-1 hashes, one per line, optionally
+// followed by ":count" as in the Pwned Passwords download. Blank lines and
+// lines starting with # are skipped.
+func ParseBreachedPasswords(r io.Reader) (BreachedPasswords, error) {
+	set := BreachedPasswords{}
+	scanner := bufio.NewScanner(r)
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+		hash, _, _ := strings.Cut(text, ":")
+
+		var sum [sha1.Size]byte
+		decoded, err := hex.DecodeString(hash)
+		if err != nil || len(decoded) != sha1.Size {
+			return nil, fmt.Errorf("line %d: not a SHA-1 hash", line)
+		}
+		copy(sum[:], decoded)
+		set[sum] = struct{}{}
+	}
+	return set, scanner.Err()
+}
+
+func (b BreachedPasswords) Contains(password string) bool {
+	_, found := b[sha1.Sum([]byte(password))]
+	return found
+}
+
+type PasswordPolicy struct {
+	MinLength int
+	// MinCharacterClasses is how many of lowercase, uppercase, digits and
+	// symbols a password has to mix
+	MinCharacterClasses int
+	BannedWords         []string
+	// HistorySize is how many of the user's latest passwords, the current
+	// one included, cannot be used again
+	HistorySize int
+	Breached    BreachedPasswords
+}
+
+// NewPasswordPolicy reads the policy from the environment. The bundled list
+// of common breached passwords is used unless PASSWORD_BREACHED_LIST_FILE
+// points at a bigger one.
+func NewPasswordPolicy() (*PasswordPolicy, error) {
+	var source io.Reader = strings.NewReader(bundledBreachedPasswords)
+	if path := utils.GetEnv("PASSWORD_BREACHED_LIST_FILE", ""); path != "" {
+		file, err := os.Open(path)
+		if err != nil {
+			return nil, err
+		}
+		defer file.Close()
+		source = file
+	}
+	breached, err := ParseBreachedPasswords(source)
+	if err != nil {
+		return nil, fmt.Errorf("breached password list: %w", err)
+	}
+
+	var banned []string
+	for _, word := range strings.Split(utils.GetEnv("PASSWORD_BANNED_WORDS", "taskify"), ",") {
+		if word = strings.TrimSpace(word); word != "" {
+			banned = append(banned, word)
+		}
+	}
+
+	return &PasswordPolicy{
+		MinLength:           utils.GetEnvAsInt("PASSWORD_MIN_LENGTH", 8),
+		MinCharacterClasses: utils.GetEnvAsInt("PASSWORD_MIN_CHARACTER_CLASSES", 2),
+		BannedWords:         banned,
+		HistorySize:         utils.GetEnvAsInt("PASSWORD_HISTORY_SIZE", 5),
+		Breached:            breached,
+	}, nil
+}
+
+// Check applies the rules that need no database: length, character classes,
+// banned words (the user's own name and email included) and the breach list
+func (p *PasswordPolicy) Check(password string, user models.User) []models.PasswordViolation {
+	var violations []models.PasswordViolation
+	add := func(rule, message string) {
+		violations = append(violations, models.PasswordViolation{Rule: rule, Message: message})
+	}
+
+	if length := len([]rune(password)); length < p.MinLength {
+		add("min_length", fmt.Sprintf("must be at least %d characters long", p.MinLength))
+	}
+	if len(password) > maxPasswordBytes {
+		add("max_length", fmt.Sprintf("must be at most %d bytes long", maxPasswordBytes))
+	}
+
+	if classes := characterClasses(password); classes < p.MinCharacterClasses {
+		add("character_classes", fmt.Sprintf("must mix at least %d of lowercase letters, uppercase letters, digits and symbols", p.MinCharacterClasses))
+	}
+
+	lowered := strings.ToLower(password)
+	for _, word := range p.bannedWordsFor(user) {
+		if strings.Contains(lowered, strings.ToLower(word)) {
+			add("banned_word", fmt.Sprintf("must not contain %q", word))
+		}
+	}
+
+	if p.Breached != nil && p.Breached.Contains(password) {
+		add("breached", "appears in a list of passwords exposed in data breaches")
+	}
+
+	return violations
+}
+
+// Validate runs Check and the history rule and returns a *PasswordPolicyError
+// listing every violation
+func (p *PasswordPolicy) Validate(db *gorm.DB, password string, user models.User) error {
+	violations := p.Check(password, user)
+
+	reused, err := p.reusesRecentPassword(db, password, user)
+	if err != nil {
+		return err
+	}
+	if reused {
+		violations = append(violations, models.PasswordViolation{
+			Rule:    "history",
+			Message: fmt.Sprintf("must differ from your last %d passwords", p.HistorySize),
+		})
+	}
+
+	if len(violations) > 0 {
+		return &PasswordPolicyError{Violations: violations}
+	}
+	return nil
+}
+
+// Remember adds a newly set password hash to the user's history and forgets
+// the ones that no longer count
+func (p *PasswordPolicy) Remember(db *gorm.DB, userID uuid.UUID, passwordHash string) error {
+	if p.HistorySize <= 0 {
+		return nil
+	}
+
+	entry := models.PasswordHistory{
+		ID:           uuid.Must(uuid.NewV4()),
+		UserID:       userID,
+		PasswordHash: passwordHash,
+	}
+	if err := db.Create(&entry).Error; err != nil {
+		return err
+	}
+
+	keep := db.Model(&models.PasswordHistory{}).Select("id").
+		Where("user_id = ?", userID).Order("created_at DESC").Limit(p.HistorySize)
+	return db.Where("user_id = ? AND id NOT IN (?)", userID, keep).Delete(&models.PasswordHistory{}).Error
+}
+
+func (p *PasswordPolicy) reusesRecentPassword(db *gorm.DB, password string, user models.User) (bool, error) {
+	if p.HistorySize <= 0 || user.ID == uuid.Nil {
+		return false, nil
+	}
+
+	// The current password counts even for accounts created before the
+	// history was kept
+	if user.Password != "" && VerifyPassword(user.Password, password) {
+		return true, nil
+	}
+
+	var history []models.PasswordHistory
+	if err := db.Where("user_id = ?", user.ID).Order("created_at DESC").Limit(p.HistorySize).Find(&history).Error; err != nil {
+		return false, err
+	}
+	for _, entry := range history {
+		if VerifyPassword(entry.PasswordHash, password) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (p *PasswordPolicy) bannedWordsFor(user models.User) []string {
+	words := append([]string{}, p.BannedWords...)
+	if user.Username != "" {
+		words = append(words, user.Username)
+	}
+	if local, _, _ := strings.Cut(user.Email, "@"); local != "" {
+		words = append(words, local)
+	}
+
+	// Very short words would ban too many passwords by accident
+	var usable []string
+	seen := map[string]bool{}
+	for _, word := range words {
+		key := strings.ToLower(word)
+		if len([]rune(word)) >= 3 && !seen[key] {
+			seen[key] = true
+			usable = append(usable, word)
+		}
+	}
+	return usable
+}
+
+func characterClasses(password string) int {
+	var lower, upper, digit, symbol int
+	for _, r := range password {
+		switch {
+		case unicode.IsLower(r):
+			lower = 1
+		case unicode.IsUpper(r):
+			upper = 1
+		case unicode.IsDigit(r):
+			digit = 1
+		default:
+			symbol = 1
+		}
+	}
+	return lower + upper + digit + symbol
+}
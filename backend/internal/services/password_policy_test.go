@@ -0,0 +1,51 @@
+package services
+
+import (
+	"strings"
+	"task-manager/backend/internal/models"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func violatedRules(violations []models.PasswordViolation) []string {
+	var rules []string
+	for _, violation := range violations {
+		rules = append(rules, violation.Rule)
+	}
+	return rules
+}
+
+func TestPasswordPolicy_Check(t *testing.T) {
+	breached, err := ParseBreachedPasswords(strings.NewReader(bundledBreachedPasswords))
+	require.NoError(t, err)
+
+	policy := &PasswordPolicy{
+		MinLength:           10,
+		MinCharacterClasses: 3,
+		BannedWords:         []string{"taskify"},
+		Breached:            breached,
+	}
+	user := models.User{Username: "ada", Email: "lovelace@example.com"}
+
+	assert.Empty(t, policy.Check("correct Horse 42", user))
+	assert.Equal(t, []string{"min_length", "character_classes"}, violatedRules(policy.Check("short", user)))
+	assert.Equal(t, []string{"banned_word"}, violatedRules(policy.Check("my-TASKIFY-pw", user)))
+	assert.Equal(t, []string{"banned_word", "banned_word"}, violatedRules(policy.Check("Ada.Lovelace-1815", user)))
+	assert.Len(t, policy.Check("bob-Builder-99", models.User{Username: "bob", Email: "bob@example.com"}), 1)
+	assert.Equal(t, []string{"character_classes", "breached"}, violatedRules(policy.Check("1234567890", user)))
+	assert.Equal(t, []string{"max_length"}, violatedRules(policy.Check(strings.Repeat("aB3", 25), user)))
+}
+
+func TestParseBreachedPasswords(t *testing.T) {
+	// HIBP style lines, with and without counts
+	breached, err := ParseBreachedPasswords(strings.NewReader("# comment\n\n5BAA61E4C9B93F3F0682250B6CF8331B7EE68FD8:3861493\nb1b3773a05c0ed0176787a4f1574ff0075f7521e\n"))
+	require.NoError(t, err)
+	assert.True(t, breached.Contains("password"))
+	assert.True(t, breached.Contains("qwerty"))
+	assert.False(t, breached.Contains("Password"))
+
+	_, err = ParseBreachedPasswords(strings.NewReader("not-a-hash\n"))
+	assert.EqualError(t, err, "line 1: not a SHA-1 hash")
+}
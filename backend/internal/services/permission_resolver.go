@@ -0,0 +1,121 @@
+package services
+
+import (
+	"sync"
+	"task-manager/backend/internal/models"
+	"task-manager/backend/internal/utils"
+	"time"
+
+	"github.com/gofrs/uuid"
+	"gorm.io/gorm"
+)
+
+// PermissionResolver looks up a user's current roles and permissions for
+// every request, so role changes apply at once instead of when the access
+// token is renewed. Results are cached per user and dropped when the
+// user.roles_changed and role.permissions_changed events are dispatched.
+// Another replica may handle those events, so entries also expire after TTL.
+type PermissionResolver struct {
+	db          *gorm.DB
+	authService AuthService
+	TTL         time.Duration
+
+	mutex   sync.RWMutex
+	entries map[uuid.UUID]resolvedPermissions
+}
+
+type resolvedPermissions struct {
+	roles       []string
+	roleIDs     []uuid.UUID
+	isAdmin     bool
+	permissions []utils.Permission
+	expiresAt   time.Time
+}
+
+func NewPermissionResolver(db *gorm.DB, authService AuthService) *PermissionResolver {
+	return &PermissionResolver{
+		db:          db,
+		authService: authService,
+		TTL:         utils.GetEnvAsDuration("PERMISSION_CACHE_TTL", 5*time.Minute),
+		entries:     make(map[uuid.UUID]resolvedPermissions),
+	}
+}
+
+// ResolvePermissions returns the user's roles, whether one of them is the
+// admin role, and the permissions they grant
+func (r *PermissionResolver) ResolvePermissions(userID uuid.UUID) ([]string, bool, []utils.Permission, error) {
+	now := time.Now()
+	r.mutex.RLock()
+	entry, ok := r.entries[userID]
+	r.mutex.RUnlock()
+	if ok && now.Before(entry.expiresAt) {
+		return entry.roles, entry.isAdmin, entry.permissions, nil
+	}
+
+	roles, isAdmin, permissions, err := r.authService.GetUserRolesAndPermissions(r.db, userID)
+	if err != nil {
+		return nil, false, nil, err
+	}
+	roleIDs, err := userRoleIDs(r.db, userID)
+	if err != nil {
+		return nil, false, nil, err
+	}
+
+	r.mutex.Lock()
+	r.entries[userID] = resolvedPermissions{
+		roles:       roles,
+		roleIDs:     roleIDs,
+		isAdmin:     isAdmin,
+		permissions: permissions,
+		expiresAt:   now.Add(r.TTL),
+	}
+	r.mutex.Unlock()
+
+	return roles, isAdmin, permissions, nil
+}
+
+func (r *PermissionResolver) InvalidateUser(userID uuid.UUID) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	delete(r.entries, userID)
+}
+
+// InvalidateRole drops every cached user holding the role
+func (r *PermissionResolver) InvalidateRole(roleID uuid.UUID) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	for userID, entry := range r.entries {
+		for _, id := range entry.roleIDs {
+			if id == roleID {
+				delete(r.entries, userID)
+				break
+			}
+		}
+	}
+}
+
+func userRoleIDs(db *gorm.DB, userID uuid.UUID) ([]uuid.UUID, error) {
+	var roleIDs []uuid.UUID
+	err := db.Model(&models.Role{}).Where("id IN ("+heldRoleIDs+")", userID, userID).Pluck("id", &roleIDs).Error
+	return roleIDs, err
+}
+
+// recordUserRolesChanged tells resolvers to forget the user's permissions
+// once the transaction commits
+func recordUserRolesChanged(tx *gorm.DB, userID, actorID uuid.UUID) error {
+	event, err := NewUserRolesChangedEvent(userID, actorID)
+	if err != nil {
+		return err
+	}
+	return RecordDomainEvent(tx, event)
+}
+
+// recordRolePermissionsChanged tells resolvers to forget the permissions of
+// everyone holding the role once the transaction commits
+func recordRolePermissionsChanged(tx *gorm.DB, roleID, actorID uuid.UUID) error {
+	event, err := NewRolePermissionsChangedEvent(roleID, actorID)
+	if err != nil {
+		return err
+	}
+	return RecordDomainEvent(tx, event)
+}
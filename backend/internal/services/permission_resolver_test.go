@@ -0,0 +1,44 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"github.com/gofrs/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func cachedResolver(entries map[uuid.UUID][]uuid.UUID) *PermissionResolver {
+	resolver := NewPermissionResolver(nil, nil)
+	for userID, roleIDs := range entries {
+		resolver.entries[userID] = resolvedPermissions{roleIDs: roleIDs, expiresAt: time.Now().Add(time.Hour)}
+	}
+	return resolver
+}
+
+func TestPermissionCacheEventSubscriber(t *testing.T) {
+	editor := uuid.Must(uuid.NewV4())
+	viewer := uuid.Must(uuid.NewV4())
+	alice := uuid.Must(uuid.NewV4())
+	bob := uuid.Must(uuid.NewV4())
+	carol := uuid.Must(uuid.NewV4())
+	resolver := cachedResolver(map[uuid.UUID][]uuid.UUID{
+		alice: {editor},
+		bob:   {editor, viewer},
+		carol: {viewer},
+	})
+	handle := PermissionCacheEventSubscriber(resolver)
+
+	event, err := NewRolePermissionsChangedEvent(editor, uuid.Nil)
+	require.NoError(t, err)
+	require.NoError(t, handle(nil, event))
+	assert.NotContains(t, resolver.entries, alice)
+	assert.NotContains(t, resolver.entries, bob)
+	assert.Contains(t, resolver.entries, carol)
+
+	event, err = NewUserRolesChangedEvent(carol, uuid.Nil)
+	require.NoError(t, err)
+	require.NoError(t, handle(nil, event))
+	assert.Empty(t, resolver.entries)
+}
@@ -0,0 +1,217 @@
+package services
+
+import (
+	"errors"
+	"strings"
+	"task-manager/backend/internal/models"
+	"task-manager/backend/internal/utils"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/gofrs/uuid"
+	"gorm.io/gorm"
+)
+
+const (
+	defaultPersonalAccessTokenDays = 30
+	// lastUsedResolution limits last used updates to one write per token a minute
+	lastUsedResolution = time.Minute
+)
+
+type PersonalAccessTokenService interface {
+	CreateToken(db *gorm.DB, userID uuid.UUID, req models.CreatePersonalAccessTokenRequest) (*models.CreatedPersonalAccessToken, error)
+	GetTokens(db *gorm.DB, userID uuid.UUID) ([]models.PersonalAccessToken, error)
+	RevokeToken(db *gorm.DB, userID, tokenID uuid.UUID) error
+	Authenticate(db *gorm.DB, token, ipAddress string) (*utils.Claims, error)
+}
+
+type PersonalAccessTokenServiceImpl struct {
+	authService AuthService
+}
+
+func NewPersonalAccessTokenService(authService AuthService) *PersonalAccessTokenServiceImpl {
+	return &PersonalAccessTokenServiceImpl{authService: authService}
+}
+
+// CreateToken issues a token limited to scopes the user holds right now
+func (s *PersonalAccessTokenServiceImpl) CreateToken(db *gorm.DB, userID uuid.UUID, req models.CreatePersonalAccessTokenRequest) (*models.CreatedPersonalAccessToken, error) {
+	_, isAdmin, permissions, err := s.authService.GetUserRolesAndPermissions(db, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	scopes := normalizeScopes(req.Scopes)
+	if len(scopes) == 0 {
+		return nil, errors.New("at least one scope is required")
+	}
+	if !withinPermissions(scopes, permissions) {
+		return nil, errors.New("scopes exceed your permissions")
+	}
+	if req.Admin && !isAdmin {
+		return nil, errors.New("only admins can create admin tokens")
+	}
+
+	secret, err := generateRefreshToken()
+	if err != nil {
+		return nil, err
+	}
+	token := models.PersonalAccessTokenPrefix + secret
+
+	days := req.ExpiresInDays
+	if days == 0 {
+		days = defaultPersonalAccessTokenDays
+	}
+
+	pat := models.PersonalAccessToken{
+		ID:        uuid.Must(uuid.NewV4()),
+		UserID:    userID,
+		Name:      strings.TrimSpace(req.Name),
+		Hint:      token[:len(models.PersonalAccessTokenPrefix)+4],
+		TokenHash: HashRefreshToken(token),
+		Scopes:    scopes,
+		Admin:     req.Admin,
+		ExpiresAt: time.Now().AddDate(0, 0, days),
+	}
+	if err := db.Create(&pat).Error; err != nil {
+		return nil, err
+	}
+
+	return &models.CreatedPersonalAccessToken{PersonalAccessToken: pat, Token: token}, nil
+}
+
+func (s *PersonalAccessTokenServiceImpl) GetTokens(db *gorm.DB, userID uuid.UUID) ([]models.PersonalAccessToken, error) {
+	var tokens []models.PersonalAccessToken
+	if err := db.Where("user_id = ?", userID).Order("created_at DESC").Find(&tokens).Error; err != nil {
+		return nil, err
+	}
+	return tokens, nil
+}
+
+func (s *PersonalAccessTokenServiceImpl) RevokeToken(db *gorm.DB, userID, tokenID uuid.UUID) error {
+	result := db.Where("id = ? AND user_id = ?", tokenID, userID).Delete(&models.PersonalAccessToken{})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return errors.New("token not found")
+	}
+	return nil
+}
+
+// Authenticate turns a token into the same claims a JWT carries. Scopes are
+// intersected with the owner's current permissions, so a token loses
+// whatever its owner loses.
+func (s *PersonalAccessTokenServiceImpl) Authenticate(db *gorm.DB, token, ipAddress string) (*utils.Claims, error) {
+	var pat models.PersonalAccessToken
+	result := db.Where("token_hash = ?", HashRefreshToken(token)).Limit(1).Find(&pat)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	now := time.Now()
+	if result.RowsAffected == 0 || !pat.ExpiresAt.After(now) {
+		return nil, errors.New("invalid token")
+	}
+
+	var user models.User
+	result = db.Where("id = ?", pat.UserID).Limit(1).Find(&user)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	if result.RowsAffected == 0 || user.DisabledAt != nil {
+		return nil, errors.New("invalid token")
+	}
+
+	roles, isAdmin, permissions, err := s.authService.GetUserRolesAndPermissions(db, user.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	if pat.LastUsedAt == nil || now.Sub(*pat.LastUsedAt) >= lastUsedResolution {
+		if err := db.Model(&models.PersonalAccessToken{}).Where("id = ?", pat.ID).Updates(map[string]interface{}{
+			"last_used_at": now,
+			"last_used_ip": ipAddress,
+		}).Error; err != nil {
+			return nil, err
+		}
+	}
+
+	return &utils.Claims{
+		UserID:      user.ID,
+		Username:    user.Username,
+		Roles:       roles,
+		IsAdmin:     isAdmin && pat.Admin,
+		Permissions: intersectPermissions(pat.Scopes, permissions),
+		RegisteredClaims: jwt.RegisteredClaims{
+			ID:        pat.ID.String(),
+			Subject:   user.ID.String(),
+			IssuedAt:  jwt.NewNumericDate(pat.CreatedAt),
+			ExpiresAt: jwt.NewNumericDate(pat.ExpiresAt),
+		},
+	}, nil
+}
+
+// normalizeScopes merges duplicate resources and drops empty entries
+func normalizeScopes(scopes []utils.Permission) []utils.Permission {
+	var normalized []utils.Permission
+	index := map[string]int{}
+	for _, scope := range scopes {
+		resource := strings.TrimSpace(scope.Resource)
+		for _, action := range scope.Actions {
+			action = strings.TrimSpace(action)
+			if resource == "" || action == "" {
+				continue
+			}
+			i, ok := index[resource]
+			if !ok {
+				i = len(normalized)
+				index[resource] = i
+				normalized = append(normalized, utils.Permission{Resource: resource})
+			}
+			if !utils.HasPermission(normalized[i:i+1], resource, action) {
+				normalized[i].Actions = append(normalized[i].Actions, action)
+			}
+		}
+	}
+	return normalized
+}
+
+func withinPermissions(scopes, permissions []utils.Permission) bool {
+	for _, scope := range scopes {
+		for _, action := range scope.Actions {
+			if !utils.HasPermission(permissions, scope.Resource, action) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+func intersectPermissions(scopes, permissions []utils.Permission) []utils.Permission {
+	var granted []utils.Permission
+	for _, scope := range scopes {
+		var actions []string
+		for _, action := range scope.Actions {
+			if utils.HasPermission(permissions, scope.Resource, action) {
+				actions = append(actions, action)
+			}
+		}
+		if len(actions) > 0 {
+			granted = append(granted, utils.Permission{Resource: scope.Resource, Actions: actions})
+		}
+	}
+	return granted
+}
+
+// PersonalAccessTokenAuthenticator lets AuthMiddleware accept API tokens
+type PersonalAccessTokenAuthenticator struct {
+	db     *gorm.DB
+	tokens PersonalAccessTokenService
+}
+
+func NewPersonalAccessTokenAuthenticator(db *gorm.DB, tokens PersonalAccessTokenService) *PersonalAccessTokenAuthenticator {
+	return &PersonalAccessTokenAuthenticator{db: db, tokens: tokens}
+}
+
+func (a *PersonalAccessTokenAuthenticator) AuthenticatePersonalAccessToken(token, ipAddress string) (*utils.Claims, error) {
+	return a.tokens.Authenticate(a.db, token, ipAddress)
+}
@@ -0,0 +1,36 @@
+package services
+
+import (
+	"task-manager/backend/internal/utils"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNormalizeScopes(t *testing.T) {
+	scopes := normalizeScopes([]utils.Permission{
+		{Resource: "task", Actions: []string{"read", " write "}},
+		{Resource: "profile", Actions: []string{""}},
+		{Resource: "task", Actions: []string{"read", "create"}},
+		{Resource: " ", Actions: []string{"read"}},
+	})
+
+	assert.Equal(t, []utils.Permission{{Resource: "task", Actions: []string{"read", "write", "create"}}}, scopes)
+}
+
+func TestPersonalAccessTokenScopes(t *testing.T) {
+	held := []utils.Permission{
+		{Resource: "task", Actions: []string{"read", "write"}},
+		{Resource: "profile", Actions: []string{"read"}},
+	}
+
+	assert.True(t, withinPermissions([]utils.Permission{{Resource: "task", Actions: []string{"read"}}}, held))
+	assert.False(t, withinPermissions([]utils.Permission{{Resource: "task", Actions: []string{"create"}}}, held))
+
+	// A token keeps only what its owner still holds
+	scopes := []utils.Permission{
+		{Resource: "task", Actions: []string{"read", "create"}},
+		{Resource: "webhook", Actions: []string{"write"}},
+	}
+	assert.Equal(t, []utils.Permission{{Resource: "task", Actions: []string{"read"}}}, intersectPermissions(scopes, held))
+}
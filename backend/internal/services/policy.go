@@ -0,0 +1,312 @@
+package services
+
+import (
+	"fmt"
+	"strings"
+	"task-manager/backend/internal/models"
+
+	"github.com/gofrs/uuid"
+	"gorm.io/gorm"
+)
+
+// Resource types and actions the access policies are declared for
+const (
+	PolicyResourceTask = "task"
+	PolicyResourceUser = "user"
+
+	PolicyActionRead   = "read"
+	PolicyActionWrite  = "write"
+	PolicyActionDelete = "delete"
+	PolicyActionShare  = "share"
+)
+
+// PolicySubject is the user a decision is made for, with the teams they
+// are a member of
+type PolicySubject struct {
+	UserID  uuid.UUID
+	IsAdmin bool
+	TeamIDs []uuid.UUID
+}
+
+// PolicyResource carries the attributes of the resource rules look at. For
+// a user, ID and OwnerID are both the user's ID. TeamAccess and UserAccess
+// are the access levels of each team and user a task is shared with.
+type PolicyResource struct {
+	Type       string
+	ID         uuid.UUID
+	OwnerID    uuid.UUID
+	TeamAccess map[uuid.UUID]string
+	UserAccess map[uuid.UUID]string
+}
+
+// PolicyRule allows some actions on a resource type. Subject limits the
+// rule to certain users and Condition to certain resources; a nil func
+// matches everything. Scope is the SQL equivalent of Condition, used to
+// narrow list queries, and must match exactly the rows Condition accepts;
+// rules without one only apply to single resources.
+type PolicyRule struct {
+	Name        string
+	Description string
+	Resource    string
+	Actions     []string
+	Subject     func(subject PolicySubject) bool
+	Condition   func(subject PolicySubject, resource PolicyResource) bool
+	Scope       func(subject PolicySubject) (string, []interface{})
+}
+
+func (r PolicyRule) covers(resource, action string) bool {
+	if r.Resource != resource {
+		return false
+	}
+	for _, a := range r.Actions {
+		if a == action {
+			return true
+		}
+	}
+	return false
+}
+
+func (r PolicyRule) appliesTo(subject PolicySubject) bool {
+	return r.Subject == nil || r.Subject(subject)
+}
+
+// PolicyDecision explains why access was allowed or denied
+type PolicyDecision struct {
+	Allowed bool   `json:"allowed"`
+	Rule    string `json:"rule,omitempty"`
+	Reason  string `json:"reason"`
+}
+
+// AccessDeniedError is returned when no rule allows an action. Message is
+// what callers have always been told; the decision says which rules were
+// tried.
+type AccessDeniedError struct {
+	Message  string
+	Decision PolicyDecision
+}
+
+func (e *AccessDeniedError) Error() string {
+	return e.Message
+}
+
+// PolicyEngine decides who may do what to which resource. Rules only ever
+// allow; an action no rule allows is denied.
+type PolicyEngine struct {
+	rules []PolicyRule
+}
+
+func NewPolicyEngine(rules ...PolicyRule) *PolicyEngine {
+	return &PolicyEngine{rules: rules}
+}
+
+// DefaultPolicyRules are the ownership and sharing rules for tasks and the
+// rules for user profiles
+func DefaultPolicyRules() []PolicyRule {
+	isAdmin := func(subject PolicySubject) bool { return subject.IsAdmin }
+	isOwner := func(subject PolicySubject, resource PolicyResource) bool {
+		return resource.OwnerID == subject.UserID
+	}
+	ownedBy := func(subject PolicySubject) (string, []interface{}) {
+		return "user_id = ?", []interface{}{subject.UserID}
+	}
+	sharedWithTeam := func(levels ...string) func(PolicySubject, PolicyResource) bool {
+		return func(subject PolicySubject, resource PolicyResource) bool {
+			for _, teamID := range subject.TeamIDs {
+				for _, level := range levels {
+					if resource.TeamAccess[teamID] == level {
+						return true
+					}
+				}
+			}
+			return false
+		}
+	}
+	sharedWithTeamScope := func(levels ...string) func(PolicySubject) (string, []interface{}) {
+		return func(subject PolicySubject) (string, []interface{}) {
+			return "id IN (SELECT task_id FROM task_team_shares WHERE access IN ? " +
+				"AND team_id IN (SELECT team_id FROM team_members WHERE user_id = ?))", []interface{}{levels, subject.UserID}
+		}
+	}
+	sharedWithUser := func(levels ...string) func(PolicySubject, PolicyResource) bool {
+		return func(subject PolicySubject, resource PolicyResource) bool {
+			for _, level := range levels {
+				if resource.UserAccess[subject.UserID] == level {
+					return true
+				}
+			}
+			return false
+		}
+	}
+	sharedWithUserScope := func(levels ...string) func(PolicySubject) (string, []interface{}) {
+		return func(subject PolicySubject) (string, []interface{}) {
+			return "id IN (SELECT task_id FROM task_user_shares WHERE access IN ? AND user_id = ?)", []interface{}{levels, subject.UserID}
+		}
+	}
+
+	return []PolicyRule{
+		{
+			Name:        "task-admin",
+			Description: "admins can view and delete any task",
+			Resource:    PolicyResourceTask,
+			Actions:     []string{PolicyActionRead, PolicyActionDelete},
+			Subject:     isAdmin,
+		},
+		{
+			Name:        "task-owner",
+			Description: "owners have full access to their tasks",
+			Resource:    PolicyResourceTask,
+			Actions:     []string{PolicyActionRead, PolicyActionWrite, PolicyActionDelete, PolicyActionShare},
+			Condition:   isOwner,
+			Scope:       ownedBy,
+		},
+		{
+			Name:        "task-team-read",
+			Description: "members of a team the task is shared with can view it",
+			Resource:    PolicyResourceTask,
+			Actions:     []string{PolicyActionRead},
+			Condition:   sharedWithTeam(models.TaskShareAccessRead, models.TaskShareAccessWrite),
+			Scope:       sharedWithTeamScope(models.TaskShareAccessRead, models.TaskShareAccessWrite),
+		},
+		{
+			Name:        "task-team-write",
+			Description: "members of a team the task is shared with for writing can update it",
+			Resource:    PolicyResourceTask,
+			Actions:     []string{PolicyActionWrite},
+			Condition:   sharedWithTeam(models.TaskShareAccessWrite),
+			Scope:       sharedWithTeamScope(models.TaskShareAccessWrite),
+		},
+		{
+			Name:        "task-user-read",
+			Description: "users the task is shared with can view it",
+			Resource:    PolicyResourceTask,
+			Actions:     []string{PolicyActionRead},
+			Condition:   sharedWithUser(models.TaskShareAccessRead, models.TaskShareAccessWrite),
+			Scope:       sharedWithUserScope(models.TaskShareAccessRead, models.TaskShareAccessWrite),
+		},
+		{
+			Name:        "task-user-write",
+			Description: "users the task is shared with for writing can update it",
+			Resource:    PolicyResourceTask,
+			Actions:     []string{PolicyActionWrite},
+			Condition:   sharedWithUser(models.TaskShareAccessWrite),
+			Scope:       sharedWithUserScope(models.TaskShareAccessWrite),
+		},
+		{
+			Name:        "user-admin",
+			Description: "admins can view every user",
+			Resource:    PolicyResourceUser,
+			Actions:     []string{PolicyActionRead},
+			Subject:     isAdmin,
+		},
+		{
+			Name:        "user-self",
+			Description: "users can view themselves",
+			Resource:    PolicyResourceUser,
+			Actions:     []string{PolicyActionRead},
+			Condition:   isOwner,
+			Scope: func(subject PolicySubject) (string, []interface{}) {
+				return "id = ?", []interface{}{subject.UserID}
+			},
+		},
+	}
+}
+
+var defaultPolicies = NewPolicyEngine(DefaultPolicyRules()...)
+
+// Policies returns the engine handlers and services check access with
+func Policies() *PolicyEngine {
+	return defaultPolicies
+}
+
+// Evaluate returns the first rule allowing the action, or a denial listing
+// the rules that could have allowed it
+func (e *PolicyEngine) Evaluate(subject PolicySubject, action string, resource PolicyResource) PolicyDecision {
+	var candidates []string
+	for _, rule := range e.rules {
+		if !rule.covers(resource.Type, action) {
+			continue
+		}
+		if rule.appliesTo(subject) && (rule.Condition == nil || rule.Condition(subject, resource)) {
+			return PolicyDecision{Allowed: true, Rule: rule.Name, Reason: rule.Description}
+		}
+		candidates = append(candidates, fmt.Sprintf("%s (%s)", rule.Name, rule.Description))
+	}
+
+	if len(candidates) == 0 {
+		return PolicyDecision{Reason: fmt.Sprintf("no rule allows %s:%s", resource.Type, action)}
+	}
+	return PolicyDecision{Reason: fmt.Sprintf("%s:%s is only allowed by %s", resource.Type, action, strings.Join(candidates, "; "))}
+}
+
+// Authorize returns an *AccessDeniedError with message when the action is
+// denied
+func (e *PolicyEngine) Authorize(subject PolicySubject, action string, resource PolicyResource, message string) error {
+	decision := e.Evaluate(subject, action, resource)
+	if !decision.Allowed {
+		return &AccessDeniedError{Message: message, Decision: decision}
+	}
+	return nil
+}
+
+// Scope narrows a list query on resourceType to the rows the subject may
+// perform action on
+func (e *PolicyEngine) Scope(query *gorm.DB, subject PolicySubject, resourceType, action string) *gorm.DB {
+	var clauses []string
+	var args []interface{}
+	for _, rule := range e.rules {
+		if !rule.covers(resourceType, action) || !rule.appliesTo(subject) {
+			continue
+		}
+		if rule.Condition == nil {
+			// The rule allows every resource of the type
+			return query
+		}
+		if rule.Scope == nil {
+			continue
+		}
+		clause, clauseArgs := rule.Scope(subject)
+		clauses = append(clauses, "("+clause+")")
+		args = append(args, clauseArgs...)
+	}
+
+	if len(clauses) == 0 {
+		return query.Where("1 = 0")
+	}
+	return query.Where(strings.Join(clauses, " OR "), args...)
+}
+
+// taskPolicySubject loads the attributes of a user the task rules look at
+func taskPolicySubject(db *gorm.DB, userID uuid.UUID, isAdmin bool) (PolicySubject, error) {
+	teamIDs, err := userTeamIDs(db, userID)
+	if err != nil {
+		return PolicySubject{}, err
+	}
+	return PolicySubject{UserID: userID, IsAdmin: isAdmin, TeamIDs: teamIDs}, nil
+}
+
+// taskPolicyResource loads the attributes of a task the task rules look at
+func taskPolicyResource(db *gorm.DB, task models.Task) (PolicyResource, error) {
+	var teamShares []models.TaskTeamShare
+	if err := db.Where("task_id = ?", task.ID).Find(&teamShares).Error; err != nil {
+		return PolicyResource{}, err
+	}
+	var userShares []models.TaskUserShare
+	if err := db.Where("task_id = ?", task.ID).Find(&userShares).Error; err != nil {
+		return PolicyResource{}, err
+	}
+
+	resource := PolicyResource{
+		Type:       PolicyResourceTask,
+		ID:         task.ID,
+		OwnerID:    task.UserID,
+		TeamAccess: make(map[uuid.UUID]string, len(teamShares)),
+		UserAccess: make(map[uuid.UUID]string, len(userShares)),
+	}
+	for _, share := range teamShares {
+		resource.TeamAccess[share.TeamID] = share.Access
+	}
+	for _, share := range userShares {
+		resource.UserAccess[share.UserID] = share.Access
+	}
+	return resource, nil
+}
@@ -0,0 +1,136 @@
+package services
+
+import (
+	"errors"
+	"task-manager/backend/internal/models"
+	"testing"
+
+	"github.com/gofrs/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+func TestPolicyEngine_Evaluate(t *testing.T) {
+	engine := NewPolicyEngine(DefaultPolicyRules()...)
+	owner := PolicySubject{UserID: uuid.Must(uuid.NewV4())}
+	other := PolicySubject{UserID: uuid.Must(uuid.NewV4())}
+	admin := PolicySubject{UserID: uuid.Must(uuid.NewV4()), IsAdmin: true}
+	task := PolicyResource{Type: PolicyResourceTask, ID: uuid.Must(uuid.NewV4()), OwnerID: owner.UserID}
+
+	decision := engine.Evaluate(owner, PolicyActionWrite, task)
+	assert.True(t, decision.Allowed)
+	assert.Equal(t, "task-owner", decision.Rule)
+
+	assert.True(t, engine.Evaluate(admin, PolicyActionDelete, task).Allowed)
+	assert.False(t, engine.Evaluate(admin, PolicyActionWrite, task).Allowed)
+
+	decision = engine.Evaluate(other, PolicyActionRead, task)
+	assert.False(t, decision.Allowed)
+	assert.Contains(t, decision.Reason, "task-admin")
+	assert.Contains(t, decision.Reason, "task-owner")
+
+	decision = engine.Evaluate(owner, "archive", task)
+	assert.False(t, decision.Allowed)
+	assert.Equal(t, "no rule allows task:archive", decision.Reason)
+
+	self := PolicyResource{Type: PolicyResourceUser, ID: other.UserID, OwnerID: other.UserID}
+	assert.True(t, engine.Evaluate(other, PolicyActionRead, self).Allowed)
+	assert.False(t, engine.Evaluate(owner, PolicyActionRead, self).Allowed)
+}
+
+func TestPolicyEngine_Authorize(t *testing.T) {
+	engine := NewPolicyEngine(DefaultPolicyRules()...)
+	task := PolicyResource{Type: PolicyResourceTask, OwnerID: uuid.Must(uuid.NewV4())}
+
+	err := engine.Authorize(PolicySubject{UserID: uuid.Must(uuid.NewV4())}, PolicyActionDelete, task, "cannot delete")
+	var deniedErr *AccessDeniedError
+	require.True(t, errors.As(err, &deniedErr))
+	assert.Equal(t, "cannot delete", deniedErr.Error())
+	assert.False(t, deniedErr.Decision.Allowed)
+
+	assert.NoError(t, engine.Authorize(PolicySubject{UserID: task.OwnerID}, PolicyActionDelete, task, "cannot delete"))
+}
+
+// Scope must list exactly the tasks Evaluate allows one at a time
+func TestPolicyEngine_ScopeMatchesEvaluate(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	require.NoError(t, err)
+	require.NoError(t, db.Exec("CREATE TABLE tasks (id TEXT PRIMARY KEY, title TEXT, user_id TEXT, deleted_at DATETIME)").Error)
+	require.NoError(t, db.Exec("CREATE TABLE team_members (id TEXT PRIMARY KEY, team_id TEXT, user_id TEXT, created_at DATETIME)").Error)
+	require.NoError(t, db.Exec("CREATE TABLE task_team_shares (id TEXT PRIMARY KEY, task_id TEXT, team_id TEXT, access TEXT, shared_by TEXT, created_at DATETIME, updated_at DATETIME)").Error)
+	require.NoError(t, db.Exec("CREATE TABLE task_user_shares (id TEXT PRIMARY KEY, task_id TEXT, user_id TEXT, access TEXT, shared_by TEXT, created_at DATETIME, updated_at DATETIME)").Error)
+
+	engine := NewPolicyEngine(DefaultPolicyRules()...)
+	alice := uuid.Must(uuid.NewV4())
+	bob := uuid.Must(uuid.NewV4())
+	carol := uuid.Must(uuid.NewV4())
+	readers := uuid.Must(uuid.NewV4())
+	writers := uuid.Must(uuid.NewV4())
+	tasks := []models.Task{
+		{ID: uuid.Must(uuid.NewV4()), Title: "a1", UserID: alice},
+		{ID: uuid.Must(uuid.NewV4()), Title: "a2", UserID: alice},
+		{ID: uuid.Must(uuid.NewV4()), Title: "b1", UserID: bob},
+		{ID: uuid.Must(uuid.NewV4()), Title: "b2", UserID: bob},
+	}
+	for _, task := range tasks {
+		require.NoError(t, db.Exec("INSERT INTO tasks (id, title, user_id) VALUES (?, ?, ?)", task.ID, task.Title, task.UserID).Error)
+	}
+	for teamID, members := range map[uuid.UUID][]uuid.UUID{readers: {bob, carol}, writers: {carol}} {
+		for _, userID := range members {
+			require.NoError(t, db.Create(&models.TeamMember{ID: uuid.Must(uuid.NewV4()), TeamID: teamID, UserID: userID}).Error)
+		}
+	}
+	require.NoError(t, db.Create(&models.TaskTeamShare{ID: uuid.Must(uuid.NewV4()), TaskID: tasks[0].ID, TeamID: readers, Access: models.TaskShareAccessRead}).Error)
+	require.NoError(t, db.Create(&models.TaskTeamShare{ID: uuid.Must(uuid.NewV4()), TaskID: tasks[1].ID, TeamID: writers, Access: models.TaskShareAccessWrite}).Error)
+	require.NoError(t, db.Create(&models.TaskUserShare{ID: uuid.Must(uuid.NewV4()), TaskID: tasks[2].ID, UserID: alice, Access: models.TaskShareAccessRead}).Error)
+	require.NoError(t, db.Create(&models.TaskUserShare{ID: uuid.Must(uuid.NewV4()), TaskID: tasks[3].ID, UserID: carol, Access: models.TaskShareAccessWrite}).Error)
+
+	users := []uuid.UUID{alice, bob, carol, uuid.Must(uuid.NewV4())}
+	for _, isAdmin := range []bool{false, true} {
+		for _, userID := range users {
+			subject, err := taskPolicySubject(db, userID, isAdmin)
+			require.NoError(t, err)
+
+			for _, action := range []string{PolicyActionRead, PolicyActionWrite, PolicyActionDelete, PolicyActionShare} {
+				expected := []string{}
+				for _, task := range tasks {
+					resource, err := taskPolicyResource(db, task)
+					require.NoError(t, err)
+					if engine.Evaluate(subject, action, resource).Allowed {
+						expected = append(expected, task.Title)
+					}
+				}
+
+				var listed []string
+				query := engine.Scope(db.Model(&models.Task{}), subject, PolicyResourceTask, action)
+				require.NoError(t, query.Order("title").Pluck("title", &listed).Error)
+				assert.Equal(t, expected, listed, "%+v %s", subject, action)
+			}
+		}
+	}
+
+	// Carol reads a1 through one team and writes a2 through the other
+	subject, err := taskPolicySubject(db, carol, false)
+	require.NoError(t, err)
+	a1, err := taskPolicyResource(db, tasks[0])
+	require.NoError(t, err)
+	a2, err := taskPolicyResource(db, tasks[1])
+	require.NoError(t, err)
+	assert.False(t, engine.Evaluate(subject, PolicyActionWrite, a1).Allowed)
+	assert.Equal(t, "task-team-write", engine.Evaluate(subject, PolicyActionWrite, a2).Rule)
+	assert.False(t, engine.Evaluate(subject, PolicyActionDelete, a2).Allowed)
+
+	// Bob shared b1 with Alice for reading and b2 with Carol for writing
+	b1, err := taskPolicyResource(db, tasks[2])
+	require.NoError(t, err)
+	b2, err := taskPolicyResource(db, tasks[3])
+	require.NoError(t, err)
+	assert.Equal(t, "task-user-write", engine.Evaluate(subject, PolicyActionWrite, b2).Rule)
+	assert.False(t, engine.Evaluate(subject, PolicyActionShare, b2).Allowed)
+	subject, err = taskPolicySubject(db, alice, false)
+	require.NoError(t, err)
+	assert.Equal(t, "task-user-read", engine.Evaluate(subject, PolicyActionRead, b1).Rule)
+	assert.False(t, engine.Evaluate(subject, PolicyActionWrite, b1).Allowed)
+}
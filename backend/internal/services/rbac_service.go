@@ -0,0 +1,433 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"task-manager/backend/internal/models"
+	"task-manager/backend/internal/utils"
+
+	"github.com/gofrs/uuid"
+	"gorm.io/gorm"
+)
+
+// builtInRoles are looked up by name elsewhere, so they cannot be renamed
+// or deleted
+var builtInRoles = map[string]bool{"user": true, "admin": true}
+
+// RBACService manages roles, permissions and which roles hold which
+// permissions
+type RBACService interface {
+	GetRoles(db *gorm.DB) ([]models.Role, error)
+	CreateRole(db *gorm.DB, req models.CreateRoleRequest) (models.Role, error)
+	UpdateRole(db *gorm.DB, roleID uuid.UUID, req models.UpdateRoleRequest) (models.Role, error)
+	DeleteRole(db *gorm.DB, roleID uuid.UUID) error
+	GetPermissions(db *gorm.DB) ([]models.Permission, error)
+	CreatePermission(db *gorm.DB, req models.CreatePermissionRequest) (models.Permission, error)
+	DeletePermission(db *gorm.DB, permissionID uuid.UUID) error
+	GetRolePermissions(db *gorm.DB, roleID uuid.UUID) ([]models.Permission, error)
+	GrantPermission(db *gorm.DB, roleID, permissionID uuid.UUID) error
+	RevokePermission(db *gorm.DB, roleID, permissionID uuid.UUID) error
+	GetCatalogue(db *gorm.DB) ([]models.CataloguePermission, error)
+}
+
+type RBACServiceImpl struct {
+	required    func() []utils.Permission
+	revocations *RevocationStore
+	events      EventNotifier
+}
+
+// NewRBACService takes the permissions routes require, which are only known
+// once the router is set up
+func NewRBACService(required func() []utils.Permission) *RBACServiceImpl {
+	return &RBACServiceImpl{required: required}
+}
+
+// UseRevocationStore rejects the access tokens of users who lose a
+// permission straight away
+func (s *RBACServiceImpl) UseRevocationStore(revocations *RevocationStore) {
+	s.revocations = revocations
+}
+
+// UseEventNotifier wakes the event dispatcher after a change commits
+func (s *RBACServiceImpl) UseEventNotifier(events EventNotifier) {
+	s.events = events
+}
+
+func (s *RBACServiceImpl) GetRoles(db *gorm.DB) ([]models.Role, error) {
+	var roles []models.Role
+	if err := db.Order("name").Find(&roles).Error; err != nil {
+		return nil, err
+	}
+	return roles, nil
+}
+
+func (s *RBACServiceImpl) CreateRole(db *gorm.DB, req models.CreateRoleRequest) (models.Role, error) {
+	role := models.Role{
+		ID:         uuid.Must(uuid.NewV4()),
+		Name:       strings.TrimSpace(req.Name),
+		RequireMFA: req.RequireMFA,
+	}
+	if role.Name == "" {
+		return models.Role{}, errors.New("role name cannot be empty")
+	}
+
+	err := db.Transaction(func(tx *gorm.DB) error {
+		if err := ensureRoleNameFree(tx, role.Name, uuid.Nil); err != nil {
+			return err
+		}
+		return tx.Create(&role).Error
+	})
+	if err != nil {
+		return models.Role{}, err
+	}
+	return role, nil
+}
+
+func (s *RBACServiceImpl) UpdateRole(db *gorm.DB, roleID uuid.UUID, req models.UpdateRoleRequest) (models.Role, error) {
+	var role models.Role
+	err := db.Transaction(func(tx *gorm.DB) error {
+		var err error
+		if role, err = findRole(tx, roleID); err != nil {
+			return err
+		}
+
+		updates := map[string]interface{}{}
+		if req.Name != nil {
+			name := strings.TrimSpace(*req.Name)
+			if name == "" {
+				return errors.New("role name cannot be empty")
+			}
+			if name != role.Name {
+				if builtInRoles[role.Name] {
+					return errors.New("built-in roles cannot be renamed")
+				}
+				if err := ensureRoleNameFree(tx, name, roleID); err != nil {
+					return err
+				}
+				if err := recordRolePermissionsChanged(tx, roleID, uuid.Nil); err != nil {
+					return err
+				}
+				updates["name"] = name
+			}
+		}
+		if req.RequireMFA != nil {
+			updates["require_mfa"] = *req.RequireMFA
+		}
+
+		if len(updates) == 0 {
+			return nil
+		}
+		return tx.Model(&role).Updates(updates).Error
+	})
+	if err != nil {
+		return models.Role{}, err
+	}
+	s.notify()
+	return findRole(db, roleID)
+}
+
+// DeleteRole takes the role away from everyone holding it
+func (s *RBACServiceImpl) DeleteRole(db *gorm.DB, roleID uuid.UUID) error {
+	var holders []uuid.UUID
+	err := db.Transaction(func(tx *gorm.DB) error {
+		role, err := findRole(tx, roleID)
+		if err != nil {
+			return err
+		}
+		if builtInRoles[role.Name] {
+			return errors.New("built-in roles cannot be deleted")
+		}
+
+		if holders, err = roleHolders(tx, []uuid.UUID{roleID}); err != nil {
+			return err
+		}
+
+		// Deleted for good, as permissions are looked up without regard to
+		// soft deletes
+		if err := tx.Unscoped().Where("role_id = ?", roleID).Delete(&models.UserRole{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Unscoped().Where("role_id = ?", roleID).Delete(&models.RolePermission{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("role_id = ?", roleID).Delete(&models.TeamRole{}).Error; err != nil {
+			return err
+		}
+		if err := recordRolePermissionsChanged(tx, roleID, uuid.Nil); err != nil {
+			return err
+		}
+		return tx.Unscoped().Where("id = ?", roleID).Delete(&models.Role{}).Error
+	})
+	if err != nil {
+		return err
+	}
+	s.notify()
+	return s.revokeUsers(db, holders)
+}
+
+func (s *RBACServiceImpl) GetPermissions(db *gorm.DB) ([]models.Permission, error) {
+	var permissions []models.Permission
+	if err := db.Order("resource, action").Find(&permissions).Error; err != nil {
+		return nil, err
+	}
+	return permissions, nil
+}
+
+func (s *RBACServiceImpl) CreatePermission(db *gorm.DB, req models.CreatePermissionRequest) (models.Permission, error) {
+	permission := models.Permission{
+		ID:       uuid.Must(uuid.NewV4()),
+		Resource: strings.TrimSpace(req.Resource),
+		Action:   strings.TrimSpace(req.Action),
+	}
+	if permission.Resource == "" || permission.Action == "" {
+		return models.Permission{}, errors.New("resource and action are required")
+	}
+
+	err := db.Transaction(func(tx *gorm.DB) error {
+		var count int64
+		if err := tx.Model(&models.Permission{}).
+			Where("resource = ? AND action = ?", permission.Resource, permission.Action).
+			Count(&count).Error; err != nil {
+			return err
+		}
+		if count > 0 {
+			return errors.New("permission already exists")
+		}
+		return tx.Create(&permission).Error
+	})
+	if err != nil {
+		return models.Permission{}, err
+	}
+	return permission, nil
+}
+
+// DeletePermission refuses permissions a route requires, since nobody could
+// pass that route any more
+func (s *RBACServiceImpl) DeletePermission(db *gorm.DB, permissionID uuid.UUID) error {
+	var holders []uuid.UUID
+	err := db.Transaction(func(tx *gorm.DB) error {
+		permission, err := findPermission(tx, permissionID)
+		if err != nil {
+			return err
+		}
+		if utils.HasPermission(s.required(), permission.Resource, permission.Action) {
+			return errors.New("permission is required by a route")
+		}
+
+		var roleIDs []uuid.UUID
+		if err := tx.Model(&models.RolePermission{}).Where("permission_id = ?", permissionID).Pluck("role_id", &roleIDs).Error; err != nil {
+			return err
+		}
+		if holders, err = roleHolders(tx, roleIDs); err != nil {
+			return err
+		}
+
+		if err := tx.Unscoped().Where("permission_id = ?", permissionID).Delete(&models.RolePermission{}).Error; err != nil {
+			return err
+		}
+		for _, roleID := range roleIDs {
+			if err := recordRolePermissionsChanged(tx, roleID, uuid.Nil); err != nil {
+				return err
+			}
+		}
+		return tx.Unscoped().Where("id = ?", permissionID).Delete(&models.Permission{}).Error
+	})
+	if err != nil {
+		return err
+	}
+	s.notify()
+	return s.revokeUsers(db, holders)
+}
+
+func (s *RBACServiceImpl) GetRolePermissions(db *gorm.DB, roleID uuid.UUID) ([]models.Permission, error) {
+	if _, err := findRole(db, roleID); err != nil {
+		return nil, err
+	}
+
+	var permissions []models.Permission
+	err := db.Where("id IN (?)", db.Model(&models.RolePermission{}).Select("permission_id").Where("role_id = ?", roleID)).
+		Order("resource, action").Find(&permissions).Error
+	return permissions, err
+}
+
+func (s *RBACServiceImpl) GrantPermission(db *gorm.DB, roleID, permissionID uuid.UUID) error {
+	err := db.Transaction(func(tx *gorm.DB) error {
+		if _, err := findRole(tx, roleID); err != nil {
+			return err
+		}
+		if _, err := findPermission(tx, permissionID); err != nil {
+			return err
+		}
+
+		var count int64
+		if err := tx.Model(&models.RolePermission{}).Where("role_id = ? AND permission_id = ?", roleID, permissionID).Count(&count).Error; err != nil {
+			return err
+		}
+		if count > 0 {
+			return errors.New("permission already granted")
+		}
+
+		if err := tx.Create(&models.RolePermission{ID: uuid.Must(uuid.NewV4()), RoleID: roleID, PermissionID: permissionID}).Error; err != nil {
+			return err
+		}
+		return recordRolePermissionsChanged(tx, roleID, uuid.Nil)
+	})
+	if err != nil {
+		return err
+	}
+	s.notify()
+	return nil
+}
+
+func (s *RBACServiceImpl) RevokePermission(db *gorm.DB, roleID, permissionID uuid.UUID) error {
+	var holders []uuid.UUID
+	err := db.Transaction(func(tx *gorm.DB) error {
+		if _, err := findRole(tx, roleID); err != nil {
+			return err
+		}
+
+		result := tx.Unscoped().Where("role_id = ? AND permission_id = ?", roleID, permissionID).Delete(&models.RolePermission{})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return errors.New("permission not granted")
+		}
+		if err := recordRolePermissionsChanged(tx, roleID, uuid.Nil); err != nil {
+			return err
+		}
+
+		var err error
+		holders, err = roleHolders(tx, []uuid.UUID{roleID})
+		return err
+	})
+	if err != nil {
+		return err
+	}
+	s.notify()
+	return s.revokeUsers(db, holders)
+}
+
+// GetCatalogue lists the permissions routes require, whether each exists
+// and which roles hold it
+func (s *RBACServiceImpl) GetCatalogue(db *gorm.DB) ([]models.CataloguePermission, error) {
+	var permissions []models.Permission
+	if err := db.Find(&permissions).Error; err != nil {
+		return nil, err
+	}
+	byKey := map[string]models.Permission{}
+	for _, permission := range permissions {
+		byKey[permission.Resource+":"+permission.Action] = permission
+	}
+
+	var grants []struct {
+		PermissionID uuid.UUID
+		Name         string
+	}
+	if err := db.Model(&models.RolePermission{}).
+		Select("role_permissions.permission_id, roles.name").
+		Joins("JOIN roles ON roles.id = role_permissions.role_id AND roles.deleted_at IS NULL").
+		Order("roles.name").Scan(&grants).Error; err != nil {
+		return nil, err
+	}
+	roles := map[uuid.UUID][]string{}
+	for _, grant := range grants {
+		roles[grant.PermissionID] = append(roles[grant.PermissionID], grant.Name)
+	}
+
+	catalogue := []models.CataloguePermission{}
+	for _, required := range s.required() {
+		for _, action := range required.Actions {
+			entry := models.CataloguePermission{Resource: required.Resource, Action: action, Roles: []string{}}
+			if permission, ok := byKey[required.Resource+":"+action]; ok {
+				id := permission.ID
+				entry.PermissionID = &id
+				if held := roles[id]; held != nil {
+					entry.Roles = held
+				}
+			}
+			catalogue = append(catalogue, entry)
+		}
+	}
+	return catalogue, nil
+}
+
+func (s *RBACServiceImpl) notify() {
+	if s.events != nil {
+		s.events.Notify()
+	}
+}
+
+func (s *RBACServiceImpl) revokeUsers(db *gorm.DB, userIDs []uuid.UUID) error {
+	if s.revocations == nil {
+		return nil
+	}
+	for _, userID := range userIDs {
+		if err := s.revocations.RevokeUser(db, userID); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ValidateRequiredPermissions fails if a route requires a permission that
+// is not in the permissions table, as no role could ever pass that route
+func ValidateRequiredPermissions(db *gorm.DB, required []utils.Permission) error {
+	var permissions []models.Permission
+	if err := db.Find(&permissions).Error; err != nil {
+		return err
+	}
+	existing := map[string]bool{}
+	for _, permission := range permissions {
+		existing[permission.Resource+":"+permission.Action] = true
+	}
+
+	var missing []string
+	for _, permission := range required {
+		for _, action := range permission.Actions {
+			if key := permission.Resource + ":" + action; !existing[key] {
+				missing = append(missing, key)
+			}
+		}
+	}
+	if len(missing) > 0 {
+		return fmt.Errorf("routes require permissions that do not exist: %s", strings.Join(missing, ", "))
+	}
+	return nil
+}
+
+func findPermission(db *gorm.DB, permissionID uuid.UUID) (models.Permission, error) {
+	var permission models.Permission
+	result := db.Where("id = ?", permissionID).Limit(1).Find(&permission)
+	if result.Error != nil {
+		return permission, result.Error
+	}
+	if result.RowsAffected == 0 {
+		return permission, errors.New("permission not found")
+	}
+	return permission, nil
+}
+
+func ensureRoleNameFree(db *gorm.DB, name string, roleID uuid.UUID) error {
+	var count int64
+	if err := db.Model(&models.Role{}).Where("LOWER(name) = LOWER(?) AND id <> ?", name, roleID).Count(&count).Error; err != nil {
+		return err
+	}
+	if count > 0 {
+		return errors.New("role already exists")
+	}
+	return nil
+}
+
+func roleHolders(db *gorm.DB, roleIDs []uuid.UUID) ([]uuid.UUID, error) {
+	var userIDs []uuid.UUID
+	if len(roleIDs) == 0 {
+		return userIDs, nil
+	}
+	err := db.Raw("SELECT user_id FROM user_roles WHERE role_id IN ? "+
+		"UNION SELECT team_members.user_id FROM team_members "+
+		"JOIN team_roles ON team_roles.team_id = team_members.team_id WHERE team_roles.role_id IN ?", roleIDs, roleIDs).
+		Scan(&userIDs).Error
+	return userIDs, err
+}
@@ -0,0 +1,179 @@
+package services
+
+import (
+	"task-manager/backend/internal/models"
+	"task-manager/backend/internal/utils"
+	"testing"
+
+	"github.com/gofrs/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+func requiredTestPermissions() []utils.Permission {
+	return []utils.Permission{
+		{Resource: "task", Actions: []string{"read", "write"}},
+		{Resource: "profile", Actions: []string{"read"}},
+	}
+}
+
+func createTestPermission(db *gorm.DB, resource, action string) models.Permission {
+	permission := models.Permission{ID: uuid.Must(uuid.NewV4()), Resource: resource, Action: action}
+	db.Create(&permission)
+	return permission
+}
+
+func TestRBACService_RoleCRUD(t *testing.T) {
+	db := setupTestDB()
+	rbacService := NewRBACService(requiredTestPermissions)
+	createTestRole(db, "admin")
+
+	role, err := rbacService.CreateRole(db, models.CreateRoleRequest{Name: "  editor  "})
+	require.NoError(t, err)
+	assert.Equal(t, "editor", role.Name)
+
+	_, err = rbacService.CreateRole(db, models.CreateRoleRequest{Name: "Editor"})
+	assert.EqualError(t, err, "role already exists")
+	_, err = rbacService.CreateRole(db, models.CreateRoleRequest{Name: " "})
+	assert.EqualError(t, err, "role name cannot be empty")
+
+	requireMFA := true
+	updated, err := rbacService.UpdateRole(db, role.ID, models.UpdateRoleRequest{Name: stringPtr("reviewer"), RequireMFA: &requireMFA})
+	require.NoError(t, err)
+	assert.Equal(t, "reviewer", updated.Name)
+	assert.True(t, updated.RequireMFA)
+
+	_, err = rbacService.UpdateRole(db, role.ID, models.UpdateRoleRequest{Name: stringPtr("ADMIN")})
+	assert.EqualError(t, err, "role already exists")
+
+	roles, err := rbacService.GetRoles(db)
+	require.NoError(t, err)
+	assert.Len(t, roles, 2)
+
+	assert.NoError(t, rbacService.DeleteRole(db, role.ID))
+	assert.EqualError(t, rbacService.DeleteRole(db, role.ID), "role not found")
+}
+
+func TestRBACService_BuiltInRolesAreProtected(t *testing.T) {
+	db := setupTestDB()
+	rbacService := NewRBACService(requiredTestPermissions)
+	admin := createTestRole(db, "admin")
+
+	_, err := rbacService.UpdateRole(db, admin.ID, models.UpdateRoleRequest{Name: stringPtr("superuser")})
+	assert.EqualError(t, err, "built-in roles cannot be renamed")
+	assert.EqualError(t, rbacService.DeleteRole(db, admin.ID), "built-in roles cannot be deleted")
+
+	// Other settings of built-in roles can still change
+	requireMFA := true
+	updated, err := rbacService.UpdateRole(db, admin.ID, models.UpdateRoleRequest{RequireMFA: &requireMFA})
+	assert.NoError(t, err)
+	assert.True(t, updated.RequireMFA)
+}
+
+func TestRBACService_DeleteRoleTakesItFromHolders(t *testing.T) {
+	db := setupTestDB()
+	rbacService := NewRBACService(requiredTestPermissions)
+
+	role := createTestRole(db, "editor")
+	permission := createTestPermission(db, "task", "write")
+	user := createTestUser(db, "ada", "password123")
+	grantTestRole(db, user.ID, role.ID)
+	require.NoError(t, rbacService.GrantPermission(db, role.ID, permission.ID))
+
+	require.NoError(t, rbacService.DeleteRole(db, role.ID))
+
+	var userRoles, rolePermissions int64
+	db.Unscoped().Model(&models.UserRole{}).Where("role_id = ?", role.ID).Count(&userRoles)
+	db.Unscoped().Model(&models.RolePermission{}).Where("role_id = ?", role.ID).Count(&rolePermissions)
+	assert.Equal(t, int64(0), userRoles)
+	assert.Equal(t, int64(0), rolePermissions)
+}
+
+func TestRBACService_PermissionCRUD(t *testing.T) {
+	db := setupTestDB()
+	rbacService := NewRBACService(requiredTestPermissions)
+	role := createTestRole(db, "editor")
+
+	permission, err := rbacService.CreatePermission(db, models.CreatePermissionRequest{Resource: " report ", Action: "export"})
+	require.NoError(t, err)
+	assert.Equal(t, "report", permission.Resource)
+
+	_, err = rbacService.CreatePermission(db, models.CreatePermissionRequest{Resource: "report", Action: "export"})
+	assert.EqualError(t, err, "permission already exists")
+	_, err = rbacService.CreatePermission(db, models.CreatePermissionRequest{Resource: "report", Action: " "})
+	assert.EqualError(t, err, "resource and action are required")
+
+	require.NoError(t, rbacService.GrantPermission(db, role.ID, permission.ID))
+	assert.EqualError(t, rbacService.GrantPermission(db, role.ID, permission.ID), "permission already granted")
+
+	granted, err := rbacService.GetRolePermissions(db, role.ID)
+	require.NoError(t, err)
+	require.Len(t, granted, 1)
+	assert.Equal(t, permission.ID, granted[0].ID)
+
+	require.NoError(t, rbacService.RevokePermission(db, role.ID, permission.ID))
+	assert.EqualError(t, rbacService.RevokePermission(db, role.ID, permission.ID), "permission not granted")
+
+	require.NoError(t, rbacService.GrantPermission(db, role.ID, permission.ID))
+	require.NoError(t, rbacService.DeletePermission(db, permission.ID))
+	assert.EqualError(t, rbacService.DeletePermission(db, permission.ID), "permission not found")
+
+	granted, err = rbacService.GetRolePermissions(db, role.ID)
+	require.NoError(t, err)
+	assert.Empty(t, granted)
+}
+
+func TestRBACService_DeletePermissionRequiredByRoute(t *testing.T) {
+	db := setupTestDB()
+	rbacService := NewRBACService(requiredTestPermissions)
+
+	required := createTestPermission(db, "task", "write")
+	unused := createTestPermission(db, "task", "archive")
+
+	assert.EqualError(t, rbacService.DeletePermission(db, required.ID), "permission is required by a route")
+	assert.NoError(t, rbacService.DeletePermission(db, unused.ID))
+
+	permissions, err := rbacService.GetPermissions(db)
+	require.NoError(t, err)
+	require.Len(t, permissions, 1)
+	assert.Equal(t, required.ID, permissions[0].ID)
+}
+
+func TestRBACService_GetCatalogue(t *testing.T) {
+	db := setupTestDB()
+	rbacService := NewRBACService(requiredTestPermissions)
+
+	role := createTestRole(db, "editor")
+	read := createTestPermission(db, "task", "read")
+	createTestPermission(db, "task", "write")
+	require.NoError(t, rbacService.GrantPermission(db, role.ID, read.ID))
+
+	catalogue, err := rbacService.GetCatalogue(db)
+	require.NoError(t, err)
+	require.Len(t, catalogue, 3)
+
+	assert.Equal(t, "task", catalogue[0].Resource)
+	assert.Equal(t, "read", catalogue[0].Action)
+	assert.Equal(t, read.ID, *catalogue[0].PermissionID)
+	assert.Equal(t, []string{"editor"}, catalogue[0].Roles)
+
+	assert.NotNil(t, catalogue[1].PermissionID)
+	assert.Empty(t, catalogue[1].Roles)
+
+	// profile:read is required but missing
+	assert.Equal(t, "profile", catalogue[2].Resource)
+	assert.Nil(t, catalogue[2].PermissionID)
+}
+
+func TestValidateRequiredPermissions(t *testing.T) {
+	db := setupTestDB()
+	createTestPermission(db, "task", "read")
+
+	err := ValidateRequiredPermissions(db, requiredTestPermissions())
+	assert.EqualError(t, err, "routes require permissions that do not exist: task:write, profile:read")
+
+	createTestPermission(db, "task", "write")
+	createTestPermission(db, "profile", "read")
+	assert.NoError(t, ValidateRequiredPermissions(db, requiredTestPermissions()))
+}
@@ -2,6 +2,7 @@ package services
 
 import (
 	"errors"
+	"log"
 	"task-manager/backend/internal/models"
 
 	"github.com/gofrs/uuid"
@@ -12,12 +13,25 @@ type RegisterService interface {
 	RegisterUser(db *gorm.DB, user models.User) error
 }
 
-type RegisterServiceImpl struct{}
+type RegisterServiceImpl struct {
+	accounts AccountService
+	policy   *PasswordPolicy
+}
 
 func NewRegisterService() *RegisterServiceImpl {
 	return &RegisterServiceImpl{}
 }
 
+// UsePasswordPolicy rejects passwords that break the policy
+func (s *RegisterServiceImpl) UsePasswordPolicy(policy *PasswordPolicy) {
+	s.policy = policy
+}
+
+// UseEmailVerification emails new users a link to confirm their address
+func (s *RegisterServiceImpl) UseEmailVerification(accounts AccountService) {
+	s.accounts = accounts
+}
+
 func (s *RegisterServiceImpl) RegisterUser(db *gorm.DB, user models.User) error {
 	// Check if username or email already exists
 	var existingUser models.User
@@ -29,6 +43,12 @@ func (s *RegisterServiceImpl) RegisterUser(db *gorm.DB, user models.User) error
 		return result.Error
 	}
 
+	if s.policy != nil {
+		if err := s.policy.Validate(db, user.Password, user); err != nil {
+			return err
+		}
+	}
+
 	// Hash password
 	hashedPassword, err := HashPassword(user.Password)
 	if err != nil {
@@ -54,5 +74,18 @@ func (s *RegisterServiceImpl) RegisterUser(db *gorm.DB, user models.User) error
 		return err
 	}
 
+	if s.policy != nil {
+		if err := s.policy.Remember(db, user.ID, user.Password); err != nil {
+			return err
+		}
+	}
+
+	// The account exists either way; the user can ask for another link
+	if s.accounts != nil {
+		if err := s.accounts.SendVerificationEmail(db, user.ID); err != nil {
+			log.Printf("Failed to send verification email to user %s: %v", user.ID, err)
+		}
+	}
+
 	return nil
 }
@@ -0,0 +1,199 @@
+package services
+
+import (
+	"log"
+	"sync"
+	"task-manager/backend/internal/models"
+	"task-manager/backend/internal/utils"
+	"time"
+
+	"github.com/gofrs/uuid"
+	"gorm.io/gorm"
+)
+
+// RevocationStore keeps revoked access tokens in memory so AuthMiddleware
+// can check them without a query. With persistence enabled every revocation
+// is also written to token_revocations, which replicas load on start and
+// poll to learn about each other's revocations.
+type RevocationStore struct {
+	mutex    sync.RWMutex
+	persist  bool
+	tokens   map[string]time.Time
+	sessions map[uuid.UUID]time.Time
+	users    map[uuid.UUID]time.Time
+	synced   time.Time
+}
+
+func NewRevocationStore(persist bool) *RevocationStore {
+	return &RevocationStore{
+		persist:  persist,
+		tokens:   make(map[string]time.Time),
+		sessions: make(map[uuid.UUID]time.Time),
+		users:    make(map[uuid.UUID]time.Time),
+	}
+}
+
+// IsRevoked reports whether a validated access token must be rejected
+func (s *RevocationStore) IsRevoked(claims *utils.Claims) bool {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	if _, ok := s.tokens[claims.ID]; ok {
+		return true
+	}
+
+	issuedAt := time.Time{}
+	if claims.IssuedAt != nil {
+		issuedAt = claims.IssuedAt.Time
+	}
+
+	// Tokens issued in the second of the revocation or later are valid
+	if revokedAt, ok := s.sessions[claims.SessionID]; ok && issuedAt.Before(revokedAt) {
+		return true
+	}
+	if revokedAt, ok := s.users[claims.UserID]; ok && issuedAt.Before(revokedAt) {
+		return true
+	}
+
+	return false
+}
+
+// RevokeToken rejects a single access token until it expires
+func (s *RevocationStore) RevokeToken(db *gorm.DB, jti string, expiresAt time.Time) error {
+	if jti == "" {
+		return nil
+	}
+	return s.revoke(db, models.RevocationKindToken, jti, time.Now(), expiresAt)
+}
+
+// RevokeSession rejects every access token issued so far for the session
+func (s *RevocationStore) RevokeSession(db *gorm.DB, sessionID uuid.UUID) error {
+	now := time.Now()
+	return s.revoke(db, models.RevocationKindSession, sessionID.String(), now, now.Add(utils.AccessTokenLifetime))
+}
+
+// RevokeUser rejects every access token issued so far for the user, e.g.
+// after the account was deleted or lost a role
+func (s *RevocationStore) RevokeUser(db *gorm.DB, userID uuid.UUID) error {
+	now := time.Now()
+	return s.revoke(db, models.RevocationKindUser, userID.String(), now, now.Add(utils.AccessTokenLifetime))
+}
+
+func (s *RevocationStore) revoke(db *gorm.DB, kind, subject string, revokedAt, expiresAt time.Time) error {
+	// Token iat is in whole seconds, so a token minted right after the
+	// revocation would otherwise look older than it
+	revokedAt = revokedAt.Truncate(time.Second)
+
+	entry := models.TokenRevocation{
+		ID:        uuid.Must(uuid.NewV4()),
+		Kind:      kind,
+		Subject:   subject,
+		RevokedAt: revokedAt,
+		ExpiresAt: expiresAt,
+	}
+
+	s.mutex.Lock()
+	s.apply(entry)
+	s.mutex.Unlock()
+
+	if !s.persist {
+		return nil
+	}
+	return db.Create(&entry).Error
+}
+
+// apply adds an entry to the in-memory maps; the caller holds the lock
+func (s *RevocationStore) apply(entry models.TokenRevocation) {
+	switch entry.Kind {
+	case models.RevocationKindToken:
+		s.tokens[entry.Subject] = entry.ExpiresAt
+	case models.RevocationKindSession, models.RevocationKindUser:
+		id, err := uuid.FromString(entry.Subject)
+		if err != nil {
+			return
+		}
+		target := s.sessions
+		if entry.Kind == models.RevocationKindUser {
+			target = s.users
+		}
+		// Keep the latest cutoff; older tokens are covered by it
+		if current, ok := target[id]; !ok || entry.RevokedAt.After(current) {
+			target[id] = entry.RevokedAt
+		}
+	}
+}
+
+// Sync loads revocations recorded by other replicas since the last sync
+func (s *RevocationStore) Sync(db *gorm.DB) error {
+	if !s.persist {
+		return nil
+	}
+
+	s.mutex.RLock()
+	since := s.synced
+	s.mutex.RUnlock()
+
+	now := time.Now()
+
+	// Overlap a little so rows committed late are not missed
+	var entries []models.TokenRevocation
+	result := db.Where("created_at > ? AND expires_at > ?", since.Add(-time.Minute), now).Find(&entries)
+	if result.Error != nil {
+		return result.Error
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	for _, entry := range entries {
+		s.apply(entry)
+	}
+	s.synced = now
+
+	return nil
+}
+
+// Prune forgets entries whose tokens have expired anyway
+func (s *RevocationStore) Prune(now time.Time) {
+	cutoff := now.Add(-utils.AccessTokenLifetime)
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	for jti, expiresAt := range s.tokens {
+		if !expiresAt.After(now) {
+			delete(s.tokens, jti)
+		}
+	}
+	for id, revokedAt := range s.sessions {
+		if revokedAt.Before(cutoff) {
+			delete(s.sessions, id)
+		}
+	}
+	for id, revokedAt := range s.users {
+		if revokedAt.Before(cutoff) {
+			delete(s.users, id)
+		}
+	}
+}
+
+// PurgeExpired deletes persisted entries that no longer reject anything
+func (s *RevocationStore) PurgeExpired(db *gorm.DB, now time.Time) error {
+	if !s.persist {
+		return nil
+	}
+	return db.Where("expires_at <= ?", now).Delete(&models.TokenRevocation{}).Error
+}
+
+// RunRevocationSync keeps the store in step with other replicas and drops
+// expired entries from memory
+func RunRevocationSync(db *gorm.DB, store *RevocationStore, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		if err := store.Sync(db); err != nil {
+			log.Printf("Failed to sync token revocations: %v", err)
+		}
+		store.Prune(time.Now())
+	}
+}
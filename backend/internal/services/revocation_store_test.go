@@ -0,0 +1,68 @@
+package services
+
+import (
+	"task-manager/backend/internal/utils"
+	"testing"
+	"time"
+
+	"github.com/gofrs/uuid"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testClaims(userID, sessionID uuid.UUID, issuedAt time.Time) *utils.Claims {
+	return &utils.Claims{
+		UserID:    userID,
+		SessionID: sessionID,
+		RegisteredClaims: jwt.RegisteredClaims{
+			ID:        uuid.Must(uuid.NewV4()).String(),
+			IssuedAt:  jwt.NewNumericDate(issuedAt),
+			ExpiresAt: jwt.NewNumericDate(issuedAt.Add(utils.AccessTokenLifetime)),
+		},
+	}
+}
+
+func TestRevocationStore_RevokeToken(t *testing.T) {
+	store := NewRevocationStore(false)
+	claims := testClaims(uuid.Must(uuid.NewV4()), uuid.Must(uuid.NewV4()), time.Now())
+	other := testClaims(claims.UserID, claims.SessionID, time.Now())
+
+	require.NoError(t, store.RevokeToken(nil, claims.ID, claims.ExpiresAt.Time))
+	assert.True(t, store.IsRevoked(claims))
+	assert.False(t, store.IsRevoked(other))
+
+	store.Prune(claims.ExpiresAt.Time.Add(time.Second))
+	assert.False(t, store.IsRevoked(claims))
+}
+
+func TestRevocationStore_RevokeSessionAndUser(t *testing.T) {
+	store := NewRevocationStore(false)
+	userID := uuid.Must(uuid.NewV4())
+	sessionID := uuid.Must(uuid.NewV4())
+	before := time.Now().Add(-time.Minute)
+
+	require.NoError(t, store.RevokeSession(nil, sessionID))
+	assert.True(t, store.IsRevoked(testClaims(userID, sessionID, before)))
+	assert.False(t, store.IsRevoked(testClaims(userID, uuid.Must(uuid.NewV4()), before)))
+
+	require.NoError(t, store.RevokeUser(nil, userID))
+	assert.True(t, store.IsRevoked(testClaims(userID, uuid.Must(uuid.NewV4()), before)))
+
+	// Tokens issued after the revocation are accepted again
+	assert.False(t, store.IsRevoked(testClaims(userID, uuid.Must(uuid.NewV4()), time.Now().Add(time.Minute))))
+}
+
+func TestRevocationStore_AcceptsTokensMintedInTheSameSecond(t *testing.T) {
+	store := NewRevocationStore(false)
+	userID := uuid.Must(uuid.NewV4())
+	sessionID := uuid.Must(uuid.NewV4())
+
+	require.NoError(t, store.RevokeUser(nil, userID))
+	require.NoError(t, store.RevokeSession(nil, sessionID))
+
+	// iat is truncated to the second, like the tokens signed after a login
+	// or refresh that immediately follows the revocation
+	assert.False(t, store.IsRevoked(testClaims(userID, sessionID, time.Now())))
+	assert.True(t, store.IsRevoked(testClaims(userID, sessionID, time.Now().Add(-time.Second))))
+}
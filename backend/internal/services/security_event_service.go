@@ -0,0 +1,16 @@
+package services
+
+import (
+	"task-manager/backend/internal/models"
+
+	"github.com/gofrs/uuid"
+	"gorm.io/gorm"
+)
+
+// RecordSecurityEvent appends an entry to the security audit log
+func RecordSecurityEvent(db *gorm.DB, event models.SecurityEvent) error {
+	if event.ID == uuid.Nil {
+		event.ID = uuid.Must(uuid.NewV4())
+	}
+	return db.Create(&event).Error
+}
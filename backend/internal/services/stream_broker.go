@@ -0,0 +1,192 @@
+package services
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"task-manager/backend/internal/models"
+	"time"
+
+	"github.com/gofrs/uuid"
+	"gorm.io/gorm"
+)
+
+const (
+	StreamEventReset = "stream.reset"
+
+	streamReplayBufferSize   = 1000
+	streamSubscriberCapacity = 64
+)
+
+// StreamEvent is a task change pushed to connected clients
+type StreamEvent struct {
+	ID        string      `json:"id"`
+	Type      string      `json:"type"`
+	Task      models.Task `json:"task"`
+	ActorID   uuid.UUID   `json:"actor_id"`
+	CreatedAt time.Time   `json:"created_at"`
+
+	// Access decides who receives the event and is never sent to clients
+	Access TaskAccess `json:"-"`
+}
+
+// StreamSubscription receives events until it is closed. Replay holds the
+// events the client missed since its Last-Event-ID.
+type StreamSubscription struct {
+	Replay []StreamEvent
+	Events <-chan StreamEvent
+
+	events chan StreamEvent
+}
+
+// StreamBroker fans task events out to live subscribers. The in-memory
+// implementation only reaches clients connected to this process; a
+// distributed broker can replace it behind the same interface.
+type StreamBroker interface {
+	Publish(eventType string, task models.Task, actorID uuid.UUID, access TaskAccess) StreamEvent
+	Subscribe(lastEventID string) *StreamSubscription
+	Unsubscribe(sub *StreamSubscription)
+}
+
+type InMemoryStreamBroker struct {
+	mutex       sync.Mutex
+	epoch       int64
+	sequence    uint64
+	buffer      []StreamEvent
+	subscribers map[*StreamSubscription]struct{}
+}
+
+func NewInMemoryStreamBroker() *InMemoryStreamBroker {
+	return &InMemoryStreamBroker{
+		epoch:       time.Now().UnixNano(),
+		subscribers: make(map[*StreamSubscription]struct{}),
+	}
+}
+
+func (b *InMemoryStreamBroker) Publish(eventType string, task models.Task, actorID uuid.UUID, access TaskAccess) StreamEvent {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	b.sequence++
+	event := StreamEvent{
+		ID:        fmt.Sprintf("%d-%d", b.epoch, b.sequence),
+		Type:      eventType,
+		Task:      task,
+		ActorID:   actorID,
+		CreatedAt: time.Now().UTC(),
+		Access:    access,
+	}
+
+	b.buffer = append(b.buffer, event)
+	if len(b.buffer) > streamReplayBufferSize {
+		b.buffer = b.buffer[len(b.buffer)-streamReplayBufferSize:]
+	}
+
+	for sub := range b.subscribers {
+		select {
+		case sub.events <- event:
+		default:
+			// Drop subscribers that cannot keep up; they reconnect with
+			// Last-Event-ID and catch up from the replay buffer
+			delete(b.subscribers, sub)
+			close(sub.events)
+		}
+	}
+
+	return event
+}
+
+func (b *InMemoryStreamBroker) Subscribe(lastEventID string) *StreamSubscription {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	events := make(chan StreamEvent, streamSubscriberCapacity)
+	sub := &StreamSubscription{Events: events, events: events}
+
+	if lastEventID != "" {
+		sub.Replay = b.replaySince(lastEventID)
+	}
+
+	b.subscribers[sub] = struct{}{}
+	return sub
+}
+
+func (b *InMemoryStreamBroker) Unsubscribe(sub *StreamSubscription) {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	if _, ok := b.subscribers[sub]; ok {
+		delete(b.subscribers, sub)
+		close(sub.events)
+	}
+}
+
+// replaySince returns the buffered events after lastEventID. When the ID is
+// from another process or already evicted, a reset event tells the client to
+// reload its state instead.
+func (b *InMemoryStreamBroker) replaySince(lastEventID string) []StreamEvent {
+	epoch, sequence, ok := parseStreamEventID(lastEventID)
+	if ok && epoch == b.epoch && sequence == b.sequence {
+		return nil
+	}
+
+	oldest := b.sequence - uint64(len(b.buffer)) + 1
+	if !ok || epoch != b.epoch || sequence+1 < oldest || sequence > b.sequence {
+		return []StreamEvent{{
+			ID:        fmt.Sprintf("%d-%d", b.epoch, b.sequence),
+			Type:      StreamEventReset,
+			CreatedAt: time.Now().UTC(),
+		}}
+	}
+
+	start := int(sequence + 1 - oldest)
+	replay := make([]StreamEvent, len(b.buffer)-start)
+	copy(replay, b.buffer[start:])
+	return replay
+}
+
+func parseStreamEventID(id string) (int64, uint64, bool) {
+	parts := strings.SplitN(id, "-", 2)
+	if len(parts) != 2 {
+		return 0, 0, false
+	}
+
+	epoch, err := strconv.ParseInt(parts[0], 10, 64)
+	if err != nil {
+		return 0, 0, false
+	}
+	sequence, err := strconv.ParseUint(parts[1], 10, 64)
+	if err != nil {
+		return 0, 0, false
+	}
+
+	return epoch, sequence, true
+}
+
+// TaskVisibleTo applies the task read policy GetTasks lists with to a
+// stream event. The user's teams are only loaded when the task was shared
+// with a team.
+func TaskVisibleTo(db *gorm.DB, event StreamEvent, userID uuid.UUID, isAdmin bool) bool {
+	if event.Type == StreamEventReset {
+		return true
+	}
+
+	subject := PolicySubject{UserID: userID, IsAdmin: isAdmin}
+	if len(event.Access.TeamAccess) > 0 && !isAdmin && event.Task.UserID != userID {
+		loaded, err := taskPolicySubject(db, userID, isAdmin)
+		if err != nil {
+			return false
+		}
+		subject = loaded
+	}
+
+	resource := PolicyResource{
+		Type:       PolicyResourceTask,
+		ID:         event.Task.ID,
+		OwnerID:    event.Task.UserID,
+		TeamAccess: event.Access.TeamAccess,
+		UserAccess: event.Access.UserAccess,
+	}
+	return Policies().Evaluate(subject, PolicyActionRead, resource).Allowed
+}
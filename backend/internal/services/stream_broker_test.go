@@ -0,0 +1,175 @@
+package services
+
+import (
+	"encoding/json"
+	"fmt"
+	"task-manager/backend/internal/models"
+	"testing"
+
+	"github.com/gofrs/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestInMemoryStreamBroker_PublishReachesSubscribers(t *testing.T) {
+	broker := NewInMemoryStreamBroker()
+	sub := broker.Subscribe("")
+	defer broker.Unsubscribe(sub)
+
+	task := models.Task{ID: uuid.Must(uuid.NewV4()), Title: "Write docs"}
+	published := broker.Publish(models.WebhookEventTaskCreated, task, uuid.Must(uuid.NewV4()), TaskAccess{})
+
+	received := <-sub.Events
+	assert.Equal(t, published.ID, received.ID)
+	assert.Equal(t, models.WebhookEventTaskCreated, received.Type)
+	assert.Equal(t, task.ID, received.Task.ID)
+	assert.Empty(t, sub.Replay)
+}
+
+func TestInMemoryStreamBroker_ReplaysSinceLastEventID(t *testing.T) {
+	broker := NewInMemoryStreamBroker()
+	actorID := uuid.Must(uuid.NewV4())
+
+	first := broker.Publish(models.WebhookEventTaskCreated, models.Task{Title: "one"}, actorID, TaskAccess{})
+	broker.Publish(models.WebhookEventTaskUpdated, models.Task{Title: "two"}, actorID, TaskAccess{})
+	last := broker.Publish(models.WebhookEventTaskDeleted, models.Task{Title: "three"}, actorID, TaskAccess{})
+
+	sub := broker.Subscribe(first.ID)
+	defer broker.Unsubscribe(sub)
+
+	require.Len(t, sub.Replay, 2)
+	assert.Equal(t, "two", sub.Replay[0].Task.Title)
+	assert.Equal(t, last.ID, sub.Replay[1].ID)
+
+	upToDate := broker.Subscribe(last.ID)
+	defer broker.Unsubscribe(upToDate)
+	assert.Empty(t, upToDate.Replay)
+}
+
+func TestInMemoryStreamBroker_ResetsUnknownEventID(t *testing.T) {
+	broker := NewInMemoryStreamBroker()
+	actorID := uuid.Must(uuid.NewV4())
+
+	for i := 0; i < streamReplayBufferSize+5; i++ {
+		broker.Publish(models.WebhookEventTaskUpdated, models.Task{}, actorID, TaskAccess{})
+	}
+
+	for _, lastEventID := range []string{
+		"garbage",
+		"1-1",
+		fmt.Sprintf("%d-1", broker.epoch),
+	} {
+		sub := broker.Subscribe(lastEventID)
+		require.Len(t, sub.Replay, 1, lastEventID)
+		assert.Equal(t, StreamEventReset, sub.Replay[0].Type)
+		broker.Unsubscribe(sub)
+	}
+}
+
+func TestInMemoryStreamBroker_DropsSlowSubscribers(t *testing.T) {
+	broker := NewInMemoryStreamBroker()
+	sub := broker.Subscribe("")
+
+	for i := 0; i <= streamSubscriberCapacity; i++ {
+		broker.Publish(models.WebhookEventTaskUpdated, models.Task{}, uuid.Nil, TaskAccess{})
+	}
+
+	count := 0
+	for range sub.Events {
+		count++
+	}
+	assert.Equal(t, streamSubscriberCapacity, count)
+
+	// Unsubscribing a dropped subscriber is a no-op
+	broker.Unsubscribe(sub)
+}
+
+func TestTaskVisibleTo(t *testing.T) {
+	ownerID := uuid.Must(uuid.NewV4())
+	otherID := uuid.Must(uuid.NewV4())
+	event := StreamEvent{Type: models.WebhookEventTaskUpdated, Task: models.Task{UserID: ownerID}}
+
+	assert.True(t, TaskVisibleTo(nil, event, ownerID, false))
+	assert.False(t, TaskVisibleTo(nil, event, otherID, false))
+	assert.True(t, TaskVisibleTo(nil, event, otherID, true))
+	assert.True(t, TaskVisibleTo(nil, StreamEvent{Type: StreamEventReset}, otherID, false))
+}
+
+func TestStreamEventSubscriber_TeamSharedTask(t *testing.T) {
+	db := setupTestDB()
+	taskService := NewTaskService()
+	cacheService, _ := NewCacheService()
+
+	ownerID := uuid.Must(uuid.NewV4())
+	memberID := uuid.Must(uuid.NewV4())
+	outsiderID := uuid.Must(uuid.NewV4())
+
+	team := models.Team{ID: uuid.Must(uuid.NewV4()), Name: "platform", CreatedBy: ownerID}
+	require.NoError(t, db.Create(&team).Error)
+	require.NoError(t, db.Create(&models.TeamMember{ID: uuid.Must(uuid.NewV4()), TeamID: team.ID, UserID: memberID}).Error)
+
+	task := models.Task{ID: uuid.Must(uuid.NewV4()), Title: "Shared", Status: "pending", Priority: "medium", UserID: ownerID}
+	require.NoError(t, db.Create(&task).Error)
+	_, err := taskService.ShareWithTeam(db, task.ID, team.ID, models.TaskShareAccessRead, ownerID)
+	require.NoError(t, err)
+
+	title := "Shared and updated"
+	_, err = taskService.UpdateTask(db, task.ID, models.TaskUpdateRequest{Title: &title}, ownerID, cacheService)
+	require.NoError(t, err)
+
+	broker := NewInMemoryStreamBroker()
+	sub := broker.Subscribe("")
+	dispatcher := NewEventDispatcher()
+	dispatcher.Subscribe("stream", StreamEventSubscriber(broker), TaskEventTypes...)
+	_, err = dispatcher.ProcessOutbox(db, 10)
+	require.NoError(t, err)
+
+	event := <-sub.Events
+	assert.Equal(t, models.DomainEventTaskUpdated, event.Type)
+	assert.True(t, TaskVisibleTo(db, event, memberID, false))
+	assert.True(t, TaskVisibleTo(db, event, ownerID, false))
+	assert.False(t, TaskVisibleTo(db, event, outsiderID, false))
+
+	// The shares decide who receives the event but are not sent to clients
+	data, err := json.Marshal(event)
+	require.NoError(t, err)
+	assert.NotContains(t, string(data), team.ID.String())
+}
+
+func TestStreamEventSubscriber_UserSharedTask(t *testing.T) {
+	db := setupTestDB()
+	taskService := NewTaskService()
+	cacheService, _ := NewCacheService()
+
+	ownerID := uuid.Must(uuid.NewV4())
+	outsiderID := uuid.Must(uuid.NewV4())
+	reader := createTestUser(db, "reader", "password123")
+
+	task := models.Task{ID: uuid.Must(uuid.NewV4()), Title: "Shared", Status: "pending", Priority: "medium", UserID: ownerID}
+	require.NoError(t, db.Create(&task).Error)
+	_, err := taskService.ShareWithUser(db, task.ID, reader.ID, models.TaskShareAccessRead, ownerID)
+	require.NoError(t, err)
+
+	title := "Shared and updated"
+	_, err = taskService.UpdateTask(db, task.ID, models.TaskUpdateRequest{Title: &title}, ownerID, cacheService)
+	require.NoError(t, err)
+	require.NoError(t, taskService.DeleteTask(db, task.ID, ownerID, false, cacheService))
+
+	broker := NewInMemoryStreamBroker()
+	sub := broker.Subscribe("")
+	dispatcher := NewEventDispatcher()
+	dispatcher.Subscribe("stream", StreamEventSubscriber(broker), TaskEventTypes...)
+	_, err = dispatcher.ProcessOutbox(db, 10)
+	require.NoError(t, err)
+
+	updated := <-sub.Events
+	assert.Equal(t, models.DomainEventTaskUpdated, updated.Type)
+	assert.True(t, TaskVisibleTo(db, updated, reader.ID, false))
+	assert.False(t, TaskVisibleTo(db, updated, outsiderID, false))
+
+	// The delete still reaches the user although the share went with the task
+	deleted := <-sub.Events
+	assert.Equal(t, models.DomainEventTaskDeleted, deleted.Type)
+	assert.True(t, TaskVisibleTo(db, deleted, reader.ID, false))
+	assert.False(t, TaskVisibleTo(db, deleted, outsiderID, false))
+}
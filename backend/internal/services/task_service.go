@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"task-manager/backend/internal/models"
 	"task-manager/backend/internal/utils"
+	"time"
 
 	"github.com/gofrs/uuid"
 	"gorm.io/gorm"
@@ -17,20 +18,71 @@ type TaskService interface {
 	GetTaskByID(db *gorm.DB, taskID uuid.UUID, userID uuid.UUID, isAdmin bool, cacheService CacheService) (*models.Task, error)
 	GetTasksByUser(db *gorm.DB, userID uuid.UUID, pagination utils.PaginationParams, filters utils.FilterParams, cacheService CacheService) (utils.PaginationResponse, error)
 	GetTasks(db *gorm.DB, userID uuid.UUID, isAdmin bool, pagination utils.PaginationParams, filters utils.FilterParams, cacheService CacheService) (utils.PaginationResponse, error)
+	UpdateRemainingEstimate(db *gorm.DB, taskID uuid.UUID, remaining float64, userID uuid.UUID, cacheService CacheService) (*models.Task, error)
+	WatchTask(db *gorm.DB, taskID uuid.UUID, userID uuid.UUID, isAdmin bool, cacheService CacheService) error
+	UnwatchTask(db *gorm.DB, taskID uuid.UUID, userID uuid.UUID) error
+	GetTaskWatchers(db *gorm.DB, taskID uuid.UUID, userID uuid.UUID, isAdmin bool, cacheService CacheService) ([]models.TaskWatcher, error)
+	GetTeamShares(db *gorm.DB, taskID uuid.UUID, userID uuid.UUID) ([]models.TaskTeamShare, error)
+	ShareWithTeam(db *gorm.DB, taskID uuid.UUID, teamID uuid.UUID, access string, userID uuid.UUID) (*models.TaskTeamShare, error)
+	UnshareWithTeam(db *gorm.DB, taskID uuid.UUID, teamID uuid.UUID, userID uuid.UUID) error
+	GetUserShares(db *gorm.DB, taskID uuid.UUID, userID uuid.UUID) ([]models.TaskUserShare, error)
+	ShareWithUser(db *gorm.DB, taskID uuid.UUID, targetUserID uuid.UUID, access string, userID uuid.UUID) (*models.TaskUserShare, error)
+	UnshareWithUser(db *gorm.DB, taskID uuid.UUID, targetUserID uuid.UUID, userID uuid.UUID) error
+	GetPublicLinks(db *gorm.DB, taskID uuid.UUID, userID uuid.UUID) ([]models.TaskPublicLink, error)
+	CreatePublicLink(db *gorm.DB, taskID uuid.UUID, req models.CreateTaskPublicLinkRequest, userID uuid.UUID) (*models.CreatedTaskPublicLink, error)
+	RevokePublicLink(db *gorm.DB, taskID uuid.UUID, linkID uuid.UUID, userID uuid.UUID) error
+	GetPublicTask(db *gorm.DB, token string) (*models.PublicTask, error)
 }
 
-type TaskServiceImpl struct{}
+type TaskServiceImpl struct {
+	notificationService NotificationService
+	events              EventNotifier
+	policies            *PolicyEngine
+}
 
 func NewTaskService() *TaskServiceImpl {
-	return &TaskServiceImpl{}
+	return &TaskServiceImpl{
+		notificationService: NewNotificationService(),
+		policies:            Policies(),
+	}
+}
+
+// UseEventNotifier wakes the event dispatcher after a task change commits
+func (s *TaskServiceImpl) UseEventNotifier(events EventNotifier) {
+	s.events = events
 }
 
 func (s *TaskServiceImpl) CreateTask(db *gorm.DB, task models.Task, cacheService CacheService) (*models.Task, error) {
 	task.ID = uuid.Must(uuid.NewV4())
-	
-	result := db.Create(&task)
-	if result.Error != nil {
-		return nil, result.Error
+
+	// Remaining work starts out as the full original estimate
+	if task.RemainingEstimate == nil && task.OriginalEstimate != nil {
+		remaining := *task.OriginalEstimate
+		task.RemainingEstimate = &remaining
+	}
+
+	if task.MilestoneID != nil {
+		if err := ensureMilestoneExists(db, *task.MilestoneID); err != nil {
+			return nil, err
+		}
+	}
+
+	recordCompletion(&task, "")
+
+	err := db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&task).Error; err != nil {
+			return err
+		}
+
+		// The owner always watches their own task
+		if err := s.notificationService.AddWatcher(tx, task.ID, task.UserID); err != nil {
+			return err
+		}
+
+		return s.recordTaskEvent(tx, task, task.UserID, models.DomainEventTaskCreated)
+	})
+	if err != nil {
+		return nil, err
 	}
 
 	// Cache the new task
@@ -39,6 +91,8 @@ func (s *TaskServiceImpl) CreateTask(db *gorm.DB, task models.Task, cacheService
 	// Invalidate user tasks cache
 	cacheService.InvalidateUserCache(task.UserID)
 
+	s.notifyEvents()
+
 	return &task, nil
 }
 
@@ -46,9 +100,8 @@ func (s *TaskServiceImpl) UpdateTask(db *gorm.DB, taskID uuid.UUID, updateReq mo
 	// Try to get from cache first
 	if cachedTask, found := cacheService.GetTask(taskID); found {
 		if task, ok := cachedTask.(*models.Task); ok {
-			// Check if user owns the task (unless admin)
-			if task.UserID != userID {
-				return nil, errors.New("unauthorized: cannot update task owned by another user")
+			if err := s.authorize(db, *task, userID, false, PolicyActionWrite); err != nil {
+				return nil, err
 			}
 		}
 	}
@@ -64,11 +117,12 @@ func (s *TaskServiceImpl) UpdateTask(db *gorm.DB, taskID uuid.UUID, updateReq mo
 		return nil, result.Error
 	}
 
-	// Check if user owns the task (unless admin)
-	if task.UserID != userID {
-		return nil, errors.New("unauthorized: cannot update task owned by another user")
+	if err := s.authorize(db, task, userID, false, PolicyActionWrite); err != nil {
+		return nil, err
 	}
 
+	previousStatus := task.Status
+
 	// Update fields if provided
 	if updateReq.Title != nil {
 		task.Title = *updateReq.Title
@@ -82,18 +136,83 @@ func (s *TaskServiceImpl) UpdateTask(db *gorm.DB, taskID uuid.UUID, updateReq mo
 	if updateReq.Priority != nil {
 		task.Priority = *updateReq.Priority
 	}
+	if updateReq.OriginalEstimate != nil {
+		task.OriginalEstimate = updateReq.OriginalEstimate
+	}
+	if updateReq.RemainingEstimate != nil {
+		task.RemainingEstimate = updateReq.RemainingEstimate
+	}
+	if updateReq.StoryPoints != nil {
+		task.StoryPoints = updateReq.StoryPoints
+	}
+	if updateReq.MilestoneID != nil {
+		if err := ensureMilestoneExists(db, *updateReq.MilestoneID); err != nil {
+			return nil, err
+		}
+		task.MilestoneID = updateReq.MilestoneID
+	} else if updateReq.MilestoneSet {
+		task.MilestoneID = nil
+	}
+
+	recordCompletion(&task, previousStatus)
+
+	err := db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Save(&task).Error; err != nil {
+			return err
+		}
+		return s.recordTaskEvent(tx, task, userID, models.DomainEventTaskUpdated)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// Update cache
+	cacheService.SetTask(task.ID, task)
+	
+	// Invalidate user tasks cache
+	cacheService.InvalidateUserCache(task.UserID)
 
-	result = db.Save(&task)
+	s.notifyEvents()
+
+	return &task, nil
+}
+
+func (s *TaskServiceImpl) UpdateRemainingEstimate(db *gorm.DB, taskID uuid.UUID, remaining float64, userID uuid.UUID, cacheService CacheService) (*models.Task, error) {
+	var task models.Task
+
+	// Find the task
+	result := db.Where("id = ?", taskID).First(&task)
 	if result.Error != nil {
+		if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+			return nil, errors.New("task not found")
+		}
 		return nil, result.Error
 	}
 
+	if err := s.authorize(db, task, userID, false, PolicyActionWrite); err != nil {
+		return nil, err
+	}
+
+	err := db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&task).Update("remaining_estimate", remaining).Error; err != nil {
+			return err
+		}
+		task.RemainingEstimate = &remaining
+
+		return s.recordTaskEvent(tx, task, userID, models.DomainEventTaskUpdated)
+	})
+	if err != nil {
+		return nil, err
+	}
+
 	// Update cache
 	cacheService.SetTask(task.ID, task)
-	
+
 	// Invalidate user tasks cache
 	cacheService.InvalidateUserCache(task.UserID)
 
+	s.notifyEvents()
+
 	return &task, nil
 }
 
@@ -109,30 +228,368 @@ func (s *TaskServiceImpl) DeleteTask(db *gorm.DB, taskID uuid.UUID, userID uuid.
 		return result.Error
 	}
 
-	// Check if user owns the task (unless admin)
-	if !isAdmin && task.UserID != userID {
-		return errors.New("unauthorized: cannot delete task owned by another user")
+	if err := s.authorize(db, task, userID, isAdmin, PolicyActionDelete); err != nil {
+		return err
 	}
 
-	result = db.Delete(&task)
-	if result.Error != nil {
-		return result.Error
+	err := db.Transaction(func(tx *gorm.DB) error {
+		// Recorded first so the event still knows who the task was shared with
+		if err := s.recordTaskEvent(tx, task, userID, models.DomainEventTaskDeleted); err != nil {
+			return err
+		}
+
+		if err := tx.Where("task_id = ?", task.ID).Delete(&models.TaskWatcher{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("task_id = ?", task.ID).Delete(&models.TaskUserShare{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("task_id = ?", task.ID).Delete(&models.TaskTeamShare{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("task_id = ?", task.ID).Delete(&models.TaskPublicLink{}).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&task).Error
+	})
+	if err != nil {
+		return err
 	}
 
 	// Invalidate caches
 	cacheService.InvalidateTaskCache(taskID)
 	cacheService.InvalidateUserCache(task.UserID)
 
+	s.notifyEvents()
+
 	return nil
 }
 
+func (s *TaskServiceImpl) WatchTask(db *gorm.DB, taskID uuid.UUID, userID uuid.UUID, isAdmin bool, cacheService CacheService) error {
+	// Only users who can see the task may subscribe to it
+	if _, err := s.GetTaskByID(db, taskID, userID, isAdmin, cacheService); err != nil {
+		return err
+	}
+
+	return s.notificationService.AddWatcher(db, taskID, userID)
+}
+
+func (s *TaskServiceImpl) UnwatchTask(db *gorm.DB, taskID uuid.UUID, userID uuid.UUID) error {
+	return s.notificationService.RemoveWatcher(db, taskID, userID)
+}
+
+func (s *TaskServiceImpl) GetTaskWatchers(db *gorm.DB, taskID uuid.UUID, userID uuid.UUID, isAdmin bool, cacheService CacheService) ([]models.TaskWatcher, error) {
+	if _, err := s.GetTaskByID(db, taskID, userID, isAdmin, cacheService); err != nil {
+		return nil, err
+	}
+
+	return s.notificationService.GetWatchers(db, taskID)
+}
+
+// GetTeamShares lists the teams the task is shared with; only whoever may
+// share the task can see them
+func (s *TaskServiceImpl) GetTeamShares(db *gorm.DB, taskID uuid.UUID, userID uuid.UUID) ([]models.TaskTeamShare, error) {
+	if _, err := s.findTaskToShare(db, taskID, userID); err != nil {
+		return nil, err
+	}
+
+	shares := []models.TaskTeamShare{}
+	if err := db.Preload("Team").Where("task_id = ?", taskID).Order("created_at").Find(&shares).Error; err != nil {
+		return nil, err
+	}
+	return shares, nil
+}
+
+// ShareWithTeam gives every member of the team read or write access to the
+// task, replacing any access the team already had
+func (s *TaskServiceImpl) ShareWithTeam(db *gorm.DB, taskID uuid.UUID, teamID uuid.UUID, access string, userID uuid.UUID) (*models.TaskTeamShare, error) {
+	if access != models.TaskShareAccessRead && access != models.TaskShareAccessWrite {
+		return nil, errors.New("access must be read or write")
+	}
+
+	var share models.TaskTeamShare
+	err := db.Transaction(func(tx *gorm.DB) error {
+		if _, err := s.findTaskToShare(tx, taskID, userID); err != nil {
+			return err
+		}
+		team, err := findTeam(tx, teamID)
+		if err != nil {
+			return err
+		}
+
+		result := tx.Where("task_id = ? AND team_id = ?", taskID, teamID).Limit(1).Find(&share)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			share = models.TaskTeamShare{ID: uuid.Must(uuid.NewV4()), TaskID: taskID, TeamID: teamID}
+		}
+		share.Access = access
+		share.SharedBy = userID
+		if err := tx.Save(&share).Error; err != nil {
+			return err
+		}
+		share.Team = team
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &share, nil
+}
+
+func (s *TaskServiceImpl) UnshareWithTeam(db *gorm.DB, taskID uuid.UUID, teamID uuid.UUID, userID uuid.UUID) error {
+	if _, err := s.findTaskToShare(db, taskID, userID); err != nil {
+		return err
+	}
+
+	result := db.Where("task_id = ? AND team_id = ?", taskID, teamID).Delete(&models.TaskTeamShare{})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return errors.New("share not found")
+	}
+	return nil
+}
+
+// GetUserShares lists the users the task is shared with; only whoever may
+// share the task can see them
+func (s *TaskServiceImpl) GetUserShares(db *gorm.DB, taskID uuid.UUID, userID uuid.UUID) ([]models.TaskUserShare, error) {
+	if _, err := s.findTaskToShare(db, taskID, userID); err != nil {
+		return nil, err
+	}
+
+	shares := []models.TaskUserShare{}
+	if err := db.Preload("User").Where("task_id = ?", taskID).Order("created_at").Find(&shares).Error; err != nil {
+		return nil, err
+	}
+	return shares, nil
+}
+
+// ShareWithUser gives one user read or write access to the task, replacing
+// any access they already had
+func (s *TaskServiceImpl) ShareWithUser(db *gorm.DB, taskID uuid.UUID, targetUserID uuid.UUID, access string, userID uuid.UUID) (*models.TaskUserShare, error) {
+	if access != models.TaskShareAccessRead && access != models.TaskShareAccessWrite {
+		return nil, errors.New("access must be read or write")
+	}
+
+	var share models.TaskUserShare
+	err := db.Transaction(func(tx *gorm.DB) error {
+		task, err := s.findTaskToShare(tx, taskID, userID)
+		if err != nil {
+			return err
+		}
+		if task.UserID == targetUserID {
+			return errors.New("cannot share a task with its owner")
+		}
+		var user models.User
+		if err := findUser(tx, targetUserID, &user); err != nil {
+			return err
+		}
+
+		result := tx.Where("task_id = ? AND user_id = ?", taskID, targetUserID).Limit(1).Find(&share)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			share = models.TaskUserShare{ID: uuid.Must(uuid.NewV4()), TaskID: taskID, UserID: targetUserID}
+		}
+		share.Access = access
+		share.SharedBy = userID
+		if err := tx.Save(&share).Error; err != nil {
+			return err
+		}
+		share.User = user
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &share, nil
+}
+
+func (s *TaskServiceImpl) UnshareWithUser(db *gorm.DB, taskID uuid.UUID, targetUserID uuid.UUID, userID uuid.UUID) error {
+	if _, err := s.findTaskToShare(db, taskID, userID); err != nil {
+		return err
+	}
+
+	result := db.Where("task_id = ? AND user_id = ?", taskID, targetUserID).Delete(&models.TaskUserShare{})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return errors.New("share not found")
+	}
+	return nil
+}
+
+func (s *TaskServiceImpl) GetPublicLinks(db *gorm.DB, taskID uuid.UUID, userID uuid.UUID) ([]models.TaskPublicLink, error) {
+	if _, err := s.findTaskToShare(db, taskID, userID); err != nil {
+		return nil, err
+	}
+
+	links := []models.TaskPublicLink{}
+	if err := db.Where("task_id = ?", taskID).Order("created_at DESC").Find(&links).Error; err != nil {
+		return nil, err
+	}
+	return links, nil
+}
+
+// CreatePublicLink returns the only copy of the link's token
+func (s *TaskServiceImpl) CreatePublicLink(db *gorm.DB, taskID uuid.UUID, req models.CreateTaskPublicLinkRequest, userID uuid.UUID) (*models.CreatedTaskPublicLink, error) {
+	if _, err := s.findTaskToShare(db, taskID, userID); err != nil {
+		return nil, err
+	}
+
+	token, err := generateRefreshToken()
+	if err != nil {
+		return nil, err
+	}
+
+	link := models.TaskPublicLink{
+		ID:        uuid.Must(uuid.NewV4()),
+		TaskID:    taskID,
+		TokenHash: HashRefreshToken(token),
+		Hint:      token[:6],
+		CreatedBy: userID,
+	}
+	if req.ExpiresInDays > 0 {
+		expiresAt := time.Now().AddDate(0, 0, req.ExpiresInDays)
+		link.ExpiresAt = &expiresAt
+	}
+	if err := db.Create(&link).Error; err != nil {
+		return nil, err
+	}
+
+	return &models.CreatedTaskPublicLink{TaskPublicLink: link, Token: token}, nil
+}
+
+func (s *TaskServiceImpl) RevokePublicLink(db *gorm.DB, taskID uuid.UUID, linkID uuid.UUID, userID uuid.UUID) error {
+	if _, err := s.findTaskToShare(db, taskID, userID); err != nil {
+		return err
+	}
+
+	result := db.Where("id = ? AND task_id = ?", linkID, taskID).Delete(&models.TaskPublicLink{})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return errors.New("link not found")
+	}
+	return nil
+}
+
+// GetPublicTask serves a public link. Unknown, revoked and expired links
+// all look the same to the caller.
+func (s *TaskServiceImpl) GetPublicTask(db *gorm.DB, token string) (*models.PublicTask, error) {
+	now := time.Now()
+
+	var link models.TaskPublicLink
+	result := db.Where("token_hash = ? AND (expires_at IS NULL OR expires_at > ?)", HashRefreshToken(token), now).Limit(1).Find(&link)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	if result.RowsAffected == 0 {
+		return nil, errors.New("link not found")
+	}
+
+	var task models.Task
+	result = db.Where("id = ?", link.TaskID).Limit(1).Find(&task)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	if result.RowsAffected == 0 {
+		return nil, errors.New("link not found")
+	}
+
+	if err := db.Model(&link).Update("last_used_at", now).Error; err != nil {
+		return nil, err
+	}
+
+	publicTask := models.NewPublicTask(task)
+	return &publicTask, nil
+}
+
+func (s *TaskServiceImpl) findTaskToShare(db *gorm.DB, taskID uuid.UUID, userID uuid.UUID) (*models.Task, error) {
+	var task models.Task
+	result := db.Where("id = ?", taskID).First(&task)
+	if result.Error != nil {
+		if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+			return nil, errors.New("task not found")
+		}
+		return nil, result.Error
+	}
+
+	if err := s.authorize(db, task, userID, false, PolicyActionShare); err != nil {
+		return nil, err
+	}
+	return &task, nil
+}
+
+// recordTaskEvent writes the event to the outbox inside the task's
+// transaction; notifications, webhooks and the live stream pick it up from there
+func (s *TaskServiceImpl) recordTaskEvent(tx *gorm.DB, task models.Task, actorID uuid.UUID, eventType string) error {
+	resource, err := taskPolicyResource(tx, task)
+	if err != nil {
+		return err
+	}
+
+	payload := TaskEventPayload{
+		Task:    task,
+		ActorID: actorID,
+		Access:  &TaskAccess{TeamAccess: resource.TeamAccess, UserAccess: resource.UserAccess},
+	}
+	if eventType == models.DomainEventTaskDeleted {
+		var watcherIDs []uuid.UUID
+		if err := tx.Model(&models.TaskWatcher{}).Where("task_id = ?", task.ID).Order("created_at asc").Pluck("user_id", &watcherIDs).Error; err != nil {
+			return err
+		}
+		if payload.Watchers, err = watchersWithReadAccess(tx, resource, watcherIDs); err != nil {
+			return err
+		}
+	}
+
+	event, err := newDomainEvent(eventType, task.ID, actorID, payload)
+	if err != nil {
+		return err
+	}
+	return RecordDomainEvent(tx, event)
+}
+
+// taskDeniedMessages are the errors callers have always matched on
+var taskDeniedMessages = map[string]string{
+	PolicyActionRead:   "unauthorized: cannot view task owned by another user",
+	PolicyActionWrite:  "unauthorized: cannot update task owned by another user",
+	PolicyActionDelete: "unauthorized: cannot delete task owned by another user",
+	PolicyActionShare:  "unauthorized: cannot share task owned by another user",
+}
+
+// authorize checks the task access policies for the user
+func (s *TaskServiceImpl) authorize(db *gorm.DB, task models.Task, userID uuid.UUID, isAdmin bool, action string) error {
+	subject, err := taskPolicySubject(db, userID, isAdmin)
+	if err != nil {
+		return err
+	}
+	resource, err := taskPolicyResource(db, task)
+	if err != nil {
+		return err
+	}
+	return s.policies.Authorize(subject, action, resource, taskDeniedMessages[action])
+}
+
+func (s *TaskServiceImpl) notifyEvents() {
+	if s.events != nil {
+		s.events.Notify()
+	}
+}
+
 func (s *TaskServiceImpl) GetTaskByID(db *gorm.DB, taskID uuid.UUID, userID uuid.UUID, isAdmin bool, cacheService CacheService) (*models.Task, error) {
 	// Try to get from cache first
 	if cachedTask, found := cacheService.GetTask(taskID); found {
 		if task, ok := cachedTask.(models.Task); ok {
-			// Check if user owns the task (unless admin)
-			if !isAdmin && task.UserID != userID {
-				return nil, errors.New("unauthorized: cannot view task owned by another user")
+			if err := s.authorize(db, task, userID, isAdmin, PolicyActionRead); err != nil {
+				return nil, err
 			}
 			return &task, nil
 		}
@@ -148,9 +605,8 @@ func (s *TaskServiceImpl) GetTaskByID(db *gorm.DB, taskID uuid.UUID, userID uuid
 		return nil, result.Error
 	}
 
-	// Check if user owns the task (unless admin)
-	if !isAdmin && task.UserID != userID {
-		return nil, errors.New("unauthorized: cannot view task owned by another user")
+	if err := s.authorize(db, task, userID, isAdmin, PolicyActionRead); err != nil {
+		return nil, err
 	}
 
 	// Cache the task
@@ -161,8 +617,8 @@ func (s *TaskServiceImpl) GetTaskByID(db *gorm.DB, taskID uuid.UUID, userID uuid
 
 func (s *TaskServiceImpl) GetTasksByUser(db *gorm.DB, userID uuid.UUID, pagination utils.PaginationParams, filters utils.FilterParams, cacheService CacheService) (utils.PaginationResponse, error) {
 	// Create cache key based on parameters
-	cacheKey := fmt.Sprintf("user_tasks:%s:page:%d:size:%d:search:%s:sort:%s:%s", 
-		userID.String(), pagination.Page, pagination.PageSize, filters.Search, filters.SortBy, filters.SortOrder)
+	cacheKey := fmt.Sprintf("user_tasks:%s:page:%d:size:%d:search:%s:sort:%s:%s:filters:%v",
+		userID.String(), pagination.Page, pagination.PageSize, filters.Search, filters.SortBy, filters.SortOrder, filters.Filters)
 	
 	// Try to get from cache first
 	if cachedTasks, found := cacheService.Get(cacheKey); found {
@@ -181,13 +637,19 @@ func (s *TaskServiceImpl) GetTasksByUser(db *gorm.DB, userID uuid.UUID, paginati
 	query = utils.ApplySearch(query, filters.Search, []string{"title", "description"})
 	
 	// Apply filters
-	allowedFilters := []string{"status", "priority"}
+	allowedFilters := []string{"status", "priority", "milestone_id"}
 	query = utils.ApplyFilters(query, filters.Filters, allowedFilters)
 	
 	// Count total
 	if err := query.Count(&total).Error; err != nil {
 		return utils.PaginationResponse{}, err
 	}
+
+	// Roll up effort over the whole filtered set, not just the current page
+	aggregates, err := sumTaskEstimates(query)
+	if err != nil {
+		return utils.PaginationResponse{}, err
+	}
 	
 	// Apply sorting and pagination
 	allowedSortFields := []string{"title", "status", "priority", "created_at", "updated_at"}
@@ -201,6 +663,7 @@ func (s *TaskServiceImpl) GetTasksByUser(db *gorm.DB, userID uuid.UUID, paginati
 
 	// Create pagination response
 	response := utils.CreatePaginationResponse(tasks, total, pagination)
+	response.Aggregates = aggregates
 	
 	// Cache the response
 	cacheService.Set(cacheKey, response, 1024)
@@ -210,8 +673,8 @@ func (s *TaskServiceImpl) GetTasksByUser(db *gorm.DB, userID uuid.UUID, paginati
 
 func (s *TaskServiceImpl) GetTasks(db *gorm.DB, userID uuid.UUID, isAdmin bool, pagination utils.PaginationParams, filters utils.FilterParams, cacheService CacheService) (utils.PaginationResponse, error) {
 	// Create cache key based on parameters
-	cacheKey := fmt.Sprintf("tasks:user:%s:admin:%t:page:%d:size:%d:search:%s:sort:%s:%s", 
-		userID.String(), isAdmin, pagination.Page, pagination.PageSize, filters.Search, filters.SortBy, filters.SortOrder)
+	cacheKey := fmt.Sprintf("tasks:user:%s:admin:%t:page:%d:size:%d:search:%s:sort:%s:%s:filters:%v",
+		userID.String(), isAdmin, pagination.Page, pagination.PageSize, filters.Search, filters.SortBy, filters.SortOrder, filters.Filters)
 	
 	// Try to get from cache first
 	if cachedTasks, found := cacheService.Get(cacheKey); found {
@@ -223,27 +686,27 @@ func (s *TaskServiceImpl) GetTasks(db *gorm.DB, userID uuid.UUID, isAdmin bool,
 	var tasks []models.Task
 	var total int64
 
-	// Build query
-	var query *gorm.DB
-	if isAdmin {
-		// Admin can see all tasks
-		query = db.Model(&models.Task{})
-	} else {
-		// Regular user can only see their own tasks
-		query = db.Model(&models.Task{}).Where("user_id = ?", userID)
-	}
+	// Build query, limited to the tasks the user may view
+	subject := PolicySubject{UserID: userID, IsAdmin: isAdmin}
+	query := s.policies.Scope(db.Model(&models.Task{}), subject, PolicyResourceTask, PolicyActionRead)
 	
 	// Apply search
 	query = utils.ApplySearch(query, filters.Search, []string{"title", "description"})
 	
 	// Apply filters
-	allowedFilters := []string{"status", "priority", "user_id"}
+	allowedFilters := []string{"status", "priority", "user_id", "milestone_id"}
 	query = utils.ApplyFilters(query, filters.Filters, allowedFilters)
 	
 	// Count total
 	if err := query.Count(&total).Error; err != nil {
 		return utils.PaginationResponse{}, err
 	}
+
+	// Roll up effort over the whole filtered set, not just the current page
+	aggregates, err := sumTaskEstimates(query)
+	if err != nil {
+		return utils.PaginationResponse{}, err
+	}
 	
 	// Apply sorting and pagination
 	allowedSortFields := []string{"title", "status", "priority", "created_at", "updated_at", "user_id"}
@@ -257,9 +720,49 @@ func (s *TaskServiceImpl) GetTasks(db *gorm.DB, userID uuid.UUID, isAdmin bool,
 
 	// Create pagination response
 	response := utils.CreatePaginationResponse(tasks, total, pagination)
+	response.Aggregates = aggregates
 	
 	// Cache the response
 	cacheService.Set(cacheKey, response, 2048)
 
 	return response, nil
 }
+
+// sumTaskEstimates totals the estimates of every task matched by query
+func sumTaskEstimates(query *gorm.DB) (models.TaskAggregates, error) {
+	var aggregates models.TaskAggregates
+
+	err := query.Session(&gorm.Session{}).
+		Select("COALESCE(SUM(original_estimate), 0) AS total_original_estimate, " +
+			"COALESCE(SUM(remaining_estimate), 0) AS total_remaining_estimate, " +
+			"COALESCE(SUM(story_points), 0) AS total_story_points").
+		Scan(&aggregates).Error
+
+	return aggregates, err
+}
+
+// recordCompletion stamps CompletedAt when a task enters the completed status
+// and clears it again when the task is reopened
+func recordCompletion(task *models.Task, previousStatus string) {
+	if task.Status == previousStatus {
+		return
+	}
+
+	if task.Status == models.TaskStatusCompleted {
+		now := time.Now()
+		task.CompletedAt = &now
+	} else {
+		task.CompletedAt = nil
+	}
+}
+
+func ensureMilestoneExists(db *gorm.DB, milestoneID uuid.UUID) error {
+	var count int64
+	if err := db.Model(&models.Milestone{}).Where("id = ?", milestoneID).Count(&count).Error; err != nil {
+		return err
+	}
+	if count == 0 {
+		return errors.New("milestone not found")
+	}
+	return nil
+}
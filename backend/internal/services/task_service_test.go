@@ -1,10 +1,12 @@
 package services
 
 import (
+	"encoding/json"
 	"fmt"
 	"task-manager/backend/internal/models"
 	"task-manager/backend/internal/utils"
 	"testing"
+	"time"
 
 	"github.com/gofrs/uuid"
 	"github.com/stretchr/testify/assert"
@@ -125,6 +127,76 @@ func TestTaskService_DeleteTask(t *testing.T) {
 	assert.Error(t, result.Error)
 }
 
+func TestTaskService_DeleteTaskRemovesSharesAndLinks(t *testing.T) {
+	db := setupTestDB()
+	taskService := NewTaskService()
+	cacheService, _ := NewCacheService()
+
+	ownerID := uuid.Must(uuid.NewV4())
+	reader := createTestUser(db, "reader", "password123")
+	team := models.Team{ID: uuid.Must(uuid.NewV4()), Name: "platform", CreatedBy: ownerID}
+	db.Create(&team)
+
+	task := models.Task{ID: uuid.Must(uuid.NewV4()), Title: "Shared", Status: "pending", Priority: "medium", UserID: ownerID}
+	db.Create(&task)
+
+	_, err := taskService.ShareWithUser(db, task.ID, reader.ID, models.TaskShareAccessRead, ownerID)
+	assert.NoError(t, err)
+	_, err = taskService.ShareWithTeam(db, task.ID, team.ID, models.TaskShareAccessRead, ownerID)
+	assert.NoError(t, err)
+	link, err := taskService.CreatePublicLink(db, task.ID, models.CreateTaskPublicLinkRequest{}, ownerID)
+	assert.NoError(t, err)
+
+	assert.NoError(t, taskService.DeleteTask(db, task.ID, ownerID, false, cacheService))
+
+	var userShares, teamShares, links int64
+	db.Model(&models.TaskUserShare{}).Where("task_id = ?", task.ID).Count(&userShares)
+	db.Model(&models.TaskTeamShare{}).Where("task_id = ?", task.ID).Count(&teamShares)
+	db.Model(&models.TaskPublicLink{}).Where("task_id = ?", task.ID).Count(&links)
+	assert.Zero(t, userShares)
+	assert.Zero(t, teamShares)
+	assert.Zero(t, links)
+
+	_, err = taskService.GetPublicTask(db, link.Token)
+	assert.EqualError(t, err, "link not found")
+}
+
+func TestTaskService_UpdateTaskMilestone(t *testing.T) {
+	db := setupTestDB()
+	db.AutoMigrate(&models.Milestone{})
+	taskService := NewTaskService()
+	cacheService, _ := NewCacheService()
+
+	userID := uuid.Must(uuid.NewV4())
+	milestone := models.Milestone{ID: uuid.Must(uuid.NewV4()), Name: "Sprint 1", StartDate: time.Now(), EndDate: time.Now().AddDate(0, 0, 14)}
+	db.Create(&milestone)
+	task := models.Task{ID: uuid.Must(uuid.NewV4()), Title: "Plan", Status: "pending", Priority: "medium", UserID: userID}
+	db.Create(&task)
+
+	updated, err := taskService.UpdateTask(db, task.ID, models.TaskUpdateRequest{MilestoneID: &milestone.ID}, userID, cacheService)
+	assert.NoError(t, err)
+	assert.Equal(t, milestone.ID, *updated.MilestoneID)
+
+	// Leaving the field out keeps the milestone
+	var req models.TaskUpdateRequest
+	assert.NoError(t, json.Unmarshal([]byte(`{"title": "Plan the sprint"}`), &req))
+	updated, err = taskService.UpdateTask(db, task.ID, req, userID, cacheService)
+	assert.NoError(t, err)
+	assert.Equal(t, milestone.ID, *updated.MilestoneID)
+
+	// An explicit null takes the task out of the sprint
+	req = models.TaskUpdateRequest{}
+	assert.NoError(t, json.Unmarshal([]byte(`{"milestone_id": null}`), &req))
+	updated, err = taskService.UpdateTask(db, task.ID, req, userID, cacheService)
+	assert.NoError(t, err)
+	assert.Nil(t, updated.MilestoneID)
+
+	var stored models.Task
+	db.First(&stored, "id = ?", task.ID)
+	assert.Nil(t, stored.MilestoneID)
+	assert.Equal(t, "Plan the sprint", stored.Title)
+}
+
 func TestTaskService_GetTasksWithPagination(t *testing.T) {
 	db := setupTestDB()
 	taskService := NewTaskService()
@@ -168,3 +240,129 @@ func TestTaskService_GetTasksWithPagination(t *testing.T) {
 	assert.True(t, response.Pagination.HasNext)
 	assert.False(t, response.Pagination.HasPrev)
 }
+
+func TestTaskService_CreateTaskDefaultsRemainingEstimate(t *testing.T) {
+	db := setupTestDB()
+	taskService := NewTaskService()
+	cacheService, _ := NewCacheService()
+
+	estimate := 8.0
+	task := models.Task{
+		Title:            "Estimated Task",
+		Status:           "pending",
+		Priority:         "medium",
+		OriginalEstimate: &estimate,
+		UserID:           uuid.Must(uuid.NewV4()),
+	}
+
+	createdTask, err := taskService.CreateTask(db, task, cacheService)
+	assert.NoError(t, err)
+	assert.NotNil(t, createdTask.RemainingEstimate)
+	assert.Equal(t, 8.0, *createdTask.RemainingEstimate)
+
+	// An explicit remaining estimate is kept
+	remaining := 3.0
+	task.RemainingEstimate = &remaining
+	createdTask, err = taskService.CreateTask(db, task, cacheService)
+	assert.NoError(t, err)
+	assert.Equal(t, 3.0, *createdTask.RemainingEstimate)
+
+	// Without an original estimate there is nothing to default to
+	task.OriginalEstimate = nil
+	task.RemainingEstimate = nil
+	createdTask, err = taskService.CreateTask(db, task, cacheService)
+	assert.NoError(t, err)
+	assert.Nil(t, createdTask.RemainingEstimate)
+}
+
+func TestTaskService_UpdateRemainingEstimate(t *testing.T) {
+	db := setupTestDB()
+	taskService := NewTaskService()
+	cacheService, _ := NewCacheService()
+
+	userID := uuid.Must(uuid.NewV4())
+	taskID := uuid.Must(uuid.NewV4())
+	estimate := 5.0
+
+	task := models.Task{
+		ID:                taskID,
+		Title:             "Test Task",
+		Status:            "in_progress",
+		Priority:          "high",
+		OriginalEstimate:  &estimate,
+		RemainingEstimate: &estimate,
+		UserID:            userID,
+	}
+	db.Create(&task)
+
+	updatedTask, err := taskService.UpdateRemainingEstimate(db, taskID, 2, userID, cacheService)
+	assert.NoError(t, err)
+	assert.Equal(t, 2.0, *updatedTask.RemainingEstimate)
+	assert.Equal(t, 5.0, *updatedTask.OriginalEstimate)
+
+	var storedTask models.Task
+	db.First(&storedTask, "id = ?", taskID)
+	assert.Equal(t, 2.0, *storedTask.RemainingEstimate)
+
+	// Only someone who may update the task can log remaining work
+	_, err = taskService.UpdateRemainingEstimate(db, taskID, 1, uuid.Must(uuid.NewV4()), cacheService)
+	assert.Error(t, err)
+
+	_, err = taskService.UpdateRemainingEstimate(db, uuid.Must(uuid.NewV4()), 1, userID, cacheService)
+	assert.EqualError(t, err, "task not found")
+}
+
+func TestTaskService_GetTasksSumsEstimates(t *testing.T) {
+	db := setupTestDB()
+	taskService := NewTaskService()
+	cacheService, _ := NewCacheService()
+
+	userID := uuid.Must(uuid.NewV4())
+	estimates := []struct {
+		status    string
+		original  *float64
+		remaining *float64
+		points    *int
+	}{
+		{"pending", floatPtr(4), floatPtr(4), intPtr(3)},
+		{"in_progress", floatPtr(6), floatPtr(2.5), intPtr(5)},
+		{"pending", nil, nil, nil},
+	}
+	for i, estimate := range estimates {
+		task := models.Task{
+			ID:                uuid.Must(uuid.NewV4()),
+			Title:             fmt.Sprintf("Task %d", i+1),
+			Status:            estimate.status,
+			OriginalEstimate:  estimate.original,
+			RemainingEstimate: estimate.remaining,
+			StoryPoints:       estimate.points,
+			UserID:            userID,
+		}
+		db.Create(&task)
+	}
+
+	// Tasks of other users are not counted
+	db.Create(&models.Task{ID: uuid.Must(uuid.NewV4()), Title: "Other", Status: "pending", OriginalEstimate: floatPtr(100), UserID: uuid.Must(uuid.NewV4())})
+
+	// Totals cover the whole filtered set, not just the current page
+	pagination := utils.PaginationParams{Page: 1, PageSize: 1, Offset: 0, Limit: 1}
+	filters := utils.FilterParams{Filters: make(map[string]string)}
+
+	response, err := taskService.GetTasks(db, userID, false, pagination, filters, cacheService)
+	assert.NoError(t, err)
+	assert.Len(t, response.Data, 1)
+	assert.Equal(t, models.TaskAggregates{TotalOriginalEstimate: 10, TotalRemainingEstimate: 6.5, TotalStoryPoints: 8}, response.Aggregates)
+
+	filters.Filters["status"] = "pending"
+	response, err = taskService.GetTasksByUser(db, userID, pagination, filters, cacheService)
+	assert.NoError(t, err)
+	assert.Equal(t, models.TaskAggregates{TotalOriginalEstimate: 4, TotalRemainingEstimate: 4, TotalStoryPoints: 3}, response.Aggregates)
+}
+
+func floatPtr(value float64) *float64 {
+	return &value
+}
+
+func intPtr(value int) *int {
+	return &value
+}
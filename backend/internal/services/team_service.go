@@ -0,0 +1,392 @@
+package services
+
+import (
+	"errors"
+	"strings"
+	"task-manager/backend/internal/models"
+
+	"github.com/gofrs/uuid"
+	"gorm.io/gorm"
+)
+
+// heldRoleIDs selects the roles a user holds, directly or through a team.
+// It takes the user ID twice.
+const heldRoleIDs = "SELECT role_id FROM user_roles WHERE user_id = ? AND deleted_at IS NULL " +
+	"UNION SELECT team_roles.role_id FROM team_roles " +
+	"JOIN team_members ON team_members.team_id = team_roles.team_id WHERE team_members.user_id = ?"
+
+// TeamService manages teams, their members and the roles members inherit
+type TeamService interface {
+	GetTeams(db *gorm.DB, userID uuid.UUID, isAdmin bool) ([]models.Team, error)
+	CreateTeam(db *gorm.DB, creatorID uuid.UUID, req models.CreateTeamRequest) (models.Team, error)
+	UpdateTeam(db *gorm.DB, teamID uuid.UUID, req models.UpdateTeamRequest) (models.Team, error)
+	DeleteTeam(db *gorm.DB, teamID uuid.UUID) error
+	GetMembers(db *gorm.DB, teamID uuid.UUID, userID uuid.UUID, isAdmin bool) ([]models.TeamMember, error)
+	AddMember(db *gorm.DB, teamID, userID uuid.UUID) error
+	RemoveMember(db *gorm.DB, teamID, userID uuid.UUID) error
+	GetTeamRoles(db *gorm.DB, teamID uuid.UUID) ([]models.Role, error)
+	GrantRole(db *gorm.DB, teamID, roleID uuid.UUID) error
+	RevokeRole(db *gorm.DB, teamID, roleID uuid.UUID) error
+}
+
+type TeamServiceImpl struct {
+	revocations *RevocationStore
+	events      EventNotifier
+}
+
+func NewTeamService() *TeamServiceImpl {
+	return &TeamServiceImpl{}
+}
+
+// UseRevocationStore rejects the access tokens of members who lose a role
+// straight away
+func (s *TeamServiceImpl) UseRevocationStore(revocations *RevocationStore) {
+	s.revocations = revocations
+}
+
+// UseEventNotifier wakes the event dispatcher after a change commits
+func (s *TeamServiceImpl) UseEventNotifier(events EventNotifier) {
+	s.events = events
+}
+
+// GetTeams lists every team for admins and the user's own teams otherwise
+func (s *TeamServiceImpl) GetTeams(db *gorm.DB, userID uuid.UUID, isAdmin bool) ([]models.Team, error) {
+	query := db.Order("name")
+	if !isAdmin {
+		query = query.Where("id IN (SELECT team_id FROM team_members WHERE user_id = ?)", userID)
+	}
+
+	teams := []models.Team{}
+	if err := query.Find(&teams).Error; err != nil {
+		return nil, err
+	}
+	return teams, nil
+}
+
+func (s *TeamServiceImpl) CreateTeam(db *gorm.DB, creatorID uuid.UUID, req models.CreateTeamRequest) (models.Team, error) {
+	team := models.Team{
+		ID:          uuid.Must(uuid.NewV4()),
+		Name:        strings.TrimSpace(req.Name),
+		Description: req.Description,
+		CreatedBy:   creatorID,
+	}
+	if team.Name == "" {
+		return models.Team{}, errors.New("team name cannot be empty")
+	}
+
+	err := db.Transaction(func(tx *gorm.DB) error {
+		if err := ensureTeamNameFree(tx, team.Name, uuid.Nil); err != nil {
+			return err
+		}
+		return tx.Create(&team).Error
+	})
+	if err != nil {
+		return models.Team{}, err
+	}
+	return team, nil
+}
+
+func (s *TeamServiceImpl) UpdateTeam(db *gorm.DB, teamID uuid.UUID, req models.UpdateTeamRequest) (models.Team, error) {
+	err := db.Transaction(func(tx *gorm.DB) error {
+		team, err := findTeam(tx, teamID)
+		if err != nil {
+			return err
+		}
+
+		updates := map[string]interface{}{}
+		if req.Name != nil {
+			name := strings.TrimSpace(*req.Name)
+			if name == "" {
+				return errors.New("team name cannot be empty")
+			}
+			if err := ensureTeamNameFree(tx, name, teamID); err != nil {
+				return err
+			}
+			updates["name"] = name
+		}
+		if req.Description != nil {
+			updates["description"] = *req.Description
+		}
+
+		if len(updates) == 0 {
+			return nil
+		}
+		return tx.Model(&team).Updates(updates).Error
+	})
+	if err != nil {
+		return models.Team{}, err
+	}
+	return findTeam(db, teamID)
+}
+
+// DeleteTeam takes the team's roles and shared tasks away from its members.
+// It is refused when that would leave no admin.
+func (s *TeamServiceImpl) DeleteTeam(db *gorm.DB, teamID uuid.UUID) error {
+	var losers []uuid.UUID
+	err := guardLastAdmin(db, func(tx *gorm.DB) error {
+		if _, err := findTeam(tx, teamID); err != nil {
+			return err
+		}
+
+		var err error
+		if losers, err = s.membersLosingRoles(tx, teamID); err != nil {
+			return err
+		}
+
+		if err := tx.Where("team_id = ?", teamID).Delete(&models.TaskTeamShare{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("team_id = ?", teamID).Delete(&models.TeamRole{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("team_id = ?", teamID).Delete(&models.TeamMember{}).Error; err != nil {
+			return err
+		}
+		return tx.Where("id = ?", teamID).Delete(&models.Team{}).Error
+	})
+	if err != nil {
+		return err
+	}
+	s.notify()
+	return s.revokeUsers(db, losers)
+}
+
+// GetMembers is open to admins and the team's own members
+func (s *TeamServiceImpl) GetMembers(db *gorm.DB, teamID uuid.UUID, userID uuid.UUID, isAdmin bool) ([]models.TeamMember, error) {
+	if _, err := findTeam(db, teamID); err != nil {
+		return nil, err
+	}
+	if !isAdmin {
+		isMember, err := teamHasMember(db, teamID, userID)
+		if err != nil {
+			return nil, err
+		}
+		if !isMember {
+			return nil, errors.New("team not found")
+		}
+	}
+
+	members := []models.TeamMember{}
+	if err := db.Preload("User").Where("team_id = ?", teamID).Order("created_at").Find(&members).Error; err != nil {
+		return nil, err
+	}
+	return members, nil
+}
+
+// AddMember gives the user the team's roles and shared tasks
+func (s *TeamServiceImpl) AddMember(db *gorm.DB, teamID, userID uuid.UUID) error {
+	err := db.Transaction(func(tx *gorm.DB) error {
+		if _, err := findTeam(tx, teamID); err != nil {
+			return err
+		}
+		var user models.User
+		if err := findUser(tx, userID, &user); err != nil {
+			return err
+		}
+
+		isMember, err := teamHasMember(tx, teamID, userID)
+		if err != nil {
+			return err
+		}
+		if isMember {
+			return errors.New("user is already a member")
+		}
+
+		if err := tx.Create(&models.TeamMember{ID: uuid.Must(uuid.NewV4()), TeamID: teamID, UserID: userID}).Error; err != nil {
+			return err
+		}
+		return recordUserRolesChanged(tx, userID, uuid.Nil)
+	})
+	if err != nil {
+		return err
+	}
+	s.notify()
+	return nil
+}
+
+func (s *TeamServiceImpl) RemoveMember(db *gorm.DB, teamID, userID uuid.UUID) error {
+	var losesRoles bool
+	err := guardLastAdmin(db, func(tx *gorm.DB) error {
+		if _, err := findTeam(tx, teamID); err != nil {
+			return err
+		}
+
+		result := tx.Where("team_id = ? AND user_id = ?", teamID, userID).Delete(&models.TeamMember{})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return errors.New("user is not a member")
+		}
+
+		var roles int64
+		if err := tx.Model(&models.TeamRole{}).Where("team_id = ?", teamID).Count(&roles).Error; err != nil {
+			return err
+		}
+		losesRoles = roles > 0
+		return recordUserRolesChanged(tx, userID, uuid.Nil)
+	})
+	if err != nil {
+		return err
+	}
+	s.notify()
+
+	if losesRoles {
+		return s.revokeUsers(db, []uuid.UUID{userID})
+	}
+	return nil
+}
+
+func (s *TeamServiceImpl) GetTeamRoles(db *gorm.DB, teamID uuid.UUID) ([]models.Role, error) {
+	if _, err := findTeam(db, teamID); err != nil {
+		return nil, err
+	}
+
+	roles := []models.Role{}
+	err := db.Where("id IN (SELECT role_id FROM team_roles WHERE team_id = ?)", teamID).Order("name").Find(&roles).Error
+	return roles, err
+}
+
+// GrantRole gives the role to every current and future member
+func (s *TeamServiceImpl) GrantRole(db *gorm.DB, teamID, roleID uuid.UUID) error {
+	err := db.Transaction(func(tx *gorm.DB) error {
+		if _, err := findTeam(tx, teamID); err != nil {
+			return err
+		}
+		if _, err := findRole(tx, roleID); err != nil {
+			return err
+		}
+
+		var count int64
+		if err := tx.Model(&models.TeamRole{}).Where("team_id = ? AND role_id = ?", teamID, roleID).Count(&count).Error; err != nil {
+			return err
+		}
+		if count > 0 {
+			return errors.New("role already granted")
+		}
+
+		if err := tx.Create(&models.TeamRole{ID: uuid.Must(uuid.NewV4()), TeamID: teamID, RoleID: roleID}).Error; err != nil {
+			return err
+		}
+		return recordMembersRolesChanged(tx, teamID)
+	})
+	if err != nil {
+		return err
+	}
+	s.notify()
+	return nil
+}
+
+func (s *TeamServiceImpl) RevokeRole(db *gorm.DB, teamID, roleID uuid.UUID) error {
+	var members []uuid.UUID
+	err := guardLastAdmin(db, func(tx *gorm.DB) error {
+		if _, err := findTeam(tx, teamID); err != nil {
+			return err
+		}
+
+		result := tx.Where("team_id = ? AND role_id = ?", teamID, roleID).Delete(&models.TeamRole{})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return errors.New("role not granted")
+		}
+
+		if err := tx.Model(&models.TeamMember{}).Where("team_id = ?", teamID).Pluck("user_id", &members).Error; err != nil {
+			return err
+		}
+		return recordMembersRolesChanged(tx, teamID)
+	})
+	if err != nil {
+		return err
+	}
+	s.notify()
+	return s.revokeUsers(db, members)
+}
+
+// membersLosingRoles returns the members of a team that holds roles
+func (s *TeamServiceImpl) membersLosingRoles(tx *gorm.DB, teamID uuid.UUID) ([]uuid.UUID, error) {
+	var roles int64
+	if err := tx.Model(&models.TeamRole{}).Where("team_id = ?", teamID).Count(&roles).Error; err != nil {
+		return nil, err
+	}
+	if roles == 0 {
+		return nil, nil
+	}
+
+	if err := recordMembersRolesChanged(tx, teamID); err != nil {
+		return nil, err
+	}
+	var members []uuid.UUID
+	err := tx.Model(&models.TeamMember{}).Where("team_id = ?", teamID).Pluck("user_id", &members).Error
+	return members, err
+}
+
+func (s *TeamServiceImpl) notify() {
+	if s.events != nil {
+		s.events.Notify()
+	}
+}
+
+func (s *TeamServiceImpl) revokeUsers(db *gorm.DB, userIDs []uuid.UUID) error {
+	if s.revocations == nil {
+		return nil
+	}
+	for _, userID := range userIDs {
+		if err := s.revocations.RevokeUser(db, userID); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// recordMembersRolesChanged tells resolvers to forget the permissions of
+// every member of the team
+func recordMembersRolesChanged(tx *gorm.DB, teamID uuid.UUID) error {
+	var members []uuid.UUID
+	if err := tx.Model(&models.TeamMember{}).Where("team_id = ?", teamID).Pluck("user_id", &members).Error; err != nil {
+		return err
+	}
+	for _, userID := range members {
+		if err := recordUserRolesChanged(tx, userID, uuid.Nil); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// userTeamIDs returns the teams the user is a member of
+func userTeamIDs(db *gorm.DB, userID uuid.UUID) ([]uuid.UUID, error) {
+	var teamIDs []uuid.UUID
+	err := db.Model(&models.TeamMember{}).Where("user_id = ?", userID).Pluck("team_id", &teamIDs).Error
+	return teamIDs, err
+}
+
+func teamHasMember(db *gorm.DB, teamID, userID uuid.UUID) (bool, error) {
+	var count int64
+	err := db.Model(&models.TeamMember{}).Where("team_id = ? AND user_id = ?", teamID, userID).Count(&count).Error
+	return count > 0, err
+}
+
+func findTeam(db *gorm.DB, teamID uuid.UUID) (models.Team, error) {
+	var team models.Team
+	result := db.Where("id = ?", teamID).Limit(1).Find(&team)
+	if result.Error != nil {
+		return team, result.Error
+	}
+	if result.RowsAffected == 0 {
+		return team, errors.New("team not found")
+	}
+	return team, nil
+}
+
+func ensureTeamNameFree(db *gorm.DB, name string, teamID uuid.UUID) error {
+	var count int64
+	if err := db.Model(&models.Team{}).Where("LOWER(name) = LOWER(?) AND id <> ?", name, teamID).Count(&count).Error; err != nil {
+		return err
+	}
+	if count > 0 {
+		return errors.New("team already exists")
+	}
+	return nil
+}
@@ -0,0 +1,75 @@
+package services
+
+import (
+	"encoding/base32"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTOTPCode_RFC6238Vectors(t *testing.T) {
+	// SHA1 test vectors from RFC 6238, appendix B
+	secret := []byte("12345678901234567890")
+	vectors := map[int64]string{
+		59:          "94287082",
+		1111111109:  "07081804",
+		1111111111:  "14050471",
+		1234567890:  "89005924",
+		2000000000:  "69279037",
+		20000000000: "65353130",
+	}
+
+	for unix, expected := range vectors {
+		assert.Equal(t, expected, TOTPCode(secret, TOTPStep(time.Unix(unix, 0)), 8), unix)
+		assert.Equal(t, expected[2:], TOTPCode(secret, TOTPStep(time.Unix(unix, 0)), 6), unix)
+	}
+}
+
+func TestValidateTOTP(t *testing.T) {
+	secret := base32.StdEncoding.WithPadding(base32.NoPadding).EncodeToString([]byte("12345678901234567890"))
+	now := time.Unix(1111111111, 0)
+	step := TOTPStep(now)
+
+	// The current code and one step either side are accepted
+	for _, offset := range []int64{-1, 0, 1} {
+		code := TOTPCode([]byte("12345678901234567890"), step+offset, totpDigits)
+		matched, ok := ValidateTOTP(secret, code, now, 0)
+		assert.True(t, ok, offset)
+		assert.Equal(t, step+offset, matched)
+	}
+
+	// Codes further out are not
+	_, ok := ValidateTOTP(secret, TOTPCode([]byte("12345678901234567890"), step+2, totpDigits), now, 0)
+	assert.False(t, ok)
+
+	// A code cannot be used twice
+	code := TOTPCode([]byte("12345678901234567890"), step, totpDigits)
+	_, ok = ValidateTOTP(secret, code, now, step)
+	assert.False(t, ok)
+
+	_, ok = ValidateTOTP(secret, "12345", now, 0)
+	assert.False(t, ok)
+}
+
+func TestTOTPProvisioningURI(t *testing.T) {
+	uri := TOTPProvisioningURI("Taskify", "ada lovelace", "JBSWY3DPEHPK3PXP")
+	assert.True(t, strings.HasPrefix(uri, "otpauth://totp/Taskify:ada%20lovelace?"))
+
+	parsed, err := url.Parse(uri)
+	assert.NoError(t, err)
+	assert.Equal(t, "JBSWY3DPEHPK3PXP", parsed.Query().Get("secret"))
+	assert.Equal(t, "Taskify", parsed.Query().Get("issuer"))
+	assert.Equal(t, "6", parsed.Query().Get("digits"))
+}
+
+func TestRecoveryCodes(t *testing.T) {
+	code, err := generateRecoveryCode()
+	assert.NoError(t, err)
+	assert.Regexp(t, `^[a-z2-7]{4}-[a-z2-7]{4}-[a-z2-7]{4}-[a-z2-7]{4}$`, code)
+
+	// Typed back in any case, with or without dashes, it hashes the same
+	assert.Equal(t, normalizeRecoveryCode(code), normalizeRecoveryCode(strings.ToUpper(strings.ReplaceAll(code, "-", " "))))
+}
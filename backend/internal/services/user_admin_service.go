@@ -0,0 +1,501 @@
+package services
+
+import (
+	"errors"
+	"log"
+	"strings"
+	"task-manager/backend/internal/models"
+	"time"
+
+	"github.com/gofrs/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// UserAdminService is how admins manage other people's accounts and roles.
+// Every change is recorded as a security event of the affected user.
+type UserAdminService interface {
+	CreateUser(db *gorm.DB, adminID uuid.UUID, req models.AdminCreateUserRequest) (models.User, error)
+	UpdateUser(db *gorm.DB, adminID, userID uuid.UUID, req models.AdminUpdateUserRequest) (models.User, error)
+	DisableUser(db *gorm.DB, adminID, userID uuid.UUID) error
+	EnableUser(db *gorm.DB, adminID, userID uuid.UUID) error
+	ForcePasswordReset(db *gorm.DB, adminID, userID uuid.UUID) error
+	GetUserRoles(db *gorm.DB, userID uuid.UUID) ([]models.Role, error)
+	GrantRole(db *gorm.DB, adminID, userID, roleID uuid.UUID) error
+	RevokeRole(db *gorm.DB, adminID, userID, roleID uuid.UUID) error
+}
+
+type UserAdminServiceImpl struct {
+	accounts    AccountService
+	policy      *PasswordPolicy
+	revocations *RevocationStore
+	events      EventNotifier
+}
+
+func NewUserAdminService() *UserAdminServiceImpl {
+	return &UserAdminServiceImpl{}
+}
+
+// UseEmailVerification emails new users a verification link, or a link to
+// choose a password, and sends forced password resets
+func (s *UserAdminServiceImpl) UseEmailVerification(accounts AccountService) {
+	s.accounts = accounts
+}
+
+// UsePasswordPolicy rejects passwords that break the policy
+func (s *UserAdminServiceImpl) UsePasswordPolicy(policy *PasswordPolicy) {
+	s.policy = policy
+}
+
+// UseRevocationStore rejects the access tokens of users who are disabled or
+// lose a role straight away
+func (s *UserAdminServiceImpl) UseRevocationStore(revocations *RevocationStore) {
+	s.revocations = revocations
+}
+
+// UseEventNotifier wakes the event dispatcher after a role change commits
+func (s *UserAdminServiceImpl) UseEventNotifier(events EventNotifier) {
+	s.events = events
+}
+
+func (s *UserAdminServiceImpl) CreateUser(db *gorm.DB, adminID uuid.UUID, req models.AdminCreateUserRequest) (models.User, error) {
+	now := time.Now()
+	user := models.User{
+		ID:          uuid.Must(uuid.NewV4()),
+		Username:    strings.TrimSpace(req.Username),
+		Email:       strings.TrimSpace(req.Email),
+		DisplayName: strings.TrimSpace(req.DisplayName),
+	}
+	if user.Username == "" {
+		return models.User{}, errors.New("username cannot be empty")
+	}
+	if req.EmailVerified {
+		user.EmailVerifiedAt = &now
+	}
+
+	roleIDs := req.RoleIDs
+	if len(roleIDs) == 0 {
+		roleIDs = []uuid.UUID{uuid.FromStringOrNil("550e8400-e29b-41d4-a716-446655440001")} // user role ID
+	}
+
+	err := db.Transaction(func(tx *gorm.DB) error {
+		if taken, err := profileValueTaken(tx, "username", user.Username, uuid.Nil); err != nil {
+			return err
+		} else if taken {
+			return errors.New("username already exists")
+		}
+		if taken, err := profileValueTaken(tx, "email", user.Email, uuid.Nil); err != nil {
+			return err
+		} else if taken {
+			return errors.New("email already exists")
+		}
+
+		if req.Password != "" {
+			if s.policy != nil {
+				if err := s.policy.Validate(tx, req.Password, user); err != nil {
+					return err
+				}
+			}
+			hashedPassword, err := HashPassword(req.Password)
+			if err != nil {
+				return err
+			}
+			user.Password = hashedPassword
+		} else {
+			// Nothing matches until the user sets a password through the
+			// reset link
+			user.PasswordResetRequired = true
+		}
+
+		if err := tx.Create(&user).Error; err != nil {
+			return err
+		}
+		for _, roleID := range roleIDs {
+			if _, err := findRole(tx, roleID); err != nil {
+				return err
+			}
+			if err := grantRole(tx, user.ID, roleID); err != nil {
+				return err
+			}
+		}
+		if s.policy != nil && user.Password != "" {
+			if err := s.policy.Remember(tx, user.ID, user.Password); err != nil {
+				return err
+			}
+		}
+
+		return recordAdminAction(tx, user.ID, models.SecurityEventAccountCreated, adminID, "")
+	})
+	if err != nil {
+		return models.User{}, err
+	}
+
+	// The account exists either way; the admin can send another link
+	if s.accounts != nil {
+		if user.PasswordResetRequired {
+			err = s.accounts.RequestPasswordReset(db, user.Email)
+		} else if user.EmailVerifiedAt == nil {
+			err = s.accounts.SendVerificationEmail(db, user.ID)
+		}
+		if err != nil {
+			log.Printf("Failed to send account email to user %s: %v", user.ID, err)
+		}
+	}
+
+	return user, nil
+}
+
+func (s *UserAdminServiceImpl) UpdateUser(db *gorm.DB, adminID, userID uuid.UUID, req models.AdminUpdateUserRequest) (models.User, error) {
+	var user models.User
+	err := db.Transaction(func(tx *gorm.DB) error {
+		if err := findUser(tx, userID, &user); err != nil {
+			return err
+		}
+
+		updates := map[string]interface{}{}
+		var changed []string
+		if req.Username != nil {
+			username := strings.TrimSpace(*req.Username)
+			if username == "" {
+				return errors.New("username cannot be empty")
+			}
+			if username != user.Username {
+				taken, err := profileValueTaken(tx, "username", username, userID)
+				if err != nil {
+					return err
+				}
+				if taken {
+					return errors.New("username already exists")
+				}
+				updates["username"] = username
+				changed = append(changed, "username")
+			}
+		}
+		if req.Email != nil {
+			email := strings.TrimSpace(*req.Email)
+			if email != user.Email {
+				taken, err := profileValueTaken(tx, "email", email, userID)
+				if err != nil {
+					return err
+				}
+				if taken {
+					return errors.New("email already exists")
+				}
+				updates["email"] = email
+				changed = append(changed, "email")
+				if !strings.EqualFold(email, user.Email) {
+					updates["email_verified_at"] = nil
+				}
+			}
+		}
+		if req.DisplayName != nil {
+			updates["display_name"] = strings.TrimSpace(*req.DisplayName)
+			changed = append(changed, "display_name")
+		}
+		if req.EmailVerified != nil {
+			if *req.EmailVerified {
+				updates["email_verified_at"] = time.Now()
+			} else {
+				updates["email_verified_at"] = nil
+			}
+			changed = append(changed, "email_verified")
+		}
+
+		if len(updates) == 0 {
+			return nil
+		}
+		if err := tx.Model(&user).Updates(updates).Error; err != nil {
+			return err
+		}
+		return recordAdminAction(tx, userID, models.SecurityEventAccountUpdated, adminID, "changed "+strings.Join(changed, ", "))
+	})
+	if err != nil {
+		return models.User{}, err
+	}
+
+	if err := db.First(&user, "id = ?", userID).Error; err != nil {
+		return models.User{}, err
+	}
+	return user, nil
+}
+
+// DisableUser signs the user out everywhere and refuses logins, refreshes
+// and API tokens until the account is enabled again
+func (s *UserAdminServiceImpl) DisableUser(db *gorm.DB, adminID, userID uuid.UUID) error {
+	if adminID == userID {
+		return errors.New("cannot disable your own account")
+	}
+
+	err := db.Transaction(func(tx *gorm.DB) error {
+		var user models.User
+		if err := findUser(tx, userID, &user); err != nil {
+			return err
+		}
+		if user.DisabledAt != nil {
+			return nil
+		}
+		if err := ensureOtherAdmin(tx, userID); err != nil {
+			return err
+		}
+
+		if err := tx.Model(&user).Update("disabled_at", time.Now()).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("user_id = ?", userID).Delete(&models.Token{}).Error; err != nil {
+			return err
+		}
+		return recordAdminAction(tx, userID, models.SecurityEventAccountDisabled, adminID, "")
+	})
+	if err != nil {
+		return err
+	}
+
+	if s.revocations != nil {
+		return s.revocations.RevokeUser(db, userID)
+	}
+	return nil
+}
+
+func (s *UserAdminServiceImpl) EnableUser(db *gorm.DB, adminID, userID uuid.UUID) error {
+	return db.Transaction(func(tx *gorm.DB) error {
+		var user models.User
+		if err := findUser(tx, userID, &user); err != nil {
+			return err
+		}
+		if user.DisabledAt == nil {
+			return nil
+		}
+
+		if err := tx.Model(&user).Update("disabled_at", nil).Error; err != nil {
+			return err
+		}
+		return recordAdminAction(tx, userID, models.SecurityEventAccountEnabled, adminID, "")
+	})
+}
+
+// ForcePasswordReset signs the user out everywhere and refuses the current
+// password until the user picks a new one through the emailed link
+func (s *UserAdminServiceImpl) ForcePasswordReset(db *gorm.DB, adminID, userID uuid.UUID) error {
+	var user models.User
+	err := db.Transaction(func(tx *gorm.DB) error {
+		if err := findUser(tx, userID, &user); err != nil {
+			return err
+		}
+
+		if err := tx.Model(&user).Update("password_reset_required", true).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("user_id = ?", userID).Delete(&models.Token{}).Error; err != nil {
+			return err
+		}
+		return recordAdminAction(tx, userID, models.SecurityEventPasswordResetForced, adminID, "")
+	})
+	if err != nil {
+		return err
+	}
+
+	if s.revocations != nil {
+		if err := s.revocations.RevokeUser(db, userID); err != nil {
+			return err
+		}
+	}
+	if s.accounts != nil {
+		return s.accounts.RequestPasswordReset(db, user.Email)
+	}
+	return nil
+}
+
+func (s *UserAdminServiceImpl) GetUserRoles(db *gorm.DB, userID uuid.UUID) ([]models.Role, error) {
+	var user models.User
+	if err := findUser(db, userID, &user); err != nil {
+		return nil, err
+	}
+
+	var roles []models.Role
+	err := db.Where("id IN (?)", db.Model(&models.UserRole{}).Select("role_id").Where("user_id = ?", userID)).
+		Order("name").Find(&roles).Error
+	return roles, err
+}
+
+func (s *UserAdminServiceImpl) GrantRole(db *gorm.DB, adminID, userID, roleID uuid.UUID) error {
+	err := db.Transaction(func(tx *gorm.DB) error {
+		var user models.User
+		if err := findUser(tx, userID, &user); err != nil {
+			return err
+		}
+		role, err := findRole(tx, roleID)
+		if err != nil {
+			return err
+		}
+
+		var count int64
+		if err := tx.Model(&models.UserRole{}).Where("user_id = ? AND role_id = ?", userID, roleID).Count(&count).Error; err != nil {
+			return err
+		}
+		if count > 0 {
+			return errors.New("role already granted")
+		}
+
+		if err := grantRole(tx, userID, roleID); err != nil {
+			return err
+		}
+		if err := recordUserRolesChanged(tx, userID, adminID); err != nil {
+			return err
+		}
+		return recordAdminAction(tx, userID, models.SecurityEventRoleGranted, adminID, "role "+role.Name)
+	})
+	if err != nil {
+		return err
+	}
+
+	s.notify()
+	return nil
+}
+
+// RevokeRole refuses to take the admin role from the last enabled admin
+func (s *UserAdminServiceImpl) RevokeRole(db *gorm.DB, adminID, userID, roleID uuid.UUID) error {
+	err := db.Transaction(func(tx *gorm.DB) error {
+		var user models.User
+		if err := findUser(tx, userID, &user); err != nil {
+			return err
+		}
+		role, err := findRole(tx, roleID)
+		if err != nil {
+			return err
+		}
+
+		// Deleted for good, as permissions are looked up without regard
+		// to soft deletes
+		err = guardLastAdmin(tx, func(tx *gorm.DB) error {
+			result := tx.Unscoped().Where("user_id = ? AND role_id = ?", userID, roleID).Delete(&models.UserRole{})
+			if result.Error != nil {
+				return result.Error
+			}
+			if result.RowsAffected == 0 {
+				return errors.New("role not granted")
+			}
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+		if err := recordUserRolesChanged(tx, userID, adminID); err != nil {
+			return err
+		}
+		return recordAdminAction(tx, userID, models.SecurityEventRoleRevoked, adminID, "role "+role.Name)
+	})
+	if err != nil {
+		return err
+	}
+	s.notify()
+
+	// Tokens issued before still carry the role
+	if s.revocations != nil {
+		return s.revocations.RevokeUser(db, userID)
+	}
+	return nil
+}
+
+func (s *UserAdminServiceImpl) notify() {
+	if s.events != nil {
+		s.events.Notify()
+	}
+}
+
+func findUser(db *gorm.DB, userID uuid.UUID, user *models.User) error {
+	result := db.Where("id = ?", userID).Limit(1).Find(user)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return errors.New("user not found")
+	}
+	return nil
+}
+
+func findRole(db *gorm.DB, roleID uuid.UUID) (models.Role, error) {
+	var role models.Role
+	result := db.Where("id = ?", roleID).Limit(1).Find(&role)
+	if result.Error != nil {
+		return role, result.Error
+	}
+	if result.RowsAffected == 0 {
+		return role, errors.New("role not found")
+	}
+	return role, nil
+}
+
+func grantRole(db *gorm.DB, userID, roleID uuid.UUID) error {
+	return db.Create(&models.UserRole{ID: uuid.Must(uuid.NewV4()), UserID: userID, RoleID: roleID}).Error
+}
+
+// ensureOtherAdmin fails if the user is the only enabled admin left
+func ensureOtherAdmin(db *gorm.DB, userID uuid.UUID) error {
+	admins, err := enabledAdminIDs(db)
+	if err != nil {
+		return err
+	}
+
+	isAdmin := false
+	for _, adminID := range admins {
+		if adminID != userID {
+			return nil
+		}
+		isAdmin = true
+	}
+	if isAdmin {
+		return errors.New("cannot remove the last admin")
+	}
+	return nil
+}
+
+// guardLastAdmin applies a change that can take the admin role away from
+// users and undoes it if no enabled admin is left afterwards
+func guardLastAdmin(db *gorm.DB, change func(tx *gorm.DB) error) error {
+	before, err := enabledAdminIDs(db)
+	if err != nil {
+		return err
+	}
+
+	return db.Transaction(func(tx *gorm.DB) error {
+		if err := change(tx); err != nil {
+			return err
+		}
+		if len(before) == 0 {
+			return nil
+		}
+
+		after, err := enabledAdminIDs(tx)
+		if err != nil {
+			return err
+		}
+		if len(after) == 0 {
+			return errors.New("cannot remove the last admin")
+		}
+		return nil
+	})
+}
+
+// enabledAdminIDs lists the enabled users holding the admin role, directly
+// or through a team
+func enabledAdminIDs(db *gorm.DB) ([]uuid.UUID, error) {
+	userID := clause.Column{Table: "users", Name: "id"}
+
+	var ids []uuid.UUID
+	err := db.Model(&models.User{}).
+		Where("users.disabled_at IS NULL AND users.deleted_at IS NULL").
+		Where("EXISTS (SELECT 1 FROM roles WHERE roles.name = ? AND roles.deleted_at IS NULL AND roles.id IN ("+heldRoleIDs+"))", "admin", userID, userID).
+		Pluck("users.id", &ids).Error
+	return ids, err
+}
+
+func recordAdminAction(db *gorm.DB, userID uuid.UUID, eventType string, adminID uuid.UUID, details string) error {
+	if details != "" {
+		details += "; "
+	}
+	return RecordSecurityEvent(db, models.SecurityEvent{
+		UserID:  &userID,
+		Type:    eventType,
+		Details: details + "by admin " + adminID.String(),
+	})
+}
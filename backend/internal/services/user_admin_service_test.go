@@ -0,0 +1,147 @@
+package services
+
+import (
+	"task-manager/backend/internal/models"
+	"testing"
+	"time"
+
+	"github.com/gofrs/uuid"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+)
+
+func createTestRole(db *gorm.DB, name string) models.Role {
+	role := models.Role{ID: uuid.Must(uuid.NewV4()), Name: name}
+	db.Create(&role)
+	return role
+}
+
+func grantTestRole(db *gorm.DB, userID, roleID uuid.UUID) {
+	db.Create(&models.UserRole{ID: uuid.Must(uuid.NewV4()), UserID: userID, RoleID: roleID})
+}
+
+// createTestTeamAdmin puts the user in a new team holding the admin role
+func createTestTeamAdmin(db *gorm.DB, userID, adminRoleID uuid.UUID) models.Team {
+	team := models.Team{ID: uuid.Must(uuid.NewV4()), Name: "admins-" + userID.String()}
+	db.Create(&team)
+	db.Create(&models.TeamRole{ID: uuid.Must(uuid.NewV4()), TeamID: team.ID, RoleID: adminRoleID})
+	db.Create(&models.TeamMember{ID: uuid.Must(uuid.NewV4()), TeamID: team.ID, UserID: userID})
+	return team
+}
+
+func TestEnabledAdminIDs_CountsTeamAdmins(t *testing.T) {
+	db := setupTestDB()
+	admin := createTestRole(db, "admin")
+
+	direct := createTestUser(db, "ada", "password123")
+	inherited := createTestUser(db, "grace", "password123")
+	createTestUser(db, "linus", "password123")
+	grantTestRole(db, direct.ID, admin.ID)
+	createTestTeamAdmin(db, inherited.ID, admin.ID)
+
+	admins, err := enabledAdminIDs(db)
+	assert.NoError(t, err)
+	assert.ElementsMatch(t, []uuid.UUID{direct.ID, inherited.ID}, admins)
+
+	// Disabled users do not count
+	db.Model(&models.User{}).Where("id = ?", inherited.ID).Update("disabled_at", gorm.Expr("CURRENT_TIMESTAMP"))
+	admins, err = enabledAdminIDs(db)
+	assert.NoError(t, err)
+	assert.Equal(t, []uuid.UUID{direct.ID}, admins)
+}
+
+func TestUserAdminService_RevokeRoleKeepsLastAdmin(t *testing.T) {
+	db := setupTestDB()
+	userAdminService := NewUserAdminService()
+	admin := createTestRole(db, "admin")
+
+	ada := createTestUser(db, "ada", "password123")
+	grace := createTestUser(db, "grace", "password123")
+	grantTestRole(db, ada.ID, admin.ID)
+
+	err := userAdminService.RevokeRole(db, ada.ID, ada.ID, admin.ID)
+	assert.EqualError(t, err, "cannot remove the last admin")
+
+	// Someone who is admin through a team counts as another admin
+	createTestTeamAdmin(db, grace.ID, admin.ID)
+	assert.NoError(t, userAdminService.RevokeRole(db, grace.ID, ada.ID, admin.ID))
+
+	admins, err := enabledAdminIDs(db)
+	assert.NoError(t, err)
+	assert.Equal(t, []uuid.UUID{grace.ID}, admins)
+
+	// Admins through a team may drop their direct grant
+	grantTestRole(db, grace.ID, admin.ID)
+	assert.NoError(t, userAdminService.RevokeRole(db, grace.ID, grace.ID, admin.ID))
+}
+
+func TestUserAdminService_DisableUserKeepsLastAdmin(t *testing.T) {
+	db := setupTestDB()
+	userAdminService := NewUserAdminService()
+	admin := createTestRole(db, "admin")
+
+	ada := createTestUser(db, "ada", "password123")
+	grace := createTestUser(db, "grace", "password123")
+	createTestTeamAdmin(db, grace.ID, admin.ID)
+
+	err := userAdminService.DisableUser(db, ada.ID, grace.ID)
+	assert.EqualError(t, err, "cannot remove the last admin")
+
+	grantTestRole(db, ada.ID, admin.ID)
+	assert.NoError(t, userAdminService.DisableUser(db, ada.ID, grace.ID))
+}
+
+func TestUserService_DeleteUserKeepsLastAdmin(t *testing.T) {
+	db := setupTestDB()
+	userService := NewUserService()
+	admin := createTestRole(db, "admin")
+
+	ada := createTestUser(db, "ada", "password123")
+	grace := createTestUser(db, "grace", "password123")
+	createTestTeamAdmin(db, ada.ID, admin.ID)
+
+	assert.EqualError(t, userService.DeleteUser(db, ada.ID), "cannot remove the last admin")
+
+	_, err := userService.ScheduleDeletion(db, ada.ID, "password123", ClientInfo{})
+	assert.EqualError(t, err, "cannot remove the last admin")
+
+	// A deletion scheduled before is put off rather than failing the job
+	db.Model(&models.User{}).Where("id = ?", ada.ID).Update("deletion_scheduled_at", time.Now().Add(-time.Hour))
+	deleted, err := userService.DeleteScheduledUsers(db, time.Now())
+	assert.NoError(t, err)
+	assert.Equal(t, 0, deleted)
+
+	// Once someone else is admin the account can go
+	grantTestRole(db, grace.ID, admin.ID)
+	deleted, err = userService.DeleteScheduledUsers(db, time.Now())
+	assert.NoError(t, err)
+	assert.Equal(t, 1, deleted)
+
+	// Users who are not admins can always be deleted
+	linus := createTestUser(db, "linus", "password123")
+	assert.NoError(t, userService.DeleteUser(db, linus.ID))
+}
+
+func TestTeamService_KeepsLastAdmin(t *testing.T) {
+	db := setupTestDB()
+	teamService := NewTeamService()
+	admin := createTestRole(db, "admin")
+
+	ada := createTestUser(db, "ada", "password123")
+	team := createTestTeamAdmin(db, ada.ID, admin.ID)
+
+	assert.EqualError(t, teamService.RevokeRole(db, team.ID, admin.ID), "cannot remove the last admin")
+	assert.EqualError(t, teamService.RemoveMember(db, team.ID, ada.ID), "cannot remove the last admin")
+	assert.EqualError(t, teamService.DeleteTeam(db, team.ID), "cannot remove the last admin")
+
+	// Nothing was changed by the refused calls
+	admins, err := enabledAdminIDs(db)
+	assert.NoError(t, err)
+	assert.Equal(t, []uuid.UUID{ada.ID}, admins)
+
+	// With a direct grant the team can go
+	grantTestRole(db, ada.ID, admin.ID)
+	assert.NoError(t, teamService.RemoveMember(db, team.ID, ada.ID))
+	assert.NoError(t, teamService.RevokeRole(db, team.ID, admin.ID))
+	assert.NoError(t, teamService.DeleteTeam(db, team.ID))
+}
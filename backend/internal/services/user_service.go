@@ -1,7 +1,12 @@
 package services
 
 import (
+	"errors"
+	"log"
+	"strings"
 	"task-manager/backend/internal/models"
+	"task-manager/backend/internal/utils"
+	"time"
 
 	"github.com/gofrs/uuid"
 	"gorm.io/gorm"
@@ -12,12 +17,48 @@ type UserService interface {
 	GetUserProfileMalicious(db *gorm.DB, userID string) ([]models.User, error)
 	GetUsers(db *gorm.DB) ([]models.User, error)
 	DeleteUser(db *gorm.DB, userId uuid.UUID) error
+	UpdateProfile(db *gorm.DB, userID uuid.UUID, req models.UpdateProfileRequest, client ClientInfo) (models.User, error)
+	ChangePassword(db *gorm.DB, userID, currentSessionID uuid.UUID, currentPassword, newPassword string, client ClientInfo) error
+	ScheduleDeletion(db *gorm.DB, userID uuid.UUID, password string, client ClientInfo) (time.Time, error)
+	CancelDeletion(db *gorm.DB, userID uuid.UUID, client ClientInfo) error
+	DeleteScheduledUsers(db *gorm.DB, now time.Time) (int, error)
 }
 
-type UserServiceImpl struct{}
+type UserServiceImpl struct {
+	events      EventNotifier
+	revocations *RevocationStore
+	accounts    AccountService
+	policy      *PasswordPolicy
+	// deletionGracePeriod is how long a user can change their mind after
+	// asking for their account to be deleted
+	deletionGracePeriod time.Duration
+}
 
 func NewUserService() *UserServiceImpl {
-	return &UserServiceImpl{}
+	return &UserServiceImpl{
+		deletionGracePeriod: utils.GetEnvAsDuration("ACCOUNT_DELETION_GRACE_PERIOD", 14*24*time.Hour),
+	}
+}
+
+// UseEventNotifier wakes the event dispatcher after a user change commits
+func (s *UserServiceImpl) UseEventNotifier(events EventNotifier) {
+	s.events = events
+}
+
+// UseRevocationStore rejects the access tokens of deleted users at once
+func (s *UserServiceImpl) UseRevocationStore(revocations *RevocationStore) {
+	s.revocations = revocations
+}
+
+// UseEmailVerification sends a verification link when a user changes their
+// email address
+func (s *UserServiceImpl) UseEmailVerification(accounts AccountService) {
+	s.accounts = accounts
+}
+
+// UsePasswordPolicy rejects new passwords that break the policy
+func (s *UserServiceImpl) UsePasswordPolicy(policy *PasswordPolicy) {
+	s.policy = policy
 }
 
 func (s *UserServiceImpl) GetUserProfile(db *gorm.DB, userID uuid.UUID) (models.User, error) {
@@ -56,9 +97,292 @@ func (s *UserServiceImpl) GetUsers(db *gorm.DB) ([]models.User, error) {
 }
 
 func (s *UserServiceImpl) DeleteUser(db *gorm.DB, userId uuid.UUID) error {
-	result := db.Delete(&models.User{}, "id = ?", userId)
-	if result.RowsAffected == 0 {
-		return gorm.ErrRecordNotFound
+	err := db.Transaction(func(tx *gorm.DB) error {
+		if err := ensureOtherAdmin(tx, userId); err != nil {
+			return err
+		}
+
+		result := tx.Delete(&models.User{}, "id = ?", userId)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return gorm.ErrRecordNotFound
+		}
+
+		// End every session so the refresh tokens stop working too
+		if err := tx.Where("user_id = ?", userId).Delete(&models.Token{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("user_id = ?", userId).Delete(&models.UserIdentity{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("user_id = ?", userId).Delete(&models.MFAChallenge{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("user_id = ?", userId).Delete(&models.PasswordHistory{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("user_id = ?", userId).Delete(&models.LoginAttempt{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("user_id = ?", userId).Delete(&models.PersonalAccessToken{}).Error; err != nil {
+			return err
+		}
+		if err := deleteMFA(tx, userId); err != nil {
+			return err
+		}
+
+		event, err := NewUserDeletedEvent(userId, uuid.Nil)
+		if err != nil {
+			return err
+		}
+		return RecordDomainEvent(tx, event)
+	})
+	if err != nil {
+		return err
+	}
+
+	if s.events != nil {
+		s.events.Notify()
+	}
+
+	if s.revocations != nil {
+		return s.revocations.RevokeUser(db, userId)
+	}
+	return nil
+}
+
+func (s *UserServiceImpl) UpdateProfile(db *gorm.DB, userID uuid.UUID, req models.UpdateProfileRequest, client ClientInfo) (models.User, error) {
+	var user models.User
+	emailChanged := false
+	err := db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.First(&user, "id = ?", userID).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return errors.New("user not found")
+			}
+			return err
+		}
+
+		updates := map[string]interface{}{}
+		if req.Username != nil {
+			username := strings.TrimSpace(*req.Username)
+			if username == "" {
+				return errors.New("username cannot be empty")
+			}
+			if username != user.Username {
+				taken, err := profileValueTaken(tx, "username", username, userID)
+				if err != nil {
+					return err
+				}
+				if taken {
+					return errors.New("username already exists")
+				}
+				updates["username"] = username
+			}
+		}
+		if req.Email != nil {
+			email := strings.TrimSpace(*req.Email)
+			if !strings.EqualFold(email, user.Email) {
+				taken, err := profileValueTaken(tx, "email", email, userID)
+				if err != nil {
+					return err
+				}
+				if taken {
+					return errors.New("email already exists")
+				}
+				// The new address has to be confirmed again
+				updates["email"] = email
+				updates["email_verified_at"] = nil
+				emailChanged = true
+			} else if email != user.Email {
+				updates["email"] = email
+			}
+		}
+		if req.DisplayName != nil {
+			updates["display_name"] = strings.TrimSpace(*req.DisplayName)
+		}
+
+		if len(updates) == 0 {
+			return nil
+		}
+		if err := tx.Model(&user).Updates(updates).Error; err != nil {
+			return err
+		}
+
+		if emailChanged {
+			return RecordSecurityEvent(tx, models.SecurityEvent{
+				UserID:    &userID,
+				Type:      models.SecurityEventEmailChanged,
+				IPAddress: client.IPAddress,
+				UserAgent: client.UserAgent,
+			})
+		}
+		return nil
+	})
+	if err != nil {
+		return models.User{}, err
 	}
-	return result.Error
+
+	if emailChanged && s.accounts != nil {
+		if err := s.accounts.SendVerificationEmail(db, userID); err != nil {
+			return models.User{}, err
+		}
+	}
+
+	return s.GetUserProfile(db, userID)
+}
+
+// ChangePassword keeps the current session signed in and ends all others
+func (s *UserServiceImpl) ChangePassword(db *gorm.DB, userID, currentSessionID uuid.UUID, currentPassword, newPassword string, client ClientInfo) error {
+	var otherSessions []uuid.UUID
+	err := db.Transaction(func(tx *gorm.DB) error {
+		var user models.User
+		if err := tx.First(&user, "id = ?", userID).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return errors.New("user not found")
+			}
+			return err
+		}
+		if !VerifyPassword(user.Password, currentPassword) {
+			return errors.New("current password is incorrect")
+		}
+		if s.policy != nil {
+			if err := s.policy.Validate(tx, newPassword, user); err != nil {
+				return err
+			}
+		}
+
+		hashedPassword, err := HashPassword(newPassword)
+		if err != nil {
+			return err
+		}
+		if err := tx.Model(&user).Update("password", hashedPassword).Error; err != nil {
+			return err
+		}
+		if s.policy != nil {
+			if err := s.policy.Remember(tx, userID, hashedPassword); err != nil {
+				return err
+			}
+		}
+
+		if err := tx.Model(&models.Token{}).Distinct("family_id").
+			Where("user_id = ? AND family_id <> ?", userID, currentSessionID).
+			Pluck("family_id", &otherSessions).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("user_id = ? AND family_id <> ?", userID, currentSessionID).Delete(&models.Token{}).Error; err != nil {
+			return err
+		}
+
+		return RecordSecurityEvent(tx, models.SecurityEvent{
+			UserID:    &userID,
+			Type:      models.SecurityEventPasswordChanged,
+			IPAddress: client.IPAddress,
+			UserAgent: client.UserAgent,
+		})
+	})
+	if err != nil {
+		return err
+	}
+
+	if s.revocations != nil {
+		for _, sessionID := range otherSessions {
+			if err := s.revocations.RevokeSession(db, sessionID); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// ScheduleDeletion deletes the account once the grace period is over, unless
+// the user cancels first. Accounts without a password (single sign-on only)
+// need no confirmation.
+func (s *UserServiceImpl) ScheduleDeletion(db *gorm.DB, userID uuid.UUID, password string, client ClientInfo) (time.Time, error) {
+	deleteAt := time.Now().Add(s.deletionGracePeriod)
+	err := db.Transaction(func(tx *gorm.DB) error {
+		var user models.User
+		if err := tx.First(&user, "id = ?", userID).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return errors.New("user not found")
+			}
+			return err
+		}
+		if user.Password != "" && !VerifyPassword(user.Password, password) {
+			return errors.New("current password is incorrect")
+		}
+		if err := ensureOtherAdmin(tx, userID); err != nil {
+			return err
+		}
+		if user.DeletionScheduledAt != nil {
+			deleteAt = *user.DeletionScheduledAt
+			return nil
+		}
+
+		if err := tx.Model(&user).Update("deletion_scheduled_at", deleteAt).Error; err != nil {
+			return err
+		}
+		return RecordSecurityEvent(tx, models.SecurityEvent{
+			UserID:    &userID,
+			Type:      models.SecurityEventDeletionScheduled,
+			IPAddress: client.IPAddress,
+			UserAgent: client.UserAgent,
+			Details:   "account will be deleted at " + deleteAt.UTC().Format(time.RFC3339),
+		})
+	})
+	return deleteAt, err
+}
+
+func (s *UserServiceImpl) CancelDeletion(db *gorm.DB, userID uuid.UUID, client ClientInfo) error {
+	return db.Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&models.User{}).
+			Where("id = ? AND deletion_scheduled_at IS NOT NULL", userID).
+			Update("deletion_scheduled_at", nil)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return errors.New("no deletion scheduled")
+		}
+
+		return RecordSecurityEvent(tx, models.SecurityEvent{
+			UserID:    &userID,
+			Type:      models.SecurityEventDeletionCancelled,
+			IPAddress: client.IPAddress,
+			UserAgent: client.UserAgent,
+		})
+	})
+}
+
+// DeleteScheduledUsers deletes the accounts whose grace period is over. The
+// last admin is kept until someone else is made admin.
+func (s *UserServiceImpl) DeleteScheduledUsers(db *gorm.DB, now time.Time) (int, error) {
+	var userIDs []uuid.UUID
+	if err := db.Model(&models.User{}).Where("deletion_scheduled_at <= ?", now).Pluck("id", &userIDs).Error; err != nil {
+		return 0, err
+	}
+
+	deleted := 0
+	for _, userID := range userIDs {
+		if err := s.DeleteUser(db, userID); err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				deleted++
+				continue
+			}
+			if err.Error() == "cannot remove the last admin" {
+				log.Printf("Not deleting user %s: they are the last admin", userID)
+				continue
+			}
+			return deleted, err
+		}
+		deleted++
+	}
+	return deleted, nil
+}
+
+func profileValueTaken(db *gorm.DB, column, value string, userID uuid.UUID) (bool, error) {
+	var count int64
+	err := db.Model(&models.User{}).Where("LOWER("+column+") = LOWER(?) AND id <> ?", value, userID).Count(&count).Error
+	return count > 0, err
 }
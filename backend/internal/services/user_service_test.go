@@ -0,0 +1,196 @@
+package services
+
+import (
+	"task-manager/backend/internal/models"
+	"testing"
+	"time"
+
+	"github.com/gofrs/uuid"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+)
+
+func createTestUser(db *gorm.DB, username, password string) models.User {
+	hashedPassword, _ := HashPassword(password)
+	verifiedAt := time.Now()
+	user := models.User{
+		ID:              uuid.Must(uuid.NewV4()),
+		Username:        username,
+		Email:           username + "@example.com",
+		Password:        hashedPassword,
+		EmailVerifiedAt: &verifiedAt,
+	}
+	db.Create(&user)
+	return user
+}
+
+func createTestSession(db *gorm.DB, userID uuid.UUID) uuid.UUID {
+	sessionID := uuid.Must(uuid.NewV4())
+	db.Create(&models.Token{
+		ID:        sessionID,
+		UserID:    userID,
+		FamilyID:  sessionID,
+		TokenHash: HashRefreshToken(sessionID.String()),
+		ExpiresAt: time.Now().Add(time.Hour),
+	})
+	return sessionID
+}
+
+func TestUserService_UpdateProfile(t *testing.T) {
+	db := setupTestDB()
+	userService := NewUserService()
+
+	user := createTestUser(db, "ada", "password123")
+	createTestUser(db, "grace", "password123")
+
+	username := "ada_lovelace"
+	displayName := "  Ada Lovelace  "
+	updated, err := userService.UpdateProfile(db, user.ID, models.UpdateProfileRequest{Username: &username, DisplayName: &displayName}, ClientInfo{})
+	assert.NoError(t, err)
+	assert.Equal(t, "ada_lovelace", updated.Username)
+	assert.Equal(t, "Ada Lovelace", updated.DisplayName)
+	assert.NotNil(t, updated.EmailVerifiedAt)
+
+	// Only changing the case of the address keeps it verified
+	email := "Ada@Example.com"
+	updated, err = userService.UpdateProfile(db, user.ID, models.UpdateProfileRequest{Email: &email}, ClientInfo{})
+	assert.NoError(t, err)
+	assert.Equal(t, "Ada@Example.com", updated.Email)
+	assert.NotNil(t, updated.EmailVerifiedAt)
+
+	// A new address has to be verified again
+	email = "ada@lovelace.dev"
+	updated, err = userService.UpdateProfile(db, user.ID, models.UpdateProfileRequest{Email: &email}, ClientInfo{})
+	assert.NoError(t, err)
+	assert.Equal(t, "ada@lovelace.dev", updated.Email)
+	assert.Nil(t, updated.EmailVerifiedAt)
+
+	var events int64
+	db.Model(&models.SecurityEvent{}).Where("user_id = ? AND type = ?", user.ID, models.SecurityEventEmailChanged).Count(&events)
+	assert.Equal(t, int64(1), events)
+}
+
+func TestUserService_UpdateProfileRejectsTakenValues(t *testing.T) {
+	db := setupTestDB()
+	userService := NewUserService()
+
+	user := createTestUser(db, "ada", "password123")
+	createTestUser(db, "grace", "password123")
+
+	tests := []struct {
+		name    string
+		req     models.UpdateProfileRequest
+		wantErr string
+	}{
+		{
+			name:    "username taken ignoring case",
+			req:     models.UpdateProfileRequest{Username: stringPtr("GRACE")},
+			wantErr: "username already exists",
+		},
+		{
+			name:    "email taken ignoring case",
+			req:     models.UpdateProfileRequest{Email: stringPtr("Grace@Example.com")},
+			wantErr: "email already exists",
+		},
+		{
+			name:    "blank username",
+			req:     models.UpdateProfileRequest{Username: stringPtr("   ")},
+			wantErr: "username cannot be empty",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := userService.UpdateProfile(db, user.ID, tt.req, ClientInfo{})
+			assert.EqualError(t, err, tt.wantErr)
+		})
+	}
+
+	var stored models.User
+	db.First(&stored, "id = ?", user.ID)
+	assert.Equal(t, "ada", stored.Username)
+	assert.Equal(t, "ada@example.com", stored.Email)
+}
+
+func TestUserService_ChangePassword(t *testing.T) {
+	db := setupTestDB()
+	userService := NewUserService()
+
+	user := createTestUser(db, "ada", "password123")
+	currentSession := createTestSession(db, user.ID)
+	otherSession := createTestSession(db, user.ID)
+
+	err := userService.ChangePassword(db, user.ID, currentSession, "wrong-password", "newpassword456", ClientInfo{})
+	assert.EqualError(t, err, "current password is incorrect")
+
+	err = userService.ChangePassword(db, user.ID, currentSession, "password123", "newpassword456", ClientInfo{})
+	assert.NoError(t, err)
+
+	var stored models.User
+	db.First(&stored, "id = ?", user.ID)
+	assert.True(t, VerifyPassword(stored.Password, "newpassword456"))
+	assert.False(t, VerifyPassword(stored.Password, "password123"))
+
+	// Only the session that changed the password stays signed in
+	var sessions []uuid.UUID
+	db.Model(&models.Token{}).Where("user_id = ?", user.ID).Pluck("family_id", &sessions)
+	assert.Equal(t, []uuid.UUID{currentSession}, sessions)
+	assert.NotContains(t, sessions, otherSession)
+}
+
+func TestUserService_ScheduleAndCancelDeletion(t *testing.T) {
+	db := setupTestDB()
+	userService := NewUserService()
+	userService.deletionGracePeriod = 24 * time.Hour
+
+	user := createTestUser(db, "ada", "password123")
+
+	_, err := userService.ScheduleDeletion(db, user.ID, "wrong-password", ClientInfo{})
+	assert.EqualError(t, err, "current password is incorrect")
+
+	deleteAt, err := userService.ScheduleDeletion(db, user.ID, "password123", ClientInfo{})
+	assert.NoError(t, err)
+	assert.WithinDuration(t, time.Now().Add(24*time.Hour), deleteAt, time.Minute)
+
+	// Asking again keeps the original date
+	again, err := userService.ScheduleDeletion(db, user.ID, "password123", ClientInfo{})
+	assert.NoError(t, err)
+	assert.WithinDuration(t, deleteAt, again, time.Second)
+
+	assert.NoError(t, userService.CancelDeletion(db, user.ID, ClientInfo{}))
+	assert.EqualError(t, userService.CancelDeletion(db, user.ID, ClientInfo{}), "no deletion scheduled")
+
+	var stored models.User
+	db.First(&stored, "id = ?", user.ID)
+	assert.Nil(t, stored.DeletionScheduledAt)
+}
+
+func TestUserService_DeleteScheduledUsers(t *testing.T) {
+	db := setupTestDB()
+	userService := NewUserService()
+
+	due := createTestUser(db, "ada", "password123")
+	later := createTestUser(db, "grace", "password123")
+	kept := createTestUser(db, "linus", "password123")
+	createTestSession(db, due.ID)
+
+	now := time.Now()
+	db.Model(&models.User{}).Where("id = ?", due.ID).Update("deletion_scheduled_at", now.Add(-time.Minute))
+	db.Model(&models.User{}).Where("id = ?", later.ID).Update("deletion_scheduled_at", now.Add(time.Hour))
+
+	deleted, err := userService.DeleteScheduledUsers(db, now)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, deleted)
+
+	var remaining []uuid.UUID
+	db.Model(&models.User{}).Order("username").Pluck("id", &remaining)
+	assert.Equal(t, []uuid.UUID{later.ID, kept.ID}, remaining)
+
+	var sessions int64
+	db.Model(&models.Token{}).Where("user_id = ?", due.ID).Count(&sessions)
+	assert.Equal(t, int64(0), sessions)
+}
+
+func stringPtr(value string) *string {
+	return &value
+}
@@ -0,0 +1,389 @@
+package services
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"task-manager/backend/internal/models"
+	"task-manager/backend/internal/utils"
+	"time"
+
+	"github.com/gofrs/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+const (
+	maxWebhookAttempts = 8
+	maxWebhookBackoff  = time.Hour
+
+	// webhookClaimLease outlasts a full batch of posts timing out, so a
+	// delivery is only claimed again if its dispatcher died
+	webhookClaimLease = 15 * time.Minute
+)
+
+type WebhookService interface {
+	CreateWebhook(db *gorm.DB, webhook models.Webhook) (*models.Webhook, error)
+	UpdateWebhook(db *gorm.DB, webhookID uuid.UUID, updateReq models.WebhookUpdateRequest) (*models.Webhook, error)
+	DeleteWebhook(db *gorm.DB, webhookID uuid.UUID) error
+	GetWebhookByID(db *gorm.DB, webhookID uuid.UUID) (*models.Webhook, error)
+	GetWebhooks(db *gorm.DB) ([]models.Webhook, error)
+	GetDeliveries(db *gorm.DB, webhookID uuid.UUID, pagination utils.PaginationParams) (utils.PaginationResponse, error)
+	EnqueueEvent(db *gorm.DB, eventType string, data interface{}) error
+	SendTestEvent(db *gorm.DB, webhookID uuid.UUID) (*models.WebhookDelivery, error)
+	ProcessDeliveries(db *gorm.DB, client *http.Client, limit int) (int, error)
+}
+
+type WebhookServiceImpl struct{}
+
+func NewWebhookService() *WebhookServiceImpl {
+	return &WebhookServiceImpl{}
+}
+
+// webhookEnvelope is the JSON body posted to subscribers
+type webhookEnvelope struct {
+	ID        uuid.UUID   `json:"id"`
+	Event     string      `json:"event"`
+	CreatedAt time.Time   `json:"created_at"`
+	Data      interface{} `json:"data"`
+}
+
+func (s *WebhookServiceImpl) CreateWebhook(db *gorm.DB, webhook models.Webhook) (*models.Webhook, error) {
+	webhook.ID = uuid.Must(uuid.NewV4())
+	webhook.Active = true
+
+	if webhook.Secret == "" {
+		secret, err := generateWebhookSecret()
+		if err != nil {
+			return nil, err
+		}
+		webhook.Secret = secret
+	}
+
+	if err := db.Create(&webhook).Error; err != nil {
+		return nil, err
+	}
+
+	return &webhook, nil
+}
+
+func (s *WebhookServiceImpl) UpdateWebhook(db *gorm.DB, webhookID uuid.UUID, updateReq models.WebhookUpdateRequest) (*models.Webhook, error) {
+	webhook, err := s.GetWebhookByID(db, webhookID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Update fields if provided
+	if updateReq.URL != nil {
+		webhook.URL = *updateReq.URL
+	}
+	if updateReq.Secret != nil && *updateReq.Secret != "" {
+		webhook.Secret = *updateReq.Secret
+	}
+	if updateReq.EventTypes != nil {
+		webhook.EventTypes = updateReq.EventTypes
+	}
+	if updateReq.Active != nil {
+		webhook.Active = *updateReq.Active
+	}
+
+	if err := db.Save(webhook).Error; err != nil {
+		return nil, err
+	}
+
+	return webhook, nil
+}
+
+func (s *WebhookServiceImpl) DeleteWebhook(db *gorm.DB, webhookID uuid.UUID) error {
+	return db.Transaction(func(tx *gorm.DB) error {
+		result := tx.Delete(&models.Webhook{}, "id = ?", webhookID)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return errors.New("webhook not found")
+		}
+
+		return tx.Where("webhook_id = ?", webhookID).Delete(&models.WebhookDelivery{}).Error
+	})
+}
+
+func (s *WebhookServiceImpl) GetWebhookByID(db *gorm.DB, webhookID uuid.UUID) (*models.Webhook, error) {
+	var webhook models.Webhook
+
+	result := db.Where("id = ?", webhookID).First(&webhook)
+	if result.Error != nil {
+		if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+			return nil, errors.New("webhook not found")
+		}
+		return nil, result.Error
+	}
+
+	return &webhook, nil
+}
+
+func (s *WebhookServiceImpl) GetWebhooks(db *gorm.DB) ([]models.Webhook, error) {
+	var webhooks []models.Webhook
+
+	result := db.Order("created_at desc").Find(&webhooks)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+
+	return webhooks, nil
+}
+
+func (s *WebhookServiceImpl) GetDeliveries(db *gorm.DB, webhookID uuid.UUID, pagination utils.PaginationParams) (utils.PaginationResponse, error) {
+	var deliveries []models.WebhookDelivery
+	var total int64
+
+	query := db.Model(&models.WebhookDelivery{}).Where("webhook_id = ?", webhookID)
+
+	if err := query.Count(&total).Error; err != nil {
+		return utils.PaginationResponse{}, err
+	}
+
+	result := query.Order("created_at desc").Offset(pagination.Offset).Limit(pagination.Limit).Find(&deliveries)
+	if result.Error != nil {
+		return utils.PaginationResponse{}, result.Error
+	}
+
+	return utils.CreatePaginationResponse(deliveries, total, pagination), nil
+}
+
+// EnqueueEvent queues a delivery for every active webhook subscribed to the
+// event type. Sending happens later in the dispatcher.
+func (s *WebhookServiceImpl) EnqueueEvent(db *gorm.DB, eventType string, data interface{}) error {
+	var webhooks []models.Webhook
+	if err := db.Where("active = ?", true).Find(&webhooks).Error; err != nil {
+		return err
+	}
+
+	var deliveries []models.WebhookDelivery
+	for _, webhook := range webhooks {
+		if !subscribedTo(webhook, eventType) {
+			continue
+		}
+
+		delivery, err := newWebhookDelivery(webhook.ID, eventType, data)
+		if err != nil {
+			return err
+		}
+		deliveries = append(deliveries, delivery)
+	}
+
+	if len(deliveries) == 0 {
+		return nil
+	}
+
+	return db.Create(&deliveries).Error
+}
+
+func (s *WebhookServiceImpl) SendTestEvent(db *gorm.DB, webhookID uuid.UUID) (*models.WebhookDelivery, error) {
+	webhook, err := s.GetWebhookByID(db, webhookID)
+	if err != nil {
+		return nil, err
+	}
+
+	delivery, err := newWebhookDelivery(webhook.ID, models.WebhookEventTest, map[string]string{
+		"message": "This is a test event from Taskify",
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if err := db.Create(&delivery).Error; err != nil {
+		return nil, err
+	}
+
+	return &delivery, nil
+}
+
+// ProcessDeliveries posts up to limit due deliveries. Failed attempts are
+// retried with exponential backoff until maxWebhookAttempts is reached.
+// Deliveries are claimed and committed before any request is made, so
+// slow receivers hold no row locks and one failed write cannot make
+// earlier deliveries go out again.
+func (s *WebhookServiceImpl) ProcessDeliveries(db *gorm.DB, client *http.Client, limit int) (int, error) {
+	deliveries, err := claimWebhookDeliveries(db, limit)
+	if err != nil {
+		return 0, err
+	}
+
+	delivered := 0
+	for _, delivery := range deliveries {
+		var webhook models.Webhook
+		if err := db.Where("id = ?", delivery.WebhookID).First(&webhook).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				db.Model(&delivery).Updates(map[string]interface{}{"status": models.WebhookDeliveryFailed, "last_error": "webhook deleted"})
+			} else {
+				log.Printf("Failed to load webhook for delivery %s: %v", delivery.ID, err)
+			}
+			continue
+		}
+
+		statusCode, sendErr := postWebhook(client, webhook, delivery)
+
+		updates := map[string]interface{}{
+			"attempts":        delivery.Attempts + 1,
+			"response_status": statusCode,
+		}
+		if sendErr == nil {
+			updates["status"] = models.WebhookDeliveryDelivered
+			updates["delivered_at"] = time.Now()
+			updates["last_error"] = ""
+			delivered++
+		} else {
+			updates["last_error"] = sendErr.Error()
+			if delivery.Attempts+1 >= maxWebhookAttempts {
+				updates["status"] = models.WebhookDeliveryFailed
+			} else {
+				updates["next_attempt_at"] = time.Now().Add(WebhookBackoff(delivery.Attempts + 1))
+			}
+		}
+
+		if err := db.Model(&delivery).Updates(updates).Error; err != nil {
+			log.Printf("Failed to record result of webhook delivery %s: %v", delivery.ID, err)
+		}
+	}
+
+	return delivered, nil
+}
+
+// claimWebhookDeliveries leases up to limit due deliveries to the caller by
+// pushing their next attempt past the time a batch can take to post
+func claimWebhookDeliveries(db *gorm.DB, limit int) ([]models.WebhookDelivery, error) {
+	var deliveries []models.WebhookDelivery
+
+	err := db.Transaction(func(tx *gorm.DB) error {
+		result := tx.Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+			Where("status = ? AND next_attempt_at <= ?", models.WebhookDeliveryPending, time.Now()).
+			Order("next_attempt_at asc").
+			Limit(limit).
+			Find(&deliveries)
+		if result.Error != nil || len(deliveries) == 0 {
+			return result.Error
+		}
+
+		ids := make([]uuid.UUID, 0, len(deliveries))
+		for _, delivery := range deliveries {
+			ids = append(ids, delivery.ID)
+		}
+		return tx.Model(&models.WebhookDelivery{}).Where("id IN ?", ids).Update("next_attempt_at", time.Now().Add(webhookClaimLease)).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return deliveries, nil
+}
+
+// WebhookBackoff returns the wait before the next attempt: 30s, 1m, 2m, ...
+// capped at one hour
+func WebhookBackoff(attempts int) time.Duration {
+	backoff := 30 * time.Second
+	for i := 1; i < attempts; i++ {
+		backoff *= 2
+		if backoff >= maxWebhookBackoff {
+			return maxWebhookBackoff
+		}
+	}
+	return backoff
+}
+
+// SignWebhookPayload returns the value of the X-Taskify-Signature header:
+// the hex HMAC-SHA256 of the raw body keyed with the webhook secret
+func SignWebhookPayload(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+func postWebhook(client *http.Client, webhook models.Webhook, delivery models.WebhookDelivery) (int, error) {
+	body := []byte(delivery.Payload)
+
+	req, err := http.NewRequest(http.MethodPost, webhook.URL, bytes.NewReader(body))
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "Taskify-Webhooks/1.0")
+	req.Header.Set("X-Taskify-Event", delivery.EventType)
+	req.Header.Set("X-Taskify-Delivery", delivery.ID.String())
+	req.Header.Set("X-Taskify-Signature", SignWebhookPayload(webhook.Secret, body))
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 64*1024))
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return resp.StatusCode, fmt.Errorf("unexpected response status %d", resp.StatusCode)
+	}
+
+	return resp.StatusCode, nil
+}
+
+func newWebhookDelivery(webhookID uuid.UUID, eventType string, data interface{}) (models.WebhookDelivery, error) {
+	deliveryID := uuid.Must(uuid.NewV4())
+
+	payload, err := json.Marshal(webhookEnvelope{
+		ID:        deliveryID,
+		Event:     eventType,
+		CreatedAt: time.Now().UTC(),
+		Data:      data,
+	})
+	if err != nil {
+		return models.WebhookDelivery{}, err
+	}
+
+	return models.WebhookDelivery{
+		ID:            deliveryID,
+		WebhookID:     webhookID,
+		EventType:     eventType,
+		Payload:       string(payload),
+		Status:        models.WebhookDeliveryPending,
+		NextAttemptAt: time.Now(),
+	}, nil
+}
+
+func subscribedTo(webhook models.Webhook, eventType string) bool {
+	for _, t := range webhook.EventTypes {
+		if t == eventType {
+			return true
+		}
+	}
+	return false
+}
+
+func generateWebhookSecret() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
+
+// RunWebhookDispatcher delivers queued webhook events until the process exits
+func RunWebhookDispatcher(db *gorm.DB, webhookService WebhookService, interval time.Duration) {
+	client := &http.Client{Timeout: 10 * time.Second}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		if _, err := webhookService.ProcessDeliveries(db, client, 50); err != nil {
+			log.Printf("Failed to process webhook deliveries: %v", err)
+		}
+	}
+}
@@ -0,0 +1,136 @@
+package services
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"task-manager/backend/internal/models"
+	"testing"
+	"time"
+
+	"github.com/gofrs/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSignWebhookPayload(t *testing.T) {
+	// Reference value from: echo -n '{"event":"task.created"}' | openssl dgst -sha256 -hmac secret
+	signature := SignWebhookPayload("secret", []byte(`{"event":"task.created"}`))
+	assert.Equal(t, "sha256=b835dced16788582434913f6e29d9ff8b26a16bd0704d9238275b871c3e7f007", signature)
+	assert.NotEqual(t, signature, SignWebhookPayload("other", []byte(`{"event":"task.created"}`)))
+}
+
+func TestWebhookBackoff(t *testing.T) {
+	assert.Equal(t, 30*time.Second, WebhookBackoff(1))
+	assert.Equal(t, time.Minute, WebhookBackoff(2))
+	assert.Equal(t, 4*time.Minute, WebhookBackoff(4))
+	assert.Equal(t, time.Hour, WebhookBackoff(20))
+}
+
+func TestPostWebhook_SignsBody(t *testing.T) {
+	var receivedBody []byte
+	var receivedHeaders http.Header
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		receivedBody, _ = io.ReadAll(r.Body)
+		receivedHeaders = r.Header
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	webhook := models.Webhook{ID: uuid.Must(uuid.NewV4()), URL: server.URL, Secret: "s3cret"}
+	delivery, err := newWebhookDelivery(webhook.ID, models.WebhookEventTaskUpdated, map[string]string{"title": "Ship it"})
+	assert.NoError(t, err)
+
+	status, err := postWebhook(server.Client(), webhook, delivery)
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusNoContent, status)
+
+	assert.Equal(t, delivery.Payload, string(receivedBody))
+	assert.Equal(t, "task.updated", receivedHeaders.Get("X-Taskify-Event"))
+	assert.Equal(t, delivery.ID.String(), receivedHeaders.Get("X-Taskify-Delivery"))
+	assert.Equal(t, SignWebhookPayload("s3cret", receivedBody), receivedHeaders.Get("X-Taskify-Signature"))
+}
+
+func TestPostWebhook_FailsOnErrorStatus(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	defer server.Close()
+
+	webhook := models.Webhook{ID: uuid.Must(uuid.NewV4()), URL: server.URL, Secret: "s3cret"}
+	delivery, _ := newWebhookDelivery(webhook.ID, models.WebhookEventTest, nil)
+
+	status, err := postWebhook(server.Client(), webhook, delivery)
+	assert.Error(t, err)
+	assert.Equal(t, http.StatusBadGateway, status)
+}
+
+func TestWebhookService_ProcessDeliveries(t *testing.T) {
+	db := setupTestDB()
+	webhookService := NewWebhookService()
+
+	received := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		received++
+		if r.Header.Get("X-Taskify-Event") == models.WebhookEventTaskDeleted {
+			w.WriteHeader(http.StatusBadGateway)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	webhook := models.Webhook{ID: uuid.Must(uuid.NewV4()), URL: server.URL, Secret: "s3cret", EventTypes: []string{"task.updated"}, Active: true}
+	db.Create(&webhook)
+
+	succeeding, _ := newWebhookDelivery(webhook.ID, models.WebhookEventTaskUpdated, nil)
+	failing, _ := newWebhookDelivery(webhook.ID, models.WebhookEventTaskDeleted, nil)
+	orphaned, _ := newWebhookDelivery(uuid.Must(uuid.NewV4()), models.WebhookEventTaskUpdated, nil)
+	for _, delivery := range []models.WebhookDelivery{succeeding, failing, orphaned} {
+		delivery.NextAttemptAt = time.Now().Add(-time.Minute)
+		db.Create(&delivery)
+	}
+
+	delivered, err := webhookService.ProcessDeliveries(db, server.Client(), 10)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, delivered)
+	assert.Equal(t, 2, received)
+
+	var stored, retried, dropped models.WebhookDelivery
+	db.First(&stored, "id = ?", succeeding.ID)
+	assert.Equal(t, models.WebhookDeliveryDelivered, stored.Status)
+	assert.Equal(t, http.StatusNoContent, stored.ResponseStatus)
+
+	db.First(&retried, "id = ?", failing.ID)
+	assert.Equal(t, models.WebhookDeliveryPending, retried.Status)
+	assert.Equal(t, 1, retried.Attempts)
+	assert.Equal(t, http.StatusBadGateway, retried.ResponseStatus)
+	assert.True(t, retried.NextAttemptAt.After(time.Now()))
+
+	db.First(&dropped, "id = ?", orphaned.ID)
+	assert.Equal(t, models.WebhookDeliveryFailed, dropped.Status)
+	assert.Equal(t, "webhook deleted", dropped.LastError)
+
+	// Nothing is due any more, so nothing is posted again
+	delivered, err = webhookService.ProcessDeliveries(db, server.Client(), 10)
+	assert.NoError(t, err)
+	assert.Equal(t, 0, delivered)
+	assert.Equal(t, 2, received)
+}
+
+func TestClaimWebhookDeliveries_LeasesClaimedRows(t *testing.T) {
+	db := setupTestDB()
+
+	delivery, _ := newWebhookDelivery(uuid.Must(uuid.NewV4()), models.WebhookEventTaskUpdated, nil)
+	delivery.NextAttemptAt = time.Now().Add(-time.Minute)
+	db.Create(&delivery)
+
+	claimed, err := claimWebhookDeliveries(db, 10)
+	assert.NoError(t, err)
+	assert.Len(t, claimed, 1)
+
+	// Another dispatcher finds nothing until the lease runs out
+	claimed, err = claimWebhookDeliveries(db, 10)
+	assert.NoError(t, err)
+	assert.Empty(t, claimed)
+}
@@ -2,18 +2,23 @@ package utils
 
 import (
 	"fmt"
+	"sync"
 	"time"
 
 	"github.com/golang-jwt/jwt/v5"
 	"github.com/gofrs/uuid"
 )
 
+// Claims of an access token. Compact tokens carry only the user, role and
+// session IDs; the server resolves the rest when the token is used.
 type Claims struct {
 	UserID      uuid.UUID     `json:"user_id"`
-	Username    string        `json:"username"`
-	Roles       []string      `json:"roles"`
-	IsAdmin     bool          `json:"is_admin"`
-	Permissions []Permission  `json:"permissions"`
+	Username    string        `json:"username,omitempty"`
+	Roles       []string      `json:"roles,omitempty"`
+	RoleIDs     []uuid.UUID   `json:"rids,omitempty"`
+	IsAdmin     bool          `json:"is_admin,omitempty"`
+	Permissions []Permission  `json:"permissions,omitempty"`
+	SessionID   uuid.UUID     `json:"sid"`
 	jwt.RegisteredClaims
 }
 
@@ -22,35 +27,77 @@ type Permission struct {
 	Actions  []string `json:"actions"`
 }
 
-var jwtSecret = []byte("your-secret-key-change-this-in-production")
+// AccessTokenLifetime is how long an access token is accepted
+const AccessTokenLifetime = time.Hour
 
-func GenerateJWT(userID uuid.UUID, username string, roles []string, isAdmin bool, permissions []Permission) (string, error) {
+var (
+	keysMu sync.Mutex
+	keys   *KeyManager
+)
+
+// SetKeyManager replaces the keys tokens are signed and verified with
+func SetKeyManager(km *KeyManager) {
+	keysMu.Lock()
+	defer keysMu.Unlock()
+	keys = km
+}
+
+// Keys returns the active key manager. Until one is set a throwaway HS256
+// key is used, which is enough for tests.
+func Keys() *KeyManager {
+	keysMu.Lock()
+	defer keysMu.Unlock()
+	if keys == nil {
+		signing, err := GenerateSigningKey("HS256")
+		if err != nil {
+			panic(err)
+		}
+		keys, _ = NewKeyManager(signing)
+	}
+	return keys
+}
+
+func GenerateJWT(userID uuid.UUID, username string, roles []string, isAdmin bool, permissions []Permission, sessionID uuid.UUID) (string, error) {
 	claims := Claims{
-		UserID:      userID,
-		Username:    username,
-		Roles:       roles,
-		IsAdmin:     isAdmin,
-		Permissions: permissions,
-		RegisteredClaims: jwt.RegisteredClaims{
-			ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Hour)),
-			IssuedAt:  jwt.NewNumericDate(time.Now()),
-			NotBefore: jwt.NewNumericDate(time.Now()),
-			Issuer:    "task-manager",
-			Subject:   userID.String(),
-		},
+		UserID:           userID,
+		Username:         username,
+		Roles:            roles,
+		IsAdmin:          isAdmin,
+		Permissions:      permissions,
+		SessionID:        sessionID,
+		RegisteredClaims: newRegisteredClaims(userID),
 	}
 
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	return token.SignedString(jwtSecret)
+	return Keys().Sign(claims)
+}
+
+// GenerateCompactJWT signs a token that stays the same size however many
+// permissions the user's roles hold
+func GenerateCompactJWT(userID uuid.UUID, roleIDs []uuid.UUID, sessionID uuid.UUID) (string, error) {
+	claims := Claims{
+		UserID:           userID,
+		RoleIDs:          roleIDs,
+		SessionID:        sessionID,
+		RegisteredClaims: newRegisteredClaims(userID),
+	}
+
+	return Keys().Sign(claims)
+}
+
+func newRegisteredClaims(userID uuid.UUID) jwt.RegisteredClaims {
+	now := time.Now()
+	return jwt.RegisteredClaims{
+		ID:        uuid.Must(uuid.NewV4()).String(),
+		ExpiresAt: jwt.NewNumericDate(now.Add(AccessTokenLifetime)),
+		IssuedAt:  jwt.NewNumericDate(now),
+		NotBefore: jwt.NewNumericDate(now),
+		Issuer:    "task-manager",
+		Subject:   userID.String(),
+	}
 }
 
 func ValidateJWT(tokenString string) (*Claims, error) {
-	token, err := jwt.ParseWithClaims(tokenString, &Claims{}, func(token *jwt.Token) (interface{}, error) {
-		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
-			return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
-		}
-		return jwtSecret, nil
-	})
+	token, err := Keys().Parse(tokenString, &Claims{})
 
 	if err != nil {
 		return nil, err
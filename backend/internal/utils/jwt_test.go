@@ -0,0 +1,33 @@
+package utils
+
+import (
+	"testing"
+
+	"github.com/gofrs/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGenerateCompactJWT(t *testing.T) {
+	signing, err := GenerateSigningKey("HS256")
+	require.NoError(t, err)
+	km, err := NewKeyManager(signing)
+	require.NoError(t, err)
+	SetKeyManager(km)
+
+	userID := uuid.Must(uuid.NewV4())
+	roleIDs := []uuid.UUID{uuid.Must(uuid.NewV4())}
+	sessionID := uuid.Must(uuid.NewV4())
+
+	tokenString, err := GenerateCompactJWT(userID, roleIDs, sessionID)
+	require.NoError(t, err)
+
+	claims, err := ValidateJWT(tokenString)
+	require.NoError(t, err)
+	assert.Equal(t, userID, claims.UserID)
+	assert.Equal(t, roleIDs, claims.RoleIDs)
+	assert.Equal(t, sessionID, claims.SessionID)
+	assert.Empty(t, claims.Username)
+	assert.Empty(t, claims.Permissions)
+	assert.NotEmpty(t, claims.ID)
+}
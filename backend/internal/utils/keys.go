@@ -0,0 +1,350 @@
+package utils
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"log"
+	"math/big"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// minHMACSecretLength matches the HS256 output size; shorter secrets are
+// brute-forceable
+const minHMACSecretLength = 32
+
+// SigningKey is a key that tokens can be verified with and, when it holds a
+// private part, signed with
+type SigningKey struct {
+	ID        string
+	Method    jwt.SigningMethod
+	signKey   interface{}
+	verifyKey interface{}
+}
+
+// CanSign reports whether the key holds the secret or private part
+func (k *SigningKey) CanSign() bool {
+	return k.signKey != nil
+}
+
+// NewHMACKey returns an HS256 key. The ID defaults to a fingerprint of the secret.
+func NewHMACKey(id string, secret []byte) (*SigningKey, error) {
+	if len(secret) < minHMACSecretLength {
+		return nil, fmt.Errorf("HS256 secret must be at least %d bytes", minHMACSecretLength)
+	}
+	if id == "" {
+		id = keyFingerprint(secret)
+	}
+	return &SigningKey{ID: id, Method: jwt.SigningMethodHS256, signKey: secret, verifyKey: secret}, nil
+}
+
+// ParseKeyPEM reads an RSA (RS256) or Ed25519 (EdDSA) key from PEM. Private
+// keys can sign, public keys only verify. The ID defaults to a fingerprint of
+// the public key.
+func ParseKeyPEM(id string, data []byte) (*SigningKey, error) {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, errors.New("no PEM block found")
+	}
+
+	var parsed interface{}
+	var err error
+	switch block.Type {
+	case "RSA PRIVATE KEY":
+		parsed, err = x509.ParsePKCS1PrivateKey(block.Bytes)
+	case "PRIVATE KEY":
+		parsed, err = x509.ParsePKCS8PrivateKey(block.Bytes)
+	case "RSA PUBLIC KEY":
+		parsed, err = x509.ParsePKCS1PublicKey(block.Bytes)
+	case "PUBLIC KEY":
+		parsed, err = x509.ParsePKIXPublicKey(block.Bytes)
+	default:
+		return nil, fmt.Errorf("unsupported PEM block %q", block.Type)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	key := &SigningKey{ID: id}
+	switch k := parsed.(type) {
+	case *rsa.PrivateKey:
+		key.Method, key.signKey, key.verifyKey = jwt.SigningMethodRS256, k, &k.PublicKey
+	case *rsa.PublicKey:
+		key.Method, key.verifyKey = jwt.SigningMethodRS256, k
+	case ed25519.PrivateKey:
+		key.Method, key.signKey, key.verifyKey = jwt.SigningMethodEdDSA, k, k.Public()
+	case ed25519.PublicKey:
+		key.Method, key.verifyKey = jwt.SigningMethodEdDSA, k
+	default:
+		return nil, fmt.Errorf("unsupported key type %T", parsed)
+	}
+
+	if key.Method == jwt.SigningMethodRS256 && key.verifyKey.(*rsa.PublicKey).N.BitLen() < 2048 {
+		return nil, errors.New("RSA keys must be at least 2048 bits")
+	}
+
+	if key.ID == "" {
+		der, err := x509.MarshalPKIXPublicKey(key.verifyKey)
+		if err != nil {
+			return nil, err
+		}
+		key.ID = keyFingerprint(der)
+	}
+	return key, nil
+}
+
+func keyFingerprint(material []byte) string {
+	sum := sha256.Sum256(material)
+	return hex.EncodeToString(sum[:8])
+}
+
+// KeyManager signs tokens with one key and verifies them against every
+// configured key, so old tokens stay valid while keys are rotated
+type KeyManager struct {
+	signing *SigningKey
+	keys    map[string]*SigningKey
+}
+
+func NewKeyManager(signing *SigningKey, verification ...*SigningKey) (*KeyManager, error) {
+	if signing == nil || !signing.CanSign() {
+		return nil, errors.New("signing key must include a secret or private key")
+	}
+
+	km := &KeyManager{signing: signing, keys: map[string]*SigningKey{signing.ID: signing}}
+	for _, key := range verification {
+		if _, exists := km.keys[key.ID]; exists {
+			return nil, fmt.Errorf("duplicate key id %q", key.ID)
+		}
+		km.keys[key.ID] = key
+	}
+	return km, nil
+}
+
+// SigningKeyID returns the kid put on newly issued tokens
+func (km *KeyManager) SigningKeyID() string {
+	return km.signing.ID
+}
+
+func (km *KeyManager) Sign(claims jwt.Claims) (string, error) {
+	token := jwt.NewWithClaims(km.signing.Method, claims)
+	token.Header["kid"] = km.signing.ID
+	return token.SignedString(km.signing.signKey)
+}
+
+// Parse verifies the token with the key named by its kid header. Tokens
+// without a kid were issued before keys had IDs and are checked against the
+// signing key. The token's alg has to match the key's, so a public key can
+// never be used as an HMAC secret.
+func (km *KeyManager) Parse(tokenString string, claims jwt.Claims) (*jwt.Token, error) {
+	return jwt.ParseWithClaims(tokenString, claims, func(token *jwt.Token) (interface{}, error) {
+		key := km.signing
+		if kid, ok := token.Header["kid"]; ok {
+			id, _ := kid.(string)
+			if key, ok = km.keys[id]; !ok {
+				return nil, fmt.Errorf("unknown signing key %q", id)
+			}
+		}
+
+		if token.Method.Alg() != key.Method.Alg() {
+			return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
+		}
+		return key.verifyKey, nil
+	})
+}
+
+// JSONWebKey is the public part of a verification key as published in the JWKS
+type JSONWebKey struct {
+	KeyType   string `json:"kty"`
+	KeyID     string `json:"kid"`
+	Use       string `json:"use"`
+	Algorithm string `json:"alg"`
+	N         string `json:"n,omitempty"`
+	E         string `json:"e,omitempty"`
+	Curve     string `json:"crv,omitempty"`
+	X         string `json:"x,omitempty"`
+}
+
+type JSONWebKeySet struct {
+	Keys []JSONWebKey `json:"keys"`
+}
+
+// JWKS lists the public verification keys. HMAC secrets are never published,
+// so a deployment using only HS256 returns an empty set.
+func (km *KeyManager) JWKS() JSONWebKeySet {
+	set := JSONWebKeySet{Keys: []JSONWebKey{}}
+	for _, key := range km.sortedKeys() {
+		jwk := JSONWebKey{KeyID: key.ID, Use: "sig", Algorithm: key.Method.Alg()}
+		switch pub := key.verifyKey.(type) {
+		case *rsa.PublicKey:
+			jwk.KeyType = "RSA"
+			jwk.N = base64.RawURLEncoding.EncodeToString(pub.N.Bytes())
+			jwk.E = base64.RawURLEncoding.EncodeToString(big.NewInt(int64(pub.E)).Bytes())
+		case ed25519.PublicKey:
+			jwk.KeyType = "OKP"
+			jwk.Curve = "Ed25519"
+			jwk.X = base64.RawURLEncoding.EncodeToString(pub)
+		default:
+			continue
+		}
+		set.Keys = append(set.Keys, jwk)
+	}
+	return set
+}
+
+// sortedKeys returns the signing key first, then the rest by ID, so the JWKS
+// output is stable
+func (km *KeyManager) sortedKeys() []*SigningKey {
+	keys := []*SigningKey{km.signing}
+	var others []string
+	for id := range km.keys {
+		if id != km.signing.ID {
+			others = append(others, id)
+		}
+	}
+	sort.Strings(others)
+	for _, id := range others {
+		keys = append(keys, km.keys[id])
+	}
+	return keys
+}
+
+// LoadKeyManager builds the key manager from the environment:
+//
+//	JWT_SIGNING_ALG          HS256 (default), RS256 or EdDSA
+//	JWT_SECRET               HS256 secret (or JWT_SECRET_FILE)
+//	JWT_PRIVATE_KEY_FILE     PEM private key for RS256 / EdDSA
+//	JWT_KEY_ID               kid of the signing key (defaults to a fingerprint)
+//	JWT_VERIFICATION_KEYS    comma separated [kid=]path list of older keys that
+//	                         are still accepted: PEM keys, or files holding a
+//	                         previous HS256 secret
+//
+// Without any HS256 secret a random one is generated, which only suits local
+// development since tokens stop working on restart.
+func LoadKeyManager() (*KeyManager, error) {
+	keyID := os.Getenv("JWT_KEY_ID")
+
+	var signing *SigningKey
+	var err error
+	switch alg := GetEnv("JWT_SIGNING_ALG", "HS256"); alg {
+	case "HS256":
+		secret, err := loadSecret()
+		if err != nil {
+			return nil, err
+		}
+		if secret == nil {
+			log.Println("JWT_SECRET is not set, using a random secret; tokens will not survive a restart")
+			secret = make([]byte, minHMACSecretLength)
+			if _, err := rand.Read(secret); err != nil {
+				return nil, err
+			}
+		}
+		signing, err = NewHMACKey(keyID, secret)
+		if err != nil {
+			return nil, err
+		}
+	case "RS256", "EdDSA":
+		path := os.Getenv("JWT_PRIVATE_KEY_FILE")
+		if path == "" {
+			return nil, fmt.Errorf("JWT_PRIVATE_KEY_FILE is required for %s", alg)
+		}
+		signing, err = loadKeyFile(keyID, path)
+		if err != nil {
+			return nil, err
+		}
+		if signing.Method.Alg() != alg {
+			return nil, fmt.Errorf("JWT_PRIVATE_KEY_FILE holds a %s key, not %s", signing.Method.Alg(), alg)
+		}
+		if !signing.CanSign() {
+			return nil, errors.New("JWT_PRIVATE_KEY_FILE must hold a private key")
+		}
+	default:
+		return nil, fmt.Errorf("unsupported JWT_SIGNING_ALG %q", alg)
+	}
+
+	var verification []*SigningKey
+	for _, entry := range strings.Split(os.Getenv("JWT_VERIFICATION_KEYS"), ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		id, path := "", entry
+		if i := strings.Index(entry, "="); i >= 0 {
+			id, path = entry[:i], entry[i+1:]
+		}
+		key, err := loadKeyFile(id, path)
+		if err != nil {
+			return nil, fmt.Errorf("verification key %s: %w", path, err)
+		}
+		verification = append(verification, key)
+	}
+
+	return NewKeyManager(signing, verification...)
+}
+
+func loadSecret() ([]byte, error) {
+	if secret := os.Getenv("JWT_SECRET"); secret != "" {
+		return []byte(secret), nil
+	}
+	if path := os.Getenv("JWT_SECRET_FILE"); path != "" {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+		return []byte(strings.TrimSpace(string(data))), nil
+	}
+	return nil, nil
+}
+
+// loadKeyFile reads a PEM key, or treats the file as an HS256 secret when it
+// holds no PEM block
+func loadKeyFile(id, path string) (*SigningKey, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	if strings.Contains(string(data), "-----BEGIN") {
+		return ParseKeyPEM(id, data)
+	}
+	return NewHMACKey(id, []byte(strings.TrimSpace(string(data))))
+}
+
+// GenerateSigningKey creates a fresh key for the given algorithm
+func GenerateSigningKey(alg string) (*SigningKey, error) {
+	var private crypto.Signer
+	var err error
+	switch alg {
+	case "HS256":
+		secret := make([]byte, minHMACSecretLength)
+		if _, err := rand.Read(secret); err != nil {
+			return nil, err
+		}
+		return NewHMACKey("", secret)
+	case "RS256":
+		private, err = rsa.GenerateKey(rand.Reader, 2048)
+	case "EdDSA":
+		_, private, err = ed25519.GenerateKey(rand.Reader)
+	default:
+		return nil, fmt.Errorf("unsupported algorithm %q", alg)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	der, err := x509.MarshalPKCS8PrivateKey(private)
+	if err != nil {
+		return nil, err
+	}
+	return ParseKeyPEM("", pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der}))
+}
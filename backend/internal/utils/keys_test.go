@@ -0,0 +1,91 @@
+package utils
+
+import (
+	"crypto/x509"
+	"encoding/pem"
+	"testing"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestKeyManager_SignAndParse(t *testing.T) {
+	for _, alg := range []string{"HS256", "RS256", "EdDSA"} {
+		signing, err := GenerateSigningKey(alg)
+		require.NoError(t, err, alg)
+		km, err := NewKeyManager(signing)
+		require.NoError(t, err, alg)
+
+		tokenString, err := km.Sign(&Claims{Username: "alice"})
+		require.NoError(t, err, alg)
+
+		claims := &Claims{}
+		token, err := km.Parse(tokenString, claims)
+		require.NoError(t, err, alg)
+		assert.Equal(t, alg, token.Method.Alg())
+		assert.Equal(t, signing.ID, token.Header["kid"])
+		assert.Equal(t, "alice", claims.Username)
+	}
+}
+
+func TestKeyManager_Rotation(t *testing.T) {
+	oldKey, _ := GenerateSigningKey("HS256")
+	newKey, _ := GenerateSigningKey("EdDSA")
+
+	oldKM, _ := NewKeyManager(oldKey)
+	oldToken, err := oldKM.Sign(&Claims{})
+	require.NoError(t, err)
+
+	// The old key is still accepted for verification after rotating
+	km, err := NewKeyManager(newKey, oldKey)
+	require.NoError(t, err)
+	_, err = km.Parse(oldToken, &Claims{})
+	assert.NoError(t, err)
+
+	// Once it is dropped, its tokens are rejected
+	km, _ = NewKeyManager(newKey)
+	_, err = km.Parse(oldToken, &Claims{})
+	assert.Error(t, err)
+}
+
+func TestKeyManager_RejectsAlgorithmConfusion(t *testing.T) {
+	signing, _ := GenerateSigningKey("RS256")
+	km, _ := NewKeyManager(signing)
+
+	// An HS256 token "signed" with the published RSA public key must not verify
+	der, err := x509.MarshalPKIXPublicKey(signing.verifyKey)
+	require.NoError(t, err)
+	publicPEM := pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der})
+
+	forged := jwt.NewWithClaims(jwt.SigningMethodHS256, &Claims{IsAdmin: true})
+	forged.Header["kid"] = signing.ID
+	tokenString, err := forged.SignedString(publicPEM)
+	require.NoError(t, err)
+
+	_, err = km.Parse(tokenString, &Claims{})
+	assert.Error(t, err)
+}
+
+func TestKeyManager_JWKS(t *testing.T) {
+	rsaKey, _ := GenerateSigningKey("RS256")
+	edKey, _ := GenerateSigningKey("EdDSA")
+	hmacKey, _ := GenerateSigningKey("HS256")
+
+	km, err := NewKeyManager(rsaKey, edKey, hmacKey)
+	require.NoError(t, err)
+
+	set := km.JWKS()
+	require.Len(t, set.Keys, 2)
+	assert.Equal(t, rsaKey.ID, set.Keys[0].KeyID)
+	assert.Equal(t, "RSA", set.Keys[0].KeyType)
+	assert.Equal(t, "AQAB", set.Keys[0].E)
+	assert.Equal(t, "OKP", set.Keys[1].KeyType)
+	assert.Equal(t, "Ed25519", set.Keys[1].Curve)
+	assert.Equal(t, "EdDSA", set.Keys[1].Algorithm)
+}
+
+func TestNewHMACKey_RejectsShortSecret(t *testing.T) {
+	_, err := NewHMACKey("", []byte("your-secret-key"))
+	assert.Error(t, err)
+}
@@ -19,6 +19,7 @@ type PaginationParams struct {
 type PaginationResponse struct {
 	Data       interface{} `json:"data"`
 	Pagination Pagination  `json:"pagination"`
+	Aggregates interface{} `json:"aggregates,omitempty"`
 }
 
 // Pagination metadata for responses
@@ -7,6 +7,7 @@ import (
 	"task-manager/backend/internal/models"
 	"task-manager/backend/internal/repositories"
 	"task-manager/backend/internal/services"
+	"task-manager/backend/internal/utils"
 	"time"
 
 	"github.com/gin-contrib/cors"
@@ -59,6 +60,33 @@ func main() {
 		&models.Permission{},
 		&models.RolePermission{},
 		&models.Task{},
+		&models.Milestone{},
+		&models.TaskWatcher{},
+		&models.Team{},
+		&models.TeamMember{},
+		&models.TeamRole{},
+		&models.TaskTeamShare{},
+		&models.TaskUserShare{},
+		&models.TaskPublicLink{},
+		&models.Notification{},
+		&models.NotificationPreference{},
+		&models.EmailOutbox{},
+		&models.Webhook{},
+		&models.WebhookDelivery{},
+		&models.OutboxEvent{},
+		&models.ScheduledJob{},
+		&models.Job{},
+		&models.TokenRevocation{},
+		&models.SecurityEvent{},
+		&models.UserIdentity{},
+		&models.OIDCLoginState{},
+		&models.UserMFA{},
+		&models.MFARecoveryCode{},
+		&models.MFAChallenge{},
+		&models.AccountToken{},
+		&models.PasswordHistory{},
+		&models.LoginAttempt{},
+		&models.PersonalAccessToken{},
 	)
 	if err != nil {
 		log.Fatal("Failed to migrate database: ", err)
@@ -76,18 +104,135 @@ func main() {
 		log.Fatal("Failed to initialize cache service: ", err)
 	}
 
+	// Access tokens are signed with the configured key; older keys listed in
+	// JWT_VERIFICATION_KEYS keep verifying while they are rotated out
+	keyManager, err := utils.LoadKeyManager()
+	if err != nil {
+		log.Fatal("Failed to load JWT signing keys: ", err)
+	}
+	utils.SetKeyManager(keyManager)
+
 	// Initialize services
 	authService := services.NewAuthService()
 	registerService := services.NewRegisterService()
 	userService := services.NewUserService()
 	taskService := services.NewTaskService()
+	milestoneService := services.NewMilestoneService()
+	notificationService := services.NewNotificationService()
+	emailService := services.NewEmailService()
+	webhookService := services.NewWebhookService()
+	oidcService := services.NewOIDCService(services.NewOIDCConfig())
+	mfaService := services.NewMFAService()
+	loginGuard := services.NewLoginGuardService()
+	personalAccessTokenService := services.NewPersonalAccessTokenService(authService)
+	userAdminService := services.NewUserAdminService()
+	rbacService := services.NewRBACService(middleware.RequiredPermissions)
+	teamService := services.NewTeamService()
+	authService.UseLoginGuard(loginGuard)
+	accountService := services.NewAccountService(emailService)
+	registerService.UseEmailVerification(accountService)
+
+	passwordPolicy, err := services.NewPasswordPolicy()
+	if err != nil {
+		log.Fatal("Failed to load password policy: ", err)
+	}
+	registerService.UsePasswordPolicy(passwordPolicy)
+	userService.UsePasswordPolicy(passwordPolicy)
+	userService.UseEmailVerification(accountService)
+	userAdminService.UsePasswordPolicy(passwordPolicy)
+	userAdminService.UseEmailVerification(accountService)
+	accountService.UsePasswordPolicy(passwordPolicy)
+	if utils.GetEnv("REQUIRE_EMAIL_VERIFICATION", "false") == "true" {
+		authService.RequireEmailVerification()
+	}
+	// Compact access tokens carry only the user and role IDs; permissions are
+	// always resolved per request, so nothing else is needed to authorize
+	if utils.GetEnv("JWT_COMPACT_CLAIMS", "false") == "true" {
+		authService.UseCompactTokens()
+	}
+
+	// Revoked access tokens are checked in memory; with the database backing
+	// (the default) revocations survive restarts and reach every replica
+	revocationStore := services.NewRevocationStore(utils.GetEnv("TOKEN_REVOCATION_STORE", "database") == "database")
+	if err := revocationStore.Sync(db); err != nil {
+		log.Fatal("Failed to load token revocations: ", err)
+	}
+	authService.UseRevocationStore(revocationStore)
+	userService.UseRevocationStore(revocationStore)
+	userAdminService.UseRevocationStore(revocationStore)
+	rbacService.UseRevocationStore(revocationStore)
+	teamService.UseRevocationStore(revocationStore)
+	accountService.UseRevocationStore(revocationStore)
+
+	// Live task updates are fanned out in-process; swap the broker for a
+	// shared one (e.g. Redis pub/sub) when running more than one instance
+	streamBroker := services.NewInMemoryStreamBroker()
+
+	// Domain events are recorded in the outbox with each change and delivered
+	// to these subscribers in the background
+	eventDispatcher := services.NewEventDispatcher()
+	eventDispatcher.Subscribe("notifications", services.NotificationEventSubscriber(notificationService), services.TaskEventTypes...)
+	eventDispatcher.Subscribe("webhooks", services.WebhookEventSubscriber(webhookService), services.TaskEventTypes...)
+	eventDispatcher.Subscribe("stream", services.StreamEventSubscriber(streamBroker), services.TaskEventTypes...)
+	eventDispatcher.Subscribe("user-cleanup", services.UserCleanupEventSubscriber(), models.DomainEventUserDeleted)
+	taskService.UseEventNotifier(eventDispatcher)
+	userService.UseEventNotifier(eventDispatcher)
+	userAdminService.UseEventNotifier(eventDispatcher)
+	rbacService.UseEventNotifier(eventDispatcher)
+	teamService.UseEventNotifier(eventDispatcher)
+
+	// Permissions are looked up per request rather than trusted from the
+	// access token, and cached until a role change event arrives
+	permissionResolver := services.NewPermissionResolver(db, authService)
+	eventDispatcher.Subscribe("permission-cache", services.PermissionCacheEventSubscriber(permissionResolver), services.PermissionEventTypes...)
+
+	// Recurring maintenance runs on the job queue; high volume queues (emails,
+	// webhooks, outbox events) keep their own dispatch loops below
+	jobRunner := services.NewJobRunner()
+	jobRunner.Register("email-digests", "*/5 * * * *", 3, services.EmailDigestJob(emailService))
+	jobRunner.Register("outbox-cleanup", "30 3 * * *", 3, services.OutboxCleanupJob(7*24*time.Hour))
+	jobRunner.Register("token-cleanup", "0 * * * *", 3, services.TokenCleanupJob(authService, revocationStore))
+	jobRunner.Register("job-history-cleanup", "45 3 * * *", 3, services.JobHistoryCleanupJob(jobRunner, 30*24*time.Hour))
+	jobRunner.Register("account-deletion", "0 * * * *", 3, services.AccountDeletionJob(userService))
+	jobRunner.Register("login-attempt-cleanup", "15 4 * * *", 3, services.LoginAttemptCleanupJob(loginGuard, utils.GetEnvAsDuration("LOGIN_ATTEMPT_RETENTION", 90*24*time.Hour)))
 
 	// Initialize handlers
-	authHandler := handlers.NewAuthHandler(db, authService)
+	authHandler := handlers.NewAuthHandler(db, authService, mfaService)
 	registerHandler := handlers.NewRegisterHandler(db, registerService)
 	userHandler := handlers.NewUserHandler(db, userService)
 	taskHandler := handlers.NewTaskHandler(db, taskService, cacheService)
 	refreshHandler := handlers.NewRefreshHandler(db, authService)
+	sessionHandler := handlers.NewSessionHandler(db, authService)
+	milestoneHandler := handlers.NewMilestoneHandler(db, milestoneService)
+	notificationHandler := handlers.NewNotificationHandler(db, notificationService, emailService)
+	webhookHandler := handlers.NewWebhookHandler(db, webhookService)
+	streamHandler := handlers.NewStreamHandler(db, streamBroker)
+	jobHandler := handlers.NewJobHandler(db, jobRunner)
+	jwksHandler := handlers.NewJWKSHandler(keyManager)
+	oidcHandler := handlers.NewOIDCHandler(db, oidcService, authService, mfaService)
+	mfaHandler := handlers.NewMFAHandler(db, mfaService, authService)
+	accountHandler := handlers.NewAccountHandler(db, accountService)
+	loginGuardHandler := handlers.NewLoginGuardHandler(db, loginGuard)
+	personalAccessTokenHandler := handlers.NewPersonalAccessTokenHandler(db, personalAccessTokenService)
+	userAdminHandler := handlers.NewUserAdminHandler(db, userAdminService)
+	rbacHandler := handlers.NewRBACHandler(db, rbacService)
+	teamHandler := handlers.NewTeamHandler(db, teamService)
+
+	// Deliver queued emails in the background
+	mailSender := services.NewMailSender()
+	go services.RunEmailDispatcher(db, emailService, mailSender, utils.GetEnvAsDuration("EMAIL_DISPATCH_INTERVAL", 30*time.Second))
+
+	// Dispatch outbox events in the background
+	go services.RunEventDispatcher(db, eventDispatcher, utils.GetEnvAsDuration("EVENT_DISPATCH_INTERVAL", 2*time.Second))
+
+	// Pick up revocations made by other replicas
+	go services.RunRevocationSync(db, revocationStore, utils.GetEnvAsDuration("TOKEN_REVOCATION_SYNC_INTERVAL", 5*time.Second))
+
+	// Run scheduled and triggered jobs in the background
+	go jobRunner.Run(db, utils.GetEnvAsDuration("JOB_POLL_INTERVAL", 10*time.Second))
+
+	// Deliver queued webhook events in the background
+	go services.RunWebhookDispatcher(db, webhookService, utils.GetEnvAsDuration("WEBHOOK_DISPATCH_INTERVAL", 5*time.Second))
 
 	// Initialize Gin router
 	r := gin.Default()
@@ -106,7 +251,7 @@ func main() {
 	r.Use(cors.New(cors.Config{
 		AllowOrigins:     []string{"http://localhost:3000", "http://host.docker.internal"},
 		AllowMethods:     []string{"GET", "POST", "PUT", "DELETE", "OPTIONS"},
-		AllowHeaders:     []string{"Origin", "Content-Type", "Authorization"},
+		AllowHeaders:     []string{"Origin", "Content-Type", "Authorization", "Last-Event-ID"},
 		ExposeHeaders:    []string{"Content-Length"},
 		AllowCredentials: true,
 		MaxAge:           12 * time.Hour,
@@ -123,6 +268,18 @@ func main() {
 		})
 	})
 
+	// Public keys for verifying access tokens
+	r.GET("/.well-known/jwks.json", jwksHandler.GetJWKS)
+
+	// Tasks shared through a public link, readable without signing in
+	r.GET("/public/tasks/:token", taskHandler.GetPublicTask)
+
+	authMiddleware := middleware.AuthMiddleware(middleware.AuthMiddlewareConfig{
+		Revocations:          revocationStore,
+		Permissions:          permissionResolver,
+		PersonalAccessTokens: services.NewPersonalAccessTokenAuthenticator(db, personalAccessTokenService),
+	})
+
 	// API routes
 	v1 := r.Group("/api/v1")
 	{
@@ -133,20 +290,157 @@ func main() {
 			authRoutes.POST("/register", registerHandler.Registration)
 			authRoutes.POST("/login", authHandler.Login)
 			authRoutes.POST("/refresh", refreshHandler.Refresh)
+			authRoutes.GET("/oidc/login", oidcHandler.Login)
+			authRoutes.GET("/oidc/callback", oidcHandler.Callback)
+			authRoutes.POST("/oidc/callback", oidcHandler.Callback)
+			authRoutes.POST("/mfa/verify", mfaHandler.Verify)
+			authRoutes.POST("/mfa/enroll", mfaHandler.Enroll)
+			authRoutes.POST("/forgot-password", accountHandler.ForgotPassword)
+			authRoutes.POST("/reset-password", accountHandler.ResetPassword)
+			authRoutes.POST("/verify-email", accountHandler.VerifyEmail)
+		}
+
+		// Logout and session management for the signed in user
+		accountRoutes := v1.Group("/auth")
+		accountRoutes.Use(authMiddleware, middleware.RejectPersonalAccessTokens())
+		{
+			accountRoutes.POST("/logout", sessionHandler.Logout)
+			accountRoutes.GET("/sessions", sessionHandler.GetSessions)
+			accountRoutes.DELETE("/sessions", sessionHandler.RevokeAllSessions)
+			accountRoutes.DELETE("/sessions/:id", sessionHandler.RevokeSession)
+			accountRoutes.GET("/mfa", mfaHandler.GetStatus)
+			accountRoutes.POST("/mfa/setup", mfaHandler.Setup)
+			accountRoutes.POST("/mfa/confirm", mfaHandler.Confirm)
+			accountRoutes.POST("/mfa/recovery-codes", mfaHandler.RegenerateRecoveryCodes)
+			accountRoutes.DELETE("/mfa", mfaHandler.Disable)
+			accountRoutes.POST("/verify-email/resend", accountHandler.ResendVerification)
+			accountRoutes.GET("/logins", loginGuardHandler.GetMyLoginAttempts)
+			accountRoutes.GET("/tokens", personalAccessTokenHandler.GetTokens)
+			accountRoutes.POST("/tokens", personalAccessTokenHandler.CreateToken)
+			accountRoutes.DELETE("/tokens/:id", personalAccessTokenHandler.RevokeToken)
+		}
+
+		// Live task updates (SSE, or WebSocket at /ws)
+		streamRoutes := v1.Group("/stream")
+		streamRoutes.Use(middleware.WebSocketProtocolAuth(), authMiddleware, middleware.RequirePermission("task", "read"))
+		{
+			streamRoutes.GET("", streamHandler.Stream)
+			streamRoutes.GET("/ws", streamHandler.StreamWebSocket)
 		}
 
 		// Protected routes (require authentication)
 		protected := v1.Group("")
-		protected.Use(middleware.AuthMiddleware())
+		protected.Use(authMiddleware)
 		{
 			// Task routes
 			taskRoutes := protected.Group("/tasks")
 			{
 				taskRoutes.POST("", middleware.RequirePermission("task", "create"), taskHandler.CreateTask)
 				taskRoutes.PUT("/:id", middleware.RequirePermission("task", "write"), taskHandler.UpdateTask)
+				taskRoutes.PUT("/:id/remaining-estimate", middleware.RequirePermission("task", "write"), taskHandler.UpdateRemainingEstimate)
 				taskRoutes.DELETE("/:id", middleware.RequirePermission("task", "delete"), taskHandler.DeleteTask)
 				taskRoutes.GET("/:id", middleware.RequirePermission("task", "read"), taskHandler.GetTaskByID)
 				taskRoutes.GET("", middleware.RequirePermission("task", "read"), taskHandler.GetTasks)
+				taskRoutes.GET("/:id/watchers", middleware.RequirePermission("task", "read"), taskHandler.GetTaskWatchers)
+				taskRoutes.POST("/:id/watchers", middleware.RequirePermission("task", "read"), taskHandler.WatchTask)
+				taskRoutes.DELETE("/:id/watchers", middleware.RequirePermission("task", "read"), taskHandler.UnwatchTask)
+				taskRoutes.GET("/:id/teams", middleware.RequirePermission("task", "write"), taskHandler.GetTeamShares)
+				taskRoutes.PUT("/:id/teams/:team_id", middleware.RequirePermission("task", "write"), taskHandler.ShareWithTeam)
+				taskRoutes.DELETE("/:id/teams/:team_id", middleware.RequirePermission("task", "write"), taskHandler.UnshareWithTeam)
+				taskRoutes.GET("/:id/users", middleware.RequirePermission("task", "write"), taskHandler.GetUserShares)
+				taskRoutes.PUT("/:id/users/:user_id", middleware.RequirePermission("task", "write"), taskHandler.ShareWithUser)
+				taskRoutes.DELETE("/:id/users/:user_id", middleware.RequirePermission("task", "write"), taskHandler.UnshareWithUser)
+				taskRoutes.GET("/:id/links", middleware.RequirePermission("task", "write"), taskHandler.GetPublicLinks)
+				taskRoutes.POST("/:id/links", middleware.RequirePermission("task", "write"), taskHandler.CreatePublicLink)
+				taskRoutes.DELETE("/:id/links/:link_id", middleware.RequirePermission("task", "write"), taskHandler.RevokePublicLink)
+			}
+
+			// Notification routes
+			notificationRoutes := protected.Group("/notifications")
+			{
+				notificationRoutes.GET("", middleware.RequirePermission("profile", "read"), notificationHandler.GetNotifications)
+				notificationRoutes.GET("/unread-count", middleware.RequirePermission("profile", "read"), notificationHandler.GetUnreadCount)
+				notificationRoutes.GET("/preferences", middleware.RequirePermission("profile", "read"), notificationHandler.GetPreferences)
+				notificationRoutes.PUT("/preferences", middleware.RequirePermission("profile", "write"), notificationHandler.UpdatePreferences)
+				notificationRoutes.PUT("/read-all", middleware.RequirePermission("profile", "write"), notificationHandler.MarkAllRead)
+				notificationRoutes.PUT("/:id/read", middleware.RequirePermission("profile", "write"), notificationHandler.MarkRead)
+			}
+
+			// Milestone routes
+			milestoneRoutes := protected.Group("/milestones")
+			{
+				milestoneRoutes.GET("", middleware.RequirePermission("task", "read"), milestoneHandler.GetMilestones)
+				milestoneRoutes.GET("/:id", middleware.RequirePermission("task", "read"), milestoneHandler.GetMilestoneByID)
+				milestoneRoutes.GET("/:id/burndown", middleware.RequirePermission("task", "read"), milestoneHandler.GetBurndown)
+
+				// Admin only routes
+				milestoneRoutes.POST("", middleware.RequireAdmin(), milestoneHandler.CreateMilestone)
+				milestoneRoutes.PUT("/:id", middleware.RequireAdmin(), milestoneHandler.UpdateMilestone)
+				milestoneRoutes.DELETE("/:id", middleware.RequireAdmin(), milestoneHandler.DeleteMilestone)
+			}
+
+			// Webhook routes (admin only)
+			webhookRoutes := protected.Group("/webhooks")
+			webhookRoutes.Use(middleware.RequireAdmin())
+			{
+				webhookRoutes.GET("", webhookHandler.GetWebhooks)
+				webhookRoutes.POST("", webhookHandler.CreateWebhook)
+				webhookRoutes.GET("/:id", webhookHandler.GetWebhookByID)
+				webhookRoutes.PUT("/:id", webhookHandler.UpdateWebhook)
+				webhookRoutes.DELETE("/:id", webhookHandler.DeleteWebhook)
+				webhookRoutes.GET("/:id/deliveries", webhookHandler.GetDeliveries)
+				webhookRoutes.POST("/:id/test", webhookHandler.TestWebhook)
+			}
+
+			// Background job routes (admin only)
+			jobRoutes := protected.Group("/jobs")
+			jobRoutes.Use(middleware.RequireAdmin())
+			{
+				jobRoutes.GET("", jobHandler.GetJobs)
+				jobRoutes.GET("/runs", jobHandler.GetJobRuns)
+				jobRoutes.POST("/:name/trigger", jobHandler.TriggerJob)
+				jobRoutes.PUT("/:name/pause", jobHandler.PauseJob)
+				jobRoutes.PUT("/:name/resume", jobHandler.ResumeJob)
+			}
+
+			// Role and permission management (admin only)
+			roleRoutes := protected.Group("/roles")
+			roleRoutes.Use(middleware.RequireAdmin())
+			{
+				roleRoutes.GET("", rbacHandler.GetRoles)
+				roleRoutes.POST("", rbacHandler.CreateRole)
+				roleRoutes.PUT("/:id", rbacHandler.UpdateRole)
+				roleRoutes.DELETE("/:id", rbacHandler.DeleteRole)
+				roleRoutes.GET("/:id/permissions", rbacHandler.GetRolePermissions)
+				roleRoutes.POST("/:id/permissions", rbacHandler.GrantPermission)
+				roleRoutes.DELETE("/:id/permissions/:permission_id", rbacHandler.RevokePermission)
+				roleRoutes.PUT("/:id/mfa", mfaHandler.SetRoleRequirement)
+			}
+
+			// Teams; members can list their own teams and who is in them
+			teamRoutes := protected.Group("/teams")
+			{
+				teamRoutes.GET("", middleware.RequirePermission("profile", "read"), teamHandler.GetTeams)
+				teamRoutes.GET("/:id/members", middleware.RequirePermission("profile", "read"), teamHandler.GetMembers)
+
+				// Admin only routes
+				teamRoutes.POST("", middleware.RequireAdmin(), teamHandler.CreateTeam)
+				teamRoutes.PUT("/:id", middleware.RequireAdmin(), teamHandler.UpdateTeam)
+				teamRoutes.DELETE("/:id", middleware.RequireAdmin(), teamHandler.DeleteTeam)
+				teamRoutes.POST("/:id/members", middleware.RequireAdmin(), teamHandler.AddMember)
+				teamRoutes.DELETE("/:id/members/:user_id", middleware.RequireAdmin(), teamHandler.RemoveMember)
+				teamRoutes.GET("/:id/roles", middleware.RequireAdmin(), teamHandler.GetTeamRoles)
+				teamRoutes.POST("/:id/roles", middleware.RequireAdmin(), teamHandler.GrantRole)
+				teamRoutes.DELETE("/:id/roles/:role_id", middleware.RequireAdmin(), teamHandler.RevokeRole)
+			}
+
+			permissionRoutes := protected.Group("/permissions")
+			permissionRoutes.Use(middleware.RequireAdmin())
+			{
+				permissionRoutes.GET("", rbacHandler.GetPermissions)
+				permissionRoutes.GET("/catalogue", rbacHandler.GetCatalogue)
+				permissionRoutes.POST("", rbacHandler.CreatePermission)
+				permissionRoutes.DELETE("/:id", rbacHandler.DeletePermission)
 			}
 
 			// User routes
@@ -154,15 +448,37 @@ func main() {
 			{
 				userRoutes.GET("/profile", middleware.RequirePermission("profile", "read"), userHandler.GetUserProfile)
 				userRoutes.GET("/profile/:user_id", middleware.RequirePermission("profile", "read"), userHandler.GetUserProfileByUserId)
+
+				// Account changes need a signed in session, not an API token
+				userRoutes.PUT("/profile", middleware.RejectPersonalAccessTokens(), middleware.RequirePermission("profile", "write"), userHandler.UpdateProfile)
+				userRoutes.POST("/profile/password", middleware.RejectPersonalAccessTokens(), middleware.RequirePermission("profile", "write"), userHandler.ChangePassword)
+				userRoutes.DELETE("/profile", middleware.RejectPersonalAccessTokens(), middleware.RequirePermission("profile", "write"), userHandler.DeleteAccount)
+				userRoutes.DELETE("/profile/deletion", middleware.RejectPersonalAccessTokens(), middleware.RequirePermission("profile", "write"), userHandler.CancelAccountDeletion)
 				userRoutes.GET("/:user_id/tasks", middleware.RequirePermission("task", "read"), taskHandler.GetTasksByUser)
 
 				// Admin only routes
 				userRoutes.GET("", middleware.RequireAdmin(), userHandler.GetUsers)
 				userRoutes.DELETE("/:user_id", middleware.RequireAdmin(), userHandler.DeleteUser)
+				userRoutes.DELETE("/:user_id/mfa", middleware.RequireAdmin(), mfaHandler.ResetUserMFA)
+				userRoutes.GET("/:user_id/logins", middleware.RequireAdmin(), loginGuardHandler.GetUserLoginAttempts)
+				userRoutes.POST("/:user_id/unlock", middleware.RequireAdmin(), loginGuardHandler.UnlockUser)
+				userRoutes.POST("", middleware.RequireAdmin(), userAdminHandler.CreateUser)
+				userRoutes.PUT("/:user_id", middleware.RequireAdmin(), userAdminHandler.UpdateUser)
+				userRoutes.POST("/:user_id/disable", middleware.RequireAdmin(), userAdminHandler.DisableUser)
+				userRoutes.POST("/:user_id/enable", middleware.RequireAdmin(), userAdminHandler.EnableUser)
+				userRoutes.POST("/:user_id/password-reset", middleware.RequireAdmin(), userAdminHandler.ForcePasswordReset)
+				userRoutes.GET("/:user_id/roles", middleware.RequireAdmin(), userAdminHandler.GetUserRoles)
+				userRoutes.POST("/:user_id/roles", middleware.RequireAdmin(), userAdminHandler.GrantRole)
+				userRoutes.DELETE("/:user_id/roles/:role_id", middleware.RequireAdmin(), userAdminHandler.RevokeRole)
 			}
 		}
 	}
 
+	// Every permission a route checks has to exist, or no role could pass it
+	if err := services.ValidateRequiredPermissions(db, middleware.RequiredPermissions()); err != nil {
+		log.Fatal("Invalid route permissions: ", err)
+	}
+
 	// Start server
 	log.Println("Starting server on :8080")
 	r.Run(":8080")
@@ -193,6 +509,10 @@ func initializeDatabase(db *gorm.DB) error {
 			{ID: uuid.FromStringOrNil("750e8400-e29b-41d4-a716-446655440003"), Resource: "task", Action: "create"},
 			{ID: uuid.FromStringOrNil("750e8400-e29b-41d4-a716-446655440004"), Resource: "task", Action: "read"},
 			{ID: uuid.FromStringOrNil("750e8400-e29b-41d4-a716-446655440005"), Resource: "task", Action: "write"},
+			{ID: uuid.FromStringOrNil("750e8400-e29b-41d4-a716-446655440006"), Resource: "task", Action: "delete"},
+			{ID: uuid.FromStringOrNil("750e8400-e29b-41d4-a716-446655440007"), Resource: "user", Action: "read"},
+			{ID: uuid.FromStringOrNil("750e8400-e29b-41d4-a716-446655440008"), Resource: "user", Action: "write"},
+			{ID: uuid.FromStringOrNil("750e8400-e29b-41d4-a716-446655440009"), Resource: "user", Action: "delete"},
 		}
 
 		for _, permission := range permissions {
@@ -209,8 +529,13 @@ func initializeDatabase(db *gorm.DB) error {
 			{RoleID: uuid.FromStringOrNil("550e8400-e29b-41d4-a716-446655440002"), PermissionID: uuid.FromStringOrNil("750e8400-e29b-41d4-a716-446655440003")}, // task:create
 			{RoleID: uuid.FromStringOrNil("550e8400-e29b-41d4-a716-446655440002"), PermissionID: uuid.FromStringOrNil("750e8400-e29b-41d4-a716-446655440004")}, // task:read
 			{RoleID: uuid.FromStringOrNil("550e8400-e29b-41d4-a716-446655440002"), PermissionID: uuid.FromStringOrNil("750e8400-e29b-41d4-a716-446655440005")}, // task:write
+			{RoleID: uuid.FromStringOrNil("550e8400-e29b-41d4-a716-446655440002"), PermissionID: uuid.FromStringOrNil("750e8400-e29b-41d4-a716-446655440006")}, // task:delete
+			{RoleID: uuid.FromStringOrNil("550e8400-e29b-41d4-a716-446655440002"), PermissionID: uuid.FromStringOrNil("750e8400-e29b-41d4-a716-446655440007")}, // user:read
+			{RoleID: uuid.FromStringOrNil("550e8400-e29b-41d4-a716-446655440002"), PermissionID: uuid.FromStringOrNil("750e8400-e29b-41d4-a716-446655440008")}, // user:write
+			{RoleID: uuid.FromStringOrNil("550e8400-e29b-41d4-a716-446655440002"), PermissionID: uuid.FromStringOrNil("750e8400-e29b-41d4-a716-446655440009")}, // user:delete
 			// User permissions
 			{RoleID: uuid.FromStringOrNil("550e8400-e29b-41d4-a716-446655440001"), PermissionID: uuid.FromStringOrNil("750e8400-e29b-41d4-a716-446655440001")}, // profile:read
+			{RoleID: uuid.FromStringOrNil("550e8400-e29b-41d4-a716-446655440001"), PermissionID: uuid.FromStringOrNil("750e8400-e29b-41d4-a716-446655440002")}, // profile:write
 			{RoleID: uuid.FromStringOrNil("550e8400-e29b-41d4-a716-446655440001"), PermissionID: uuid.FromStringOrNil("750e8400-e29b-41d4-a716-446655440003")}, // task:create
 			{RoleID: uuid.FromStringOrNil("550e8400-e29b-41d4-a716-446655440001"), PermissionID: uuid.FromStringOrNil("750e8400-e29b-41d4-a716-446655440004")}, // task:read
 			{RoleID: uuid.FromStringOrNil("550e8400-e29b-41d4-a716-446655440001"), PermissionID: uuid.FromStringOrNil("750e8400-e29b-41d4-a716-446655440005")}, // task:write
@@ -223,11 +548,13 @@ func initializeDatabase(db *gorm.DB) error {
 		}
 
 		// Create admin user
+		verifiedAt := time.Now()
 		adminUser := models.User{
-			ID:       uuid.FromStringOrNil("bd006d41-aded-4040-9934-2ba4e909ef9a"),
-			Username: "admin",
-			Email:    "admin@gmail.com",
-			Password: "$2a$10$ZsE5IA/WUP2CKVd0rND/rum3DKp6lLfjTGSHAmqONb9eGCLY4GYD6", // admin123
+			ID:              uuid.FromStringOrNil("bd006d41-aded-4040-9934-2ba4e909ef9a"),
+			Username:        "admin",
+			Email:           "admin@gmail.com",
+			Password:        "$2a$10$ZsE5IA/WUP2CKVd0rND/rum3DKp6lLfjTGSHAmqONb9eGCLY4GYD6", // admin123
+			EmailVerifiedAt: &verifiedAt,
 		}
 
 		if err := db.Create(&adminUser).Error; err != nil {
@@ -247,5 +574,24 @@ func initializeDatabase(db *gorm.DB) error {
 		log.Println("Database initialized with default data")
 	}
 
+	// Databases seeded before DELETE /tasks/:id checked task:delete lack it
+	var taskDelete int64
+	if err := db.Model(&models.Permission{}).Where("resource = ? AND action = ?", "task", "delete").Count(&taskDelete).Error; err != nil {
+		return err
+	}
+	if taskDelete == 0 {
+		permission := models.Permission{ID: uuid.Must(uuid.NewV4()), Resource: "task", Action: "delete"}
+		if err := db.Create(&permission).Error; err != nil {
+			return err
+		}
+		adminPermission := models.RolePermission{
+			RoleID:       uuid.FromStringOrNil("550e8400-e29b-41d4-a716-446655440002"), // admin role
+			PermissionID: permission.ID,
+		}
+		if err := db.Create(&adminPermission).Error; err != nil {
+			return err
+		}
+	}
+
 	return nil
 }
@@ -0,0 +1,4 @@
+ALTER TABLE tasks
+    DROP COLUMN IF EXISTS story_points,
+    DROP COLUMN IF EXISTS remaining_estimate,
+    DROP COLUMN IF EXISTS original_estimate;
@@ -0,0 +1,8 @@
+DROP INDEX IF EXISTS idx_milestones_start_date;
+DROP INDEX IF EXISTS idx_tasks_milestone_id;
+
+ALTER TABLE tasks
+    DROP COLUMN IF EXISTS completed_at,
+    DROP COLUMN IF EXISTS milestone_id;
+
+DROP TABLE IF EXISTS milestones;
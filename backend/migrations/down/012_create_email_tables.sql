@@ -0,0 +1,4 @@
+ALTER TABLE notifications DROP COLUMN IF EXISTS email_pending;
+
+DROP TABLE IF EXISTS email_outboxes;
+DROP TABLE IF EXISTS notification_preferences;
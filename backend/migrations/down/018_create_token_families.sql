@@ -0,0 +1,16 @@
+DROP TABLE IF EXISTS security_events;
+
+-- Hashed tokens cannot be turned back into raw ones, so every session ends
+DELETE FROM tokens;
+
+DROP INDEX IF EXISTS idx_tokens_family_id;
+DROP INDEX IF EXISTS idx_tokens_token_hash;
+
+ALTER TABLE tokens
+    DROP COLUMN IF EXISTS used_at,
+    DROP COLUMN IF EXISTS token_hash,
+    DROP COLUMN IF EXISTS parent_id,
+    DROP COLUMN IF EXISTS family_id,
+    ADD COLUMN refresh_token UUID NOT NULL;
+
+CREATE INDEX IF NOT EXISTS idx_tokens_refresh_token ON tokens(refresh_token);
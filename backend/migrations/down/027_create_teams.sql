@@ -0,0 +1,4 @@
+DROP TABLE IF EXISTS task_team_shares;
+DROP TABLE IF EXISTS team_roles;
+DROP TABLE IF EXISTS team_members;
+DROP TABLE IF EXISTS teams;
@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS task_public_links;
+DROP TABLE IF EXISTS task_user_shares;
@@ -0,0 +1,4 @@
+ALTER TABLE tasks
+    ADD COLUMN original_estimate NUMERIC(10, 2) NULL,
+    ADD COLUMN remaining_estimate NUMERIC(10, 2) NULL,
+    ADD COLUMN story_points INTEGER NULL;
@@ -0,0 +1,18 @@
+CREATE TABLE milestones (
+    id UUID NOT NULL PRIMARY KEY,
+    name VARCHAR(255) NOT NULL,
+    goal TEXT,
+    start_date TIMESTAMPTZ NOT NULL,
+    end_date TIMESTAMPTZ NOT NULL,
+    created_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    deleted_at TIMESTAMPTZ NULL
+);
+
+ALTER TABLE tasks
+    ADD COLUMN milestone_id UUID NULL REFERENCES milestones(id) ON DELETE SET NULL,
+    ADD COLUMN completed_at TIMESTAMPTZ NULL;
+
+CREATE INDEX IF NOT EXISTS idx_tasks_milestone_id ON tasks(milestone_id);
+CREATE INDEX IF NOT EXISTS idx_milestones_start_date ON milestones(start_date);
@@ -0,0 +1,5 @@
+ALTER TABLE tokens
+    ADD COLUMN device VARCHAR(100) NULL,
+    ADD COLUMN user_agent TEXT NULL,
+    ADD COLUMN ip_address VARCHAR(45) NULL,
+    ADD COLUMN last_used_at TIMESTAMPTZ NULL;
@@ -0,0 +1,12 @@
+CREATE TABLE token_revocations (
+    id UUID NOT NULL PRIMARY KEY,
+    kind VARCHAR(20) NOT NULL,
+    subject VARCHAR(64) NOT NULL,
+    revoked_at TIMESTAMPTZ NOT NULL,
+    expires_at TIMESTAMPTZ NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_token_revocations_subject ON token_revocations(subject);
+CREATE INDEX IF NOT EXISTS idx_token_revocations_expires_at ON token_revocations(expires_at);
+CREATE INDEX IF NOT EXISTS idx_token_revocations_created_at ON token_revocations(created_at);
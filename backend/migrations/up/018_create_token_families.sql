@@ -0,0 +1,30 @@
+ALTER TABLE tokens
+    ADD COLUMN family_id UUID NULL,
+    ADD COLUMN parent_id UUID NULL REFERENCES tokens(id) ON DELETE SET NULL,
+    ADD COLUMN token_hash VARCHAR(64) NULL,
+    ADD COLUMN used_at TIMESTAMPTZ NULL;
+
+-- Existing tokens become single token families and keep working: clients
+-- still present the raw UUID, which hashes to the stored value
+UPDATE tokens SET family_id = id, token_hash = encode(sha256(refresh_token::text::bytea), 'hex');
+
+ALTER TABLE tokens
+    ALTER COLUMN family_id SET NOT NULL,
+    ALTER COLUMN token_hash SET NOT NULL,
+    DROP COLUMN refresh_token;
+
+DROP INDEX IF EXISTS idx_tokens_refresh_token;
+CREATE UNIQUE INDEX IF NOT EXISTS idx_tokens_token_hash ON tokens(token_hash);
+CREATE INDEX IF NOT EXISTS idx_tokens_family_id ON tokens(family_id);
+
+CREATE TABLE security_events (
+    id UUID NOT NULL PRIMARY KEY,
+    user_id UUID REFERENCES users(id) ON DELETE CASCADE,
+    type VARCHAR(50) NOT NULL,
+    ip_address VARCHAR(45),
+    user_agent TEXT,
+    details TEXT,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_security_events_user_id ON security_events(user_id, created_at DESC);
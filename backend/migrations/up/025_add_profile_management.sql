@@ -0,0 +1,12 @@
+ALTER TABLE users ADD COLUMN display_name VARCHAR(100) NOT NULL DEFAULT '';
+ALTER TABLE users ADD COLUMN deletion_scheduled_at TIMESTAMPTZ NULL;
+
+CREATE INDEX IF NOT EXISTS idx_users_deletion_scheduled_at ON users(deletion_scheduled_at);
+
+-- Users can edit their own profile
+INSERT INTO role_permissions(id, role_id, permission_id)
+SELECT '850e8400-e29b-41d4-a716-446655440002', '550e8400-e29b-41d4-a716-446655440001', '750e8400-e29b-41d4-a716-446655440002'
+WHERE NOT EXISTS (
+    SELECT 1 FROM role_permissions
+    WHERE role_id = '550e8400-e29b-41d4-a716-446655440001' AND permission_id = '750e8400-e29b-41d4-a716-446655440002'
+);
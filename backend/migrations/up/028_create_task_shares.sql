@@ -0,0 +1,26 @@
+CREATE TABLE task_user_shares (
+    id UUID NOT NULL PRIMARY KEY,
+    task_id UUID NOT NULL REFERENCES tasks(id) ON DELETE CASCADE,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    access VARCHAR(10) NOT NULL CHECK (access IN ('read', 'write')),
+    shared_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_task_user_shares_task_user ON task_user_shares(task_id, user_id);
+CREATE INDEX IF NOT EXISTS idx_task_user_shares_user_id ON task_user_shares(user_id);
+
+CREATE TABLE task_public_links (
+    id UUID NOT NULL PRIMARY KEY,
+    task_id UUID NOT NULL REFERENCES tasks(id) ON DELETE CASCADE,
+    token_hash VARCHAR(64) NOT NULL,
+    hint VARCHAR(16) NOT NULL,
+    expires_at TIMESTAMPTZ NULL,
+    created_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    last_used_at TIMESTAMPTZ NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_task_public_links_token_hash ON task_public_links(token_hash);
+CREATE INDEX IF NOT EXISTS idx_task_public_links_task_id ON task_public_links(task_id);
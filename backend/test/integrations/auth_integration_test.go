@@ -5,6 +5,7 @@ import (
 	"encoding/json"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
 	"task-manager/backend/internal/handlers"
 	"task-manager/backend/internal/models"
@@ -26,15 +27,23 @@ func setupTestRouter() (*gin.Engine, *gorm.DB) {
 		panic("failed to connect database")
 	}
 
+	// SQLite has no gen_random_uuid(), so generate IDs from random bytes
+	db.Callback().Raw().Before("gorm:raw").Register("sqlite_uuid_default", func(tx *gorm.DB) {
+		sql := strings.ReplaceAll(tx.Statement.SQL.String(), "DEFAULT gen_random_uuid()", "DEFAULT (lower(hex(randomblob(16))))")
+		tx.Statement.SQL.Reset()
+		tx.Statement.SQL.WriteString(sql)
+	})
+
 	// Auto-migrate
-	db.AutoMigrate(&models.User{}, &models.Token{}, &models.Role{}, &models.UserRole{}, &models.Permission{}, &models.RolePermission{})
+	db.AutoMigrate(&models.User{}, &models.Token{}, &models.Role{}, &models.UserRole{}, &models.Permission{}, &models.RolePermission{},
+		&models.TeamMember{}, &models.TeamRole{}, &models.UserMFA{})
 
 	// Initialize services
 	authService := services.NewAuthService()
 	registerService := services.NewRegisterService()
 
 	// Initialize handlers
-	authHandler := handlers.NewAuthHandler(db, authService)
+	authHandler := handlers.NewAuthHandler(db, authService, services.NewMFAService())
 	registerHandler := handlers.NewRegisterHandler(db, registerService)
 
 	// Setup router
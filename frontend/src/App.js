@@ -5,6 +5,9 @@ import ProfilePage from './pages/ProfilePage';
 import TasksPage from './pages/TasksPage';
 import AdminPanel from './pages/AdminPanel';
 import LogoutPage from './pages/LogoutPage';
+import SSOCallbackPage from './pages/SSOCallbackPage';
+import ResetPasswordPage from './pages/ResetPasswordPage';
+import VerifyEmailPage from './pages/VerifyEmailPage';
 import WithNavBar from './components/WithNavBar';
 import Unauthorized from './pages/Unauthorized';
 import React from 'react';
@@ -18,6 +21,9 @@ function App() {
       <Routes>
         <Route path="/" element={<LoginPage />} />
         <Route path="/register" element={<RegistrationPage />} />
+        <Route path="/auth/callback" element={<SSOCallbackPage />} />
+        <Route path="/reset-password" element={<ResetPasswordPage />} />
+        <Route path="/verify-email" element={<VerifyEmailPage />} />
         <Route
           path="/profile"
           element={
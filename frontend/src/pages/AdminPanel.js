@@ -1,6 +1,7 @@
 import React, { useState, useEffect } from 'react';
 import { Button, Container, Grid, Typography, Box, Card, CardContent } from '@mui/material';
 import api from '../services/api';
+import { describeError } from '../services/auth';
 
 const AdminPanel = () => {
   const [users, setUsers] = useState([]);
@@ -10,7 +11,7 @@ const AdminPanel = () => {
     const fetchAdminData = async () => {
       try {
         const usersResponse = await api.get('/users');
-        setUsers(usersResponse.data);
+        setUsers(usersResponse.data.users || []);
 
         const tasksResponse = await api.get('/tasks');
         setTasks(tasksResponse.data);
@@ -30,6 +31,45 @@ const AdminPanel = () => {
     }
   };
 
+  const replaceUser = (updated) => {
+    setUsers(users.map((user) => (user.id === updated.id ? updated : user)));
+  };
+
+  const handleCreateUser = async () => {
+    const username = window.prompt('Username');
+    if (!username) return;
+    const email = window.prompt('Email address');
+    if (!email) return;
+
+    try {
+      // Without a password the user is emailed a link to choose one
+      const response = await api.post('/users', { username, email });
+      setUsers([...users, response.data.user]);
+    } catch (error) {
+      alert('Creating the user failed: ' + describeError(error));
+    }
+  };
+
+  const handleToggleDisabled = async (user) => {
+    try {
+      await api.post(`/users/${user.id}/${user.disabled_at ? 'enable' : 'disable'}`);
+      replaceUser({ ...user, disabled_at: user.disabled_at ? null : new Date().toISOString() });
+    } catch (error) {
+      alert('Request failed: ' + describeError(error));
+    }
+  };
+
+  const handleForcePasswordReset = async (user) => {
+    if (!window.confirm(`Sign ${user.username} out and require a new password?`)) return;
+
+    try {
+      await api.post(`/users/${user.id}/password-reset`);
+      replaceUser({ ...user, password_reset_required: true });
+    } catch (error) {
+      alert('Request failed: ' + describeError(error));
+    }
+  };
+
   return (
     <Container sx={{ marginTop: 4 }}>
       {/* Page Header */}
@@ -47,6 +87,9 @@ const AdminPanel = () => {
           <Typography variant="h5" gutterBottom sx={{ fontWeight: 'bold' }}>
             Users
           </Typography>
+          <Button variant="contained" onClick={handleCreateUser} sx={{ marginBottom: 2 }}>
+            Create User
+          </Button>
           <Grid container spacing={3}>
             {users.length > 0 ? (
               users.map((user) => (
@@ -62,6 +105,26 @@ const AdminPanel = () => {
                     <Typography variant="body1" sx={{ fontWeight: 'bold' }}>
                       {user.username}
                     </Typography>
+                    {user.disabled_at && (
+                      <Typography variant="body2" color="error">Disabled</Typography>
+                    )}
+                    {user.password_reset_required && (
+                      <Typography variant="body2" color="text.secondary">Password reset pending</Typography>
+                    )}
+                    <Button
+                      variant="outlined"
+                      onClick={() => handleToggleDisabled(user)}
+                      sx={{ marginTop: 1, marginRight: 1 }}
+                    >
+                      {user.disabled_at ? 'Enable' : 'Disable'}
+                    </Button>
+                    <Button
+                      variant="outlined"
+                      onClick={() => handleForcePasswordReset(user)}
+                      sx={{ marginTop: 1, marginRight: 1 }}
+                    >
+                      Reset Password
+                    </Button>
                     <Button
                       variant="outlined"
                       color="secondary"
@@ -2,8 +2,8 @@ import React from 'react';
 import { useForm } from 'react-hook-form';
 import { TextField, Button, Grid, Container, Typography, Box } from '@mui/material';
 import { useNavigate } from 'react-router-dom';
-import { jwtDecode } from 'jwt-decode';
 import api from '../services/api';
+import { startSession, startSingleSignOn } from '../services/auth';
 import { useUser } from '../context/UserContext'; 
 
 
@@ -13,34 +13,48 @@ const LoginPage = () => {
   const { login } = useUser(); 
 
 
+  // Logins with 2FA answer with a challenge token that is exchanged for the
+  // real tokens together with a code from the authenticator app
+  const completeTwoFactor = async ({ mfa_token, mfa_enrollment_required }) => {
+    if (mfa_enrollment_required) {
+      const enrollment = await api.post('/auth/mfa/enroll', { mfa_token });
+      alert(
+        'Your role requires two-factor authentication. Add this account to your authenticator app:\n\n' +
+        enrollment.data.provisioning_uri + '\n\nSecret: ' + enrollment.data.secret
+      );
+    }
+
+    const code = window.prompt('Enter the code from your authenticator app (or a recovery code)');
+    if (!code) return null;
+
+    const response = await api.post('/auth/mfa/verify', { mfa_token, code });
+    if (response.data.recovery_codes) {
+      alert('Save these recovery codes somewhere safe. Each can be used once:\n\n' + response.data.recovery_codes.join('\n'));
+    }
+    return response;
+  };
+
+  const forgotPassword = async () => {
+    const email = window.prompt('Enter the email address of your account');
+    if (!email) return;
+
+    try {
+      const response = await api.post('/auth/forgot-password', { email });
+      alert(response.data.message);
+    } catch (error) {
+      console.error('Password reset request failed', error);
+      alert('Password reset request failed: ' + (error.response?.data?.error || error.message));
+    }
+  };
+
   const onSubmit = async (data) => {
     try {
-      const response = await api.post('/auth/login', data);
-      if (response.data.access_token) {
-        localStorage.setItem('access_token', response.data.access_token);
-        
-        const userResponse = await api.get('/users/profile');
-        // Fix: Extract user data from the correct response structure
-        const userData = userResponse.data.user;
-        
-        // Decode JWT to get user info
-        const decoded = jwtDecode(response.data.access_token);
-        
-        // Create combined user object with both JWT and profile data
-        const combinedUser = {
-          id: userData.id,
-          user_id: decoded.user_id,
-          username: decoded.username,
-          email: userData.email,
-          roles: decoded.roles,
-          is_admin: decoded.is_admin,
-          permissions: decoded.permissions,
-          created_at: userData.created_at,
-          updated_at: userData.updated_at
-        };
-        
-        login(combinedUser); 
-        localStorage.setItem('user', JSON.stringify(combinedUser));
+      let response = await api.post('/auth/login', data);
+      if (response.data.mfa_required) {
+        response = await completeTwoFactor(response.data);
+      }
+      if (response?.data.access_token) {
+        await startSession(response.data.access_token, login);
 
         navigate('/tasks');
       }
@@ -126,6 +140,23 @@ const LoginPage = () => {
               </Grid>
             </Grid>
           </form>
+          <Button
+            variant="outlined"
+            color="primary"
+            fullWidth
+            onClick={startSingleSignOn}
+            sx={{ marginTop: 2, fontWeight: 'bold' }}
+          >
+            Sign in with SSO
+          </Button>
+          <Button
+            variant="text"
+            color="secondary"
+            onClick={forgotPassword}
+            sx={{ marginTop: 1 }}
+          >
+            Forgot password?
+          </Button>
         </Box>
       </Container>
     </Box>
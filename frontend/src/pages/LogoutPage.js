@@ -1,6 +1,7 @@
 import React, { useEffect } from 'react';
 import { useNavigate } from 'react-router-dom';
 import { useUser } from '../context/UserContext';
+import api from '../services/api';
 import { Box, Typography, CircularProgress } from '@mui/material';
 
 const LogoutPage = () => {
@@ -8,9 +9,18 @@ const LogoutPage = () => {
     const navigate = useNavigate();
   
     useEffect(() => {
-      localStorage.removeItem('access_token');
-      setUser(null); 
-      navigate('/');
+      const logout = async () => {
+        try {
+          // End the session on the server so the tokens stop working
+          await api.post('/auth/logout');
+        } catch (error) {
+          console.error('Error logging out', error);
+        }
+        localStorage.removeItem('access_token');
+        setUser(null); 
+        navigate('/');
+      };
+      logout();
     }, [setUser, navigate]);
 
   return (
@@ -1,6 +1,7 @@
 import React, { useEffect, useState } from 'react';
 import { useUser } from '../context/UserContext';
 import api from '../services/api';
+import { describeError } from '../services/auth';
 import { Button, Typography, Box, Container, Card, CardContent } from '@mui/material';
 
 const ProfilePage = () => {
@@ -21,6 +22,54 @@ const ProfilePage = () => {
     fetchProfile();
   }, [user]);
 
+  const editProfile = async () => {
+    const display_name = window.prompt('Display name', profileData.display_name || '');
+    if (display_name === null) return;
+    const email = window.prompt('Email address', profileData.email);
+    if (email === null) return;
+
+    try {
+      const res = await api.put('/users/profile', { display_name, email });
+      setProfileData(res.data.user);
+      if (email !== profileData.email) {
+        alert('Check your inbox to verify the new address');
+      }
+    } catch (err) {
+      alert('Profile update failed: ' + describeError(err));
+    }
+  };
+
+  const changePassword = async () => {
+    const current_password = window.prompt('Current password');
+    if (!current_password) return;
+    const new_password = window.prompt('New password');
+    if (!new_password) return;
+
+    try {
+      const res = await api.post('/users/profile/password', { current_password, new_password });
+      alert(res.data.message);
+    } catch (err) {
+      alert('Password change failed: ' + describeError(err));
+    }
+  };
+
+  const toggleDeletion = async () => {
+    try {
+      if (profileData.deletion_scheduled_at) {
+        await api.delete('/users/profile/deletion');
+        setProfileData({ ...profileData, deletion_scheduled_at: null });
+        return;
+      }
+
+      const password = window.prompt('Enter your password to delete your account');
+      if (password === null) return;
+      const res = await api.delete('/users/profile', { data: { password } });
+      setProfileData({ ...profileData, deletion_scheduled_at: res.data.deletion_scheduled_at });
+    } catch (err) {
+      alert('Request failed: ' + describeError(err));
+    }
+  };
+
   if (error)
     return (
       <Box
@@ -82,6 +131,9 @@ const ProfilePage = () => {
               <Typography variant="body1" gutterBottom>
                 <strong>Username:</strong> {profileData?.username || 'N/A'}
               </Typography>
+              <Typography variant="body1" gutterBottom>
+                <strong>Display Name:</strong> {profileData?.display_name || 'N/A'}
+              </Typography>
               <Typography variant="body1" gutterBottom>
                 <strong>Email:</strong> {profileData?.email || 'N/A'}
               </Typography>
@@ -89,6 +141,19 @@ const ProfilePage = () => {
                 <strong>Joined At:</strong>{" "}
                 {profileData?.created_at ? new Date(profileData.created_at).toLocaleDateString() : 'Invalid Date'}
               </Typography>
+              {profileData?.deletion_scheduled_at && (
+                <Typography variant="body1" color="error" gutterBottom>
+                  This account will be deleted on {new Date(profileData.deletion_scheduled_at).toLocaleString()}
+                </Typography>
+              )}
+            </Box>
+
+            <Box sx={{ display: 'flex', gap: 1, justifyContent: 'center', marginTop: 3 }}>
+              <Button variant="outlined" onClick={editProfile}>Edit Profile</Button>
+              <Button variant="outlined" onClick={changePassword}>Change Password</Button>
+              <Button variant="outlined" color="error" onClick={toggleDeletion}>
+                {profileData?.deletion_scheduled_at ? 'Cancel Deletion' : 'Delete Account'}
+              </Button>
             </Box>
 
             {/* Manager Access Button */}
@@ -3,6 +3,7 @@ import { useForm } from 'react-hook-form';
 import { TextField, Button, Typography, Box, Container } from '@mui/material';
 import { useNavigate } from 'react-router-dom';
 import api from '../services/api';
+import { describeError } from '../services/auth';
 
 const RegistrationPage = () => {
   const { register, handleSubmit, formState: { errors } } = useForm();
@@ -20,7 +21,7 @@ const RegistrationPage = () => {
     } catch (error) {
       console.error('Error registering', error);
       console.error('Error response:', error.response?.data); // Debug log
-      alert(`Registration failed: ${error.response?.data?.error || error.message}`);
+      alert(`Registration failed: ${describeError(error)}`);
     }
   };
 
@@ -88,8 +89,8 @@ const RegistrationPage = () => {
               {...register("password", { 
                 required: "Password is required",
                 minLength: {
-                  value: 6,
-                  message: "Password must be at least 6 characters"
+                  value: 8,
+                  message: "Password must be at least 8 characters"
                 }
               })}
               error={!!errors.password}
@@ -0,0 +1,101 @@
+import React from 'react';
+import { useForm } from 'react-hook-form';
+import { TextField, Button, Container, Typography, Box } from '@mui/material';
+import { useNavigate, useSearchParams } from 'react-router-dom';
+import api from '../services/api';
+import { describeError } from '../services/auth';
+
+const ResetPasswordPage = () => {
+  const { register, handleSubmit, watch, formState: { errors } } = useForm();
+  const [searchParams] = useSearchParams();
+  const navigate = useNavigate();
+
+  const onSubmit = async (data) => {
+    try {
+      await api.post('/auth/reset-password', {
+        token: searchParams.get('token'),
+        password: data.password,
+      });
+      alert('Your password has been reset. Please log in with the new password.');
+      navigate('/');
+    } catch (error) {
+      console.error('Password reset failed', error);
+      alert('Password reset failed: ' + describeError(error));
+    }
+  };
+
+  return (
+    <Box
+      sx={{
+        display: 'flex',
+        justifyContent: 'center',
+        alignItems: 'center',
+        height: '100vh',
+        backgroundColor: '#f0f4f8',
+      }}
+    >
+      <Container maxWidth="xs">
+        <Box
+          sx={{
+            display: 'flex',
+            flexDirection: 'column',
+            alignItems: 'center',
+            padding: 4,
+            borderRadius: 2,
+            boxShadow: 3,
+            backgroundColor: '#ffffff',
+          }}
+        >
+          <Typography
+            component="h1"
+            variant="h4"
+            gutterBottom
+            sx={{ color: '#1976d2', fontWeight: 'bold' }}
+          >
+            Reset Password
+          </Typography>
+          <form onSubmit={handleSubmit(onSubmit)} style={{ width: '100%' }}>
+            <TextField
+              label="New password"
+              type="password"
+              {...register('password', {
+                required: 'Password is required',
+                minLength: { value: 8, message: 'Password must be at least 8 characters' },
+              })}
+              error={!!errors.password}
+              helperText={errors.password?.message}
+              variant="outlined"
+              margin="normal"
+              fullWidth
+              sx={{ backgroundColor: '#f9f9f9' }}
+            />
+            <TextField
+              label="Confirm new password"
+              type="password"
+              {...register('confirmPassword', {
+                validate: (value) => value === watch('password') || 'Passwords do not match',
+              })}
+              error={!!errors.confirmPassword}
+              helperText={errors.confirmPassword?.message}
+              variant="outlined"
+              margin="normal"
+              fullWidth
+              sx={{ backgroundColor: '#f9f9f9' }}
+            />
+            <Button
+              type="submit"
+              variant="contained"
+              color="primary"
+              fullWidth
+              sx={{ marginTop: 2, fontWeight: 'bold' }}
+            >
+              Set new password
+            </Button>
+          </form>
+        </Box>
+      </Container>
+    </Box>
+  );
+};
+
+export default ResetPasswordPage;
@@ -0,0 +1,52 @@
+import React, { useEffect, useRef } from 'react';
+import { useNavigate, useSearchParams } from 'react-router-dom';
+import { useUser } from '../context/UserContext';
+import api from '../services/api';
+import { startSession } from '../services/auth';
+import { Box, Typography, CircularProgress } from '@mui/material';
+
+const SSOCallbackPage = () => {
+  const [searchParams] = useSearchParams();
+  const { login } = useUser();
+  const navigate = useNavigate();
+  const completed = useRef(false);
+
+  useEffect(() => {
+    // The code can only be redeemed once, so guard against double effects
+    if (completed.current) return;
+    completed.current = true;
+
+    const completeLogin = async () => {
+      try {
+        const response = await api.post('/auth/oidc/callback', Object.fromEntries(searchParams));
+        await startSession(response.data.access_token, login);
+        navigate('/tasks');
+      } catch (error) {
+        console.error('Single sign-on failed', error);
+        alert('Single sign-on failed: ' + (error.response?.data?.error || error.message));
+        navigate('/');
+      }
+    };
+    completeLogin();
+  }, [searchParams, login, navigate]);
+
+  return (
+    <Box
+      sx={{
+        display: 'flex',
+        flexDirection: 'column',
+        justifyContent: 'center',
+        alignItems: 'center',
+        height: '100vh',
+        backgroundColor: '#f0f4f8',
+      }}
+    >
+      <CircularProgress sx={{ marginBottom: 2 }} />
+      <Typography variant="h6" color="textSecondary">
+        Signing you in...
+      </Typography>
+    </Box>
+  );
+};
+
+export default SSOCallbackPage;
@@ -14,6 +14,7 @@ import { AdapterDayjs } from '@mui/x-date-pickers/AdapterDayjs';
 import dayjs from 'dayjs'; 
 import { useUser } from '../context/UserContext';
 import api from '../services/api';
+import { subscribeToTaskStream } from '../services/taskStream';
 import TaskCard from '../components/TaskCard';
 
 const TasksPage = () => {
@@ -46,6 +47,28 @@ const TasksPage = () => {
     fetchData();
   }, [user]);
 
+  // Apply task changes made elsewhere without reloading the page
+  useEffect(() => {
+    if (!user?.id) return undefined;
+
+    return subscribeToTaskStream((event) => {
+      if (event.type === 'stream.reset') {
+        api.get(`/users/${user.id}/tasks`).then((response) => setTasks(response.data.data || []));
+        return;
+      }
+
+      const { task } = event;
+      if (task.user_id !== user.id) return;
+
+      setTasks((prev) => {
+        const others = prev.filter((t) => t.id !== task.id);
+        if (event.type === 'task.deleted') return others;
+        if (others.length === prev.length) return [...prev, task];
+        return prev.map((t) => (t.id === task.id ? task : t));
+      });
+    });
+  }, [user]);
+
   
   const handleCreateTask = async () => {
     if (!user) {  
@@ -0,0 +1,48 @@
+import React, { useEffect, useRef } from 'react';
+import { useNavigate, useSearchParams } from 'react-router-dom';
+import api from '../services/api';
+import { Box, Typography, CircularProgress } from '@mui/material';
+
+const VerifyEmailPage = () => {
+  const [searchParams] = useSearchParams();
+  const navigate = useNavigate();
+  const completed = useRef(false);
+
+  useEffect(() => {
+    // The link only works once, so guard against double effects
+    if (completed.current) return;
+    completed.current = true;
+
+    const verify = async () => {
+      try {
+        await api.post('/auth/verify-email', { token: searchParams.get('token') });
+        alert('Your email address is confirmed. You can log in now.');
+      } catch (error) {
+        console.error('Email verification failed', error);
+        alert('Email verification failed: ' + (error.response?.data?.error || error.message));
+      }
+      navigate('/');
+    };
+    verify();
+  }, [searchParams, navigate]);
+
+  return (
+    <Box
+      sx={{
+        display: 'flex',
+        flexDirection: 'column',
+        justifyContent: 'center',
+        alignItems: 'center',
+        height: '100vh',
+        backgroundColor: '#f0f4f8',
+      }}
+    >
+      <CircularProgress sx={{ marginBottom: 2 }} />
+      <Typography variant="h6" color="textSecondary">
+        Confirming your email address...
+      </Typography>
+    </Box>
+  );
+};
+
+export default VerifyEmailPage;
@@ -0,0 +1,44 @@
+import { jwtDecode } from 'jwt-decode';
+import api from './api';
+
+// Stores the access token from a successful login and loads the signed in user
+export const startSession = async (accessToken, login) => {
+  localStorage.setItem('access_token', accessToken);
+
+  const userResponse = await api.get('/users/profile');
+  const userData = userResponse.data.user;
+
+  // Decode JWT to get user info
+  const decoded = jwtDecode(accessToken);
+
+  // Roles and permissions come from the profile, as compact tokens leave
+  // them out and the server resolves them on every request anyway
+  const combinedUser = {
+    id: userData.id,
+    user_id: decoded.user_id,
+    username: userData.username,
+    email: userData.email,
+    roles: userResponse.data.roles,
+    is_admin: userResponse.data.is_admin,
+    permissions: userResponse.data.permissions,
+    created_at: userData.created_at,
+    updated_at: userData.updated_at
+  };
+
+  login(combinedUser);
+  localStorage.setItem('user', JSON.stringify(combinedUser));
+};
+
+// The API redirects to the identity provider and back to /auth/callback
+export const startSingleSignOn = () => {
+  window.location.href = `${api.defaults.baseURL}/auth/oidc/login`;
+};
+
+// Turns an API error into a message, listing every password rule that was broken
+export const describeError = (error) => {
+  const data = error.response?.data;
+  if (data?.violations?.length) {
+    return data.error + ':\n' + data.violations.map((violation) => '- Password ' + violation.message).join('\n');
+  }
+  return data?.error || error.message;
+};
@@ -0,0 +1,69 @@
+// Live task updates from /api/v1/stream. EventSource cannot send an
+// Authorization header, so the SSE response is read through fetch.
+const STREAM_URL = 'http://localhost:8080/api/v1/stream';
+const RECONNECT_DELAY = 3000;
+
+const parseEvent = (block) => {
+  const event = { id: null, type: 'message', data: '' };
+  block.split('\n').forEach((line) => {
+    if (line.startsWith(':')) return;
+    const index = line.indexOf(':');
+    const field = index === -1 ? line : line.slice(0, index);
+    const value = index === -1 ? '' : line.slice(index + 1).replace(/^ /, '');
+    if (field === 'id') event.id = value;
+    if (field === 'event') event.type = value;
+    if (field === 'data') event.data += value;
+  });
+  return event;
+};
+
+export const subscribeToTaskStream = (onEvent) => {
+  const controller = new AbortController();
+  let lastEventId = null;
+  let stopped = false;
+
+  const connect = async () => {
+    const token = localStorage.getItem('access_token');
+    if (!token) return;
+
+    const headers = { Authorization: `Bearer ${token}` };
+    if (lastEventId) headers['Last-Event-ID'] = lastEventId;
+
+    try {
+      const response = await fetch(STREAM_URL, { headers, signal: controller.signal });
+      if (!response.ok) throw new Error(`stream responded with ${response.status}`);
+
+      const reader = response.body.getReader();
+      const decoder = new TextDecoder();
+      let buffer = '';
+
+      for (;;) {
+        const { value, done } = await reader.read();
+        if (done) break;
+
+        buffer += decoder.decode(value, { stream: true });
+        const blocks = buffer.split('\n\n');
+        buffer = blocks.pop();
+
+        blocks.forEach((block) => {
+          const event = parseEvent(block);
+          if (!event.data) return;
+          if (event.id) lastEventId = event.id;
+          onEvent(JSON.parse(event.data));
+        });
+      }
+    } catch (error) {
+      if (stopped) return;
+      console.error('Task stream disconnected', error);
+    }
+
+    if (!stopped) setTimeout(connect, RECONNECT_DELAY);
+  };
+
+  connect();
+
+  return () => {
+    stopped = true;
+    controller.abort();
+  };
+};